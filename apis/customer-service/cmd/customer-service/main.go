@@ -47,24 +47,67 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(*logger, db.DB, os.Args[2:]); err != nil {
+			logger.Error("Error migrating DB", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	cacheClient, err := cache.GetCacheClient(*logger)
 	if err != nil {
 		logger.Error("Error creating cache client", "error", err)
 		return
 	}
 
+	cacheTTL, err := cache.GetCustomerCacheTTL()
+	if err != nil {
+		logger.Error("Error loading cache TTLs", "error", err)
+		return
+	}
+
+	breakerConfig, err := cache.GetBreakerConfig()
+	if err != nil {
+		logger.Error("Error loading cache breaker config", "error", err)
+		return
+	}
+
+	localCacheConfig, err := cache.GetLocalCacheConfig()
+	if err != nil {
+		logger.Error("Error loading local cache config", "error", err)
+		return
+	}
+
+	documentHasher, err := cache.GetDocumentHasher()
+	if err != nil {
+		logger.Error("Error loading cache document secret", "error", err)
+		return
+	}
+
+	cachedReadPaths, err := cache.GetCachedReadPaths()
+	if err != nil {
+		logger.Error("Error loading cached read paths", "error", err)
+		return
+	}
+
 	// Metrics
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(collectors.NewGoCollector())
 	prometheusHandler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg})
 
 	metrics := metrics.NewCustomerMetrics(*logger, reg)
-	customerGtw := database.NewCustomerGateway(*logger, metrics, db.DB)
-	customerCache := cache.NewCustomerCache(*logger, cacheClient)
-	customerSvc := service.NewCustomerService(*logger, customerGtw, customerCache)
+	cacheBreaker := cache.NewBreakerCustomerCache(*logger, cacheClient, cache.NewCustomerCache(*logger, metrics, cacheClient, *cacheTTL, *documentHasher), *breakerConfig)
+	var customerCache cache.CustomerCache = cacheBreaker
+	if localCacheConfig.Size > 0 {
+		customerCache = cache.NewLocalCustomerCache(*logger, metrics, cacheClient, customerCache, *localCacheConfig, *documentHasher)
+	}
+	customerGtw := cache.NewCachedCustomerGateway(*logger, database.NewCustomerGateway(*logger, metrics, db.DB), customerCache, *cachedReadPaths, *documentHasher)
+	customerSvc := service.NewCustomerService(*logger, customerGtw)
 	customerHandler := api.NewCustomerHandler(*logger, metrics, customerSvc)
+	healthHandler := api.NewHealthHandler(*logger, db.DB.PingContext, cacheBreaker.Check)
 
-	r := createRouter(prometheusHandler, customerHandler)
+	r := createRouter(prometheusHandler, healthHandler, customerHandler, os.Getenv("ADMIN_TOKEN"))
 	logger.Debug("Starting customer-service", "port", os.Getenv("APP_PORT"))
 	go http.ListenAndServe(fmt.Sprintf(":%s", os.Getenv("APP_PORT")), r)
 
@@ -75,20 +118,42 @@ func main() {
 	logger.Debug("Stoping customer-service")
 }
 
-func createRouter(prometheusHandler http.Handler, customerHandler api.CustomerHandler) *mux.Router {
+func createRouter(prometheusHandler http.Handler, healthHandler api.HealthHandler, customerHandler api.CustomerHandler, adminToken string) *mux.Router {
 	r := mux.NewRouter()
 
 	r.HandleFunc("/metrics", prometheusHandler.ServeHTTP).Methods("GET")
+	r.HandleFunc("/health", healthHandler.GetHealth).Methods("GET")
 	r.HandleFunc("/v1/customers", customerHandler.GetCustomers).Methods("GET")
+	r.HandleFunc("/v1/customers/search", customerHandler.SearchCustomers).Methods("GET")
+	r.HandleFunc("/v1/customers/export", customerHandler.ExportCustomers).Methods("GET")
+	r.HandleFunc("/v1/customers/birthdays", customerHandler.GetUpcomingBirthdays).Methods("GET")
 	r.HandleFunc("/v1/customers/{id}", customerHandler.GetCustomerByID).Methods("GET")
-	r.HandleFunc("/v2/customers/{id}", customerHandler.V2GetCustomerByID).Methods("GET")
 	r.HandleFunc("/v1/customers/email/{email}", customerHandler.GetCustomerByEmail).Methods("GET")
-	r.HandleFunc("/v2/customers/email/{email}", customerHandler.V2GetCustomerByEmail).Methods("GET")
+	r.HandleFunc("/v1/customers/document/{cpf}", customerHandler.GetCustomerByDocument).Methods("GET")
+	r.HandleFunc("/v1/customers/phone/{phone}", customerHandler.GetCustomerByPhone).Methods("GET")
 	r.HandleFunc("/v1/customers/name/{name}", customerHandler.GetCustomerByName).Methods("GET")
-	r.HandleFunc("/v2/customers/name/{name}", customerHandler.V2GetCustomerByName).Methods("GET")
 	r.HandleFunc("/v1/customers", customerHandler.CreateCustomer).Methods("POST")
+	r.HandleFunc("/v1/customers/import", customerHandler.ImportCustomers).Methods("POST")
 	r.HandleFunc("/v1/customers/{id}", customerHandler.UpdateCustomer).Methods("PUT")
+	r.HandleFunc("/v1/customers/{id}", customerHandler.PatchCustomer).Methods("PATCH")
 	r.HandleFunc("/v1/customers/{id}", customerHandler.DeleteCustomer).Methods("DELETE")
+	r.HandleFunc("/v1/customers/{id}/restore", customerHandler.RestoreCustomer).Methods("POST")
+	r.HandleFunc("/v1/customers/{id}/erase", api.AdminOnly(adminToken, customerHandler.EraseCustomer)).Methods("POST")
+	r.HandleFunc("/v1/customers/{id}/addresses", customerHandler.GetCustomerAddresses).Methods("GET")
+	r.HandleFunc("/v1/customers/{id}/addresses", customerHandler.CreateCustomerAddress).Methods("POST")
+	r.HandleFunc("/v1/customers/{id}/addresses/{addressId}", customerHandler.GetCustomerAddressByID).Methods("GET")
+	r.HandleFunc("/v1/customers/{id}/addresses/{addressId}", customerHandler.UpdateCustomerAddress).Methods("PUT")
+	r.HandleFunc("/v1/customers/{id}/addresses/{addressId}", customerHandler.DeleteCustomerAddress).Methods("DELETE")
+	r.HandleFunc("/v1/customers/{id}/phones", customerHandler.GetCustomerPhones).Methods("GET")
+	r.HandleFunc("/v1/customers/{id}/phones", customerHandler.CreateCustomerPhone).Methods("POST")
+	r.HandleFunc("/v1/customers/{id}/phones/{phoneId}", customerHandler.GetCustomerPhoneByID).Methods("GET")
+	r.HandleFunc("/v1/customers/{id}/phones/{phoneId}", customerHandler.UpdateCustomerPhone).Methods("PUT")
+	r.HandleFunc("/v1/customers/{id}/phones/{phoneId}", customerHandler.DeleteCustomerPhone).Methods("DELETE")
+
+	// Deprecated: caching is transparent now, v2 routes are aliases of v1
+	r.HandleFunc("/v2/customers/{id}", api.Deprecated(customerHandler.GetCustomerByID)).Methods("GET")
+	r.HandleFunc("/v2/customers/email/{email}", api.Deprecated(customerHandler.GetCustomerByEmail)).Methods("GET")
+	r.HandleFunc("/v2/customers/name/{name}", api.Deprecated(customerHandler.GetCustomerByName)).Methods("GET")
 
 	// Swagger
 	r.PathPrefix("/customers/doc/").Handler(httpSwagger.Handler(
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS customers_name_prefix_idx;
+DROP INDEX IF EXISTS customers_surname_prefix_idx;
@@ -0,0 +1,3 @@
+-- the name and surname filters match a case-insensitive prefix, the (name, customer_id) indexes only serve the sort
+CREATE INDEX IF NOT EXISTS customers_name_prefix_idx ON customers USING btree (lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS customers_surname_prefix_idx ON customers USING btree (lower(surname) text_pattern_ops);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS customers_name_id_idx;
+DROP INDEX IF EXISTS customers_surname_id_idx;
+DROP INDEX IF EXISTS customers_created_at_id_idx;
+DROP INDEX IF EXISTS customers_email_domain_idx;
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS customers_name_id_idx ON customers USING btree (name, customer_id);
+CREATE INDEX IF NOT EXISTS customers_surname_id_idx ON customers USING btree (surname, customer_id);
+CREATE INDEX IF NOT EXISTS customers_created_at_id_idx ON customers USING btree (created_at, customer_id);
+CREATE INDEX IF NOT EXISTS customers_email_domain_idx ON customers USING btree (lower(split_part(email, '@', 2)));
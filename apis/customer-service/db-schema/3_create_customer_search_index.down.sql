@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS customers_search_trgm_idx;
+DROP FUNCTION IF EXISTS immutable_unaccent(text);
+
+-- the extensions are left installed, other database objects may depend on them
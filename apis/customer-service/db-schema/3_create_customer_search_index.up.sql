@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent is only STABLE, index expressions need an IMMUTABLE function with a fixed dictionary
+CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text AS $$
+	SELECT public.unaccent('public.unaccent'::regdictionary, $1)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+CREATE INDEX IF NOT EXISTS customers_search_trgm_idx ON customers
+	USING gin (immutable_unaccent(lower(name || ' ' || surname || ' ' || email)) gin_trgm_ops);
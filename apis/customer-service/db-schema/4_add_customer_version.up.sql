@@ -0,0 +1,2 @@
+-- bumped on every write, the API exposes it as the ETag for If-Match / If-None-Match
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
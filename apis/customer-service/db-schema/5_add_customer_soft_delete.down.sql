@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS customer_audit;
+
+-- fails while deleted customers share an email with active ones, they have to be purged first
+DROP INDEX IF EXISTS customers_email_active_idx;
+ALTER TABLE customers ADD CONSTRAINT customers_email_key UNIQUE (email);
+
+ALTER TABLE customers DROP COLUMN IF EXISTS erased_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS deleted_at;
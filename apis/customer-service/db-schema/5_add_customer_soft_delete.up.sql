@@ -0,0 +1,21 @@
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS erased_at timestamp;
+
+-- deleted customers keep their email, only active ones must be unique so it can be reused
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS customers_email_active_idx ON customers USING btree (email) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS customer_audit (
+	audit_id varchar(26) NOT NULL,
+	customer_id varchar(26) NOT NULL,
+	action varchar(20) NOT NULL,
+	actor varchar(100) NOT NULL,
+	reason text,
+	trace_id varchar(100),
+
+	created_at timestamp NOT NULL,
+
+	CONSTRAINT customer_audit_pk PRIMARY KEY (audit_id)
+);
+
+CREATE INDEX IF NOT EXISTS customer_audit_customer_id_idx ON customer_audit USING btree (customer_id, created_at);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS customer_addresses (
+	address_id varchar(26) NOT NULL,
+	customer_id varchar(26) NOT NULL REFERENCES customers (customer_id),
+	type varchar(10) NOT NULL CHECK (type IN ('home', 'work', 'billing')),
+	street varchar(200) NOT NULL,
+	number varchar(10) NOT NULL,
+	complement varchar(100) NOT NULL DEFAULT '',
+	district varchar(100) NOT NULL DEFAULT '',
+	city varchar(100) NOT NULL,
+	state char(2) NOT NULL,
+	cep char(9) NOT NULL,
+	is_default boolean NOT NULL DEFAULT false,
+
+	created_at timestamp NOT NULL,
+	updated_at timestamp,
+
+	CONSTRAINT customer_address_pk PRIMARY KEY (address_id)
+);
+
+CREATE INDEX IF NOT EXISTS customer_addresses_customer_id_idx ON customer_addresses USING btree (customer_id, created_at);
+-- a customer has at most one default address
+CREATE UNIQUE INDEX IF NOT EXISTS customer_addresses_default_idx ON customer_addresses USING btree (customer_id) WHERE is_default;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS customer_phones (
+	phone_id varchar(26) NOT NULL,
+	customer_id varchar(26) NOT NULL REFERENCES customers (customer_id),
+	type varchar(10) NOT NULL CHECK (type IN ('CELULAR', 'FIXO', 'TRABALHO')),
+	number varchar(16) NOT NULL,
+	is_primary boolean NOT NULL DEFAULT false,
+
+	created_at timestamp NOT NULL,
+	updated_at timestamp,
+
+	CONSTRAINT customer_phone_pk PRIMARY KEY (phone_id)
+);
+
+CREATE INDEX IF NOT EXISTS customer_phones_customer_id_idx ON customer_phones USING btree (customer_id, created_at);
+-- a number belongs to a single customer, so customers can be looked up by it
+CREATE UNIQUE INDEX IF NOT EXISTS customer_phones_number_idx ON customer_phones USING btree (number);
+-- a customer has at most one primary phone
+CREATE UNIQUE INDEX IF NOT EXISTS customer_phones_primary_idx ON customer_phones USING btree (customer_id) WHERE is_primary;
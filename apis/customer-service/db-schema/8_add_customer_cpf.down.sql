@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS customers_cpf_active_idx;
+ALTER TABLE customers DROP COLUMN IF EXISTS cpf;
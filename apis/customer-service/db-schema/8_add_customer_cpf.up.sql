@@ -0,0 +1,3 @@
+-- the CPF is optional and stored as its 11 digits, like the email only active customers must be unique
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS cpf char(11);
+CREATE UNIQUE INDEX IF NOT EXISTS customers_cpf_active_idx ON customers USING btree (cpf) WHERE deleted_at IS NULL;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS customers_birthdate_idx;
+
+-- the birthdates that failed the conversion are restored from the report, erased customers get an empty one again
+ALTER TABLE customers ALTER COLUMN birthdate TYPE varchar(10) USING to_char(birthdate, 'YYYY-MM-DD');
+UPDATE customers c SET birthdate = f.birthdate
+	FROM customer_birthdate_conversion_failures f WHERE f.customer_id = c.customer_id AND c.birthdate IS NULL;
+UPDATE customers SET birthdate = '' WHERE birthdate IS NULL;
+ALTER TABLE customers ALTER COLUMN birthdate SET NOT NULL;
+
+DROP TABLE IF EXISTS customer_birthdate_conversion_failures;
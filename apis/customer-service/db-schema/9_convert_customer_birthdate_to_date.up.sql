@@ -0,0 +1,45 @@
+-- birthdates that aren't a valid past YYYY-MM-DD date are kept here for review and converted to NULL
+CREATE TABLE IF NOT EXISTS customer_birthdate_conversion_failures (
+	customer_id varchar(26) NOT NULL,
+	birthdate varchar(10) NOT NULL,
+	reported_at timestamp NOT NULL DEFAULT now(),
+
+	CONSTRAINT customer_birthdate_conversion_failure_pk PRIMARY KEY (customer_id)
+);
+
+CREATE OR REPLACE FUNCTION try_birthdate(value text) RETURNS date AS $$
+BEGIN
+	IF value !~ '^\d{4}-\d{2}-\d{2}$' OR value::date > CURRENT_DATE THEN
+		RETURN NULL;
+	END IF;
+	RETURN value::date;
+EXCEPTION WHEN others THEN
+	-- well formed but not a day of the calendar, like 1990-02-30
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+DO $$
+DECLARE
+	failures bigint;
+BEGIN
+	IF (SELECT data_type FROM information_schema.columns WHERE table_name = 'customers' AND column_name = 'birthdate') = 'date' THEN
+		RETURN;
+	END IF;
+
+	-- erased customers have an empty birthdate on purpose, they aren't failures
+	INSERT INTO customer_birthdate_conversion_failures (customer_id, birthdate)
+	SELECT customer_id, birthdate FROM customers WHERE erased_at IS NULL AND try_birthdate(birthdate) IS NULL
+	ON CONFLICT (customer_id) DO NOTHING;
+	GET DIAGNOSTICS failures = ROW_COUNT;
+
+	ALTER TABLE customers ALTER COLUMN birthdate DROP NOT NULL;
+	ALTER TABLE customers ALTER COLUMN birthdate TYPE date USING try_birthdate(birthdate);
+
+	RAISE NOTICE '% customer birthdates failed the conversion, they are listed in customer_birthdate_conversion_failures', failures;
+END;
+$$;
+
+DROP FUNCTION IF EXISTS try_birthdate(text);
+
+CREATE INDEX IF NOT EXISTS customers_birthdate_idx ON customers USING btree (birthdate);
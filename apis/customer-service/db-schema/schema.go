@@ -0,0 +1,8 @@
+// Package dbschema embeds the versioned migrations applied by `customer-service migrate`.
+// A migration is a pair of N_name.up.sql and N_name.down.sql files, applied in the order of N.
+package dbschema
+
+import "embed"
+
+//go:embed *.sql
+var Migrations embed.FS
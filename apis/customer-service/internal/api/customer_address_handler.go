@@ -0,0 +1,127 @@
+package api
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *customerHandler) GetCustomerAddresses(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET customer addresses request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	addresses, err := h.customerSvc.GetCustomerAddresses(ctx, id)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/{customerId}/addresses", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/{customerId}/addresses", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Addresses of customer: %s", id), now, map[string]interface{}{"addresses": addresses})
+}
+
+func (h *customerHandler) GetCustomerAddressByID(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET customer address by ID request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	addressID := vars["addressId"]
+
+	address, err := h.customerSvc.GetCustomerAddressByID(ctx, id, addressID)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/{customerId}/addresses/{addressId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/{customerId}/addresses/{addressId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Address by ID: %s", addressID), now, map[string]interface{}{"address": address})
+}
+
+func (h *customerHandler) CreateCustomerAddress(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST customer address request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	var address entity.Address
+	err := decodeJSON(r, &address)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/customers/{customerId}/addresses", now)
+		return
+	}
+	address.CustomerID = vars["id"]
+
+	id, err := h.customerSvc.CreateCustomerAddress(ctx, address)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/customers/{customerId}/addresses", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/customers/{customerId}/addresses", "201")
+	h.metrics.IncReqByStatusCode("201")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	h.buildResponse(w, "Customer address created", now, map[string]interface{}{"id": id})
+}
+
+func (h *customerHandler) UpdateCustomerAddress(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("PUT customer address by ID request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	addressID := vars["addressId"]
+	var address entity.Address
+	err := decodeJSON(r, &address)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PUT", "/v1/customers/{customerId}/addresses/{addressId}", now)
+		return
+	}
+	address.ID = &addressID
+	address.CustomerID = vars["id"]
+
+	err = h.customerSvc.UpdateCustomerAddress(ctx, address)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "PUT", "/v1/customers/{customerId}/addresses/{addressId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "PUT", "/v1/customers/{customerId}/addresses/{addressId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Customer address updated", now, map[string]interface{}{"id": addressID})
+}
+
+func (h *customerHandler) DeleteCustomerAddress(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("DELETE customer address by ID request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	addressID := vars["addressId"]
+
+	err := h.customerSvc.DeleteCustomerAddress(ctx, vars["id"], addressID)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "DELETE", "/v1/customers/{customerId}/addresses/{addressId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "DELETE", "/v1/customers/{customerId}/addresses/{addressId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Customer address deleted", now, map[string]interface{}{})
+}
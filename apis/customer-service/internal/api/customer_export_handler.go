@@ -0,0 +1,66 @@
+package api
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var customerExportHeader = []string{"customer_id", "name", "surname", "email", "birthdate", "cpf", "created_at", "updated_at", "version"}
+
+// ExportCustomers streams every customer matching the filters of the list as NDJSON or CSV, in the format
+// of the Accept header. The CPFs are masked like in any other response.
+func (h *customerHandler) ExportCustomers(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET customers export request", "traceID", ctx.Value("traceID"))
+
+	contentType, ok := negotiateExportType(r.Header.Get("Accept"))
+	if !ok {
+		traceID, _ := ctx.Value("traceID").(string)
+		h.writeProblem(w, newProblem(http.StatusNotAcceptable, "Accept must be application/x-ndjson or text/csv", traceID), "GET", "/v1/customers/export", now)
+		return
+	}
+
+	filter, err := parseCustomerFilter(r)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "GET", "/v1/customers/export", now)
+		return
+	}
+
+	stream := newExportStream(w, contentType, "customers", customerExportHeader)
+	err = h.customerSvc.ExportCustomers(ctx, *filter, func(customer *entity.Customer) error {
+		masked := maskCustomer(customer)
+		return stream.write(masked, func() []string { return customerExportRecord(masked) })
+	})
+	if err == nil {
+		err = stream.finish()
+	}
+	if err != nil && !stream.started {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/export", now)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Error on GET customers export", "error", err, "traceID", ctx.Value("traceID"))
+		h.metrics.MeasureDuration(now, "GET", "/v1/customers/export", "500")
+		h.metrics.IncReqByStatusCode("500")
+		// the status was sent already, aborting the response is the only way to tell the client it's truncated
+		panic(http.ErrAbortHandler)
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/export", "200")
+	h.metrics.IncReqByStatusCode("200")
+}
+
+func customerExportRecord(customer *entity.Customer) []string {
+	record := []string{*customer.ID, customer.Name, customer.Surname, customer.Email, customer.Birthdate.String(), "",
+		customer.CreatedAt.Format(time.RFC3339), "", strconv.FormatInt(customer.Version, 10)}
+	if customer.CPF != nil {
+		record[5] = *customer.CPF
+	}
+	if customer.UpdatedAt != nil {
+		record[7] = customer.UpdatedAt.Format(time.RFC3339)
+	}
+	return record
+}
@@ -6,8 +6,11 @@ import (
 	"cmd/customer-service/internal/metrics"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"log/slog"
@@ -19,14 +22,30 @@ import (
 type CustomerHandler interface {
 	GetCustomers(w http.ResponseWriter, r *http.Request)
 	GetCustomerByID(w http.ResponseWriter, r *http.Request)
-	V2GetCustomerByID(w http.ResponseWriter, r *http.Request)
 	GetCustomerByEmail(w http.ResponseWriter, r *http.Request)
-	V2GetCustomerByEmail(w http.ResponseWriter, r *http.Request)
+	GetCustomerByDocument(w http.ResponseWriter, r *http.Request)
+	GetCustomerByPhone(w http.ResponseWriter, r *http.Request)
 	GetCustomerByName(w http.ResponseWriter, r *http.Request)
-	V2GetCustomerByName(w http.ResponseWriter, r *http.Request)
+	SearchCustomers(w http.ResponseWriter, r *http.Request)
+	ExportCustomers(w http.ResponseWriter, r *http.Request)
+	GetUpcomingBirthdays(w http.ResponseWriter, r *http.Request)
 	CreateCustomer(w http.ResponseWriter, r *http.Request)
+	ImportCustomers(w http.ResponseWriter, r *http.Request)
 	UpdateCustomer(w http.ResponseWriter, r *http.Request)
+	PatchCustomer(w http.ResponseWriter, r *http.Request)
 	DeleteCustomer(w http.ResponseWriter, r *http.Request)
+	RestoreCustomer(w http.ResponseWriter, r *http.Request)
+	EraseCustomer(w http.ResponseWriter, r *http.Request)
+	GetCustomerAddresses(w http.ResponseWriter, r *http.Request)
+	GetCustomerAddressByID(w http.ResponseWriter, r *http.Request)
+	CreateCustomerAddress(w http.ResponseWriter, r *http.Request)
+	UpdateCustomerAddress(w http.ResponseWriter, r *http.Request)
+	DeleteCustomerAddress(w http.ResponseWriter, r *http.Request)
+	GetCustomerPhones(w http.ResponseWriter, r *http.Request)
+	GetCustomerPhoneByID(w http.ResponseWriter, r *http.Request)
+	CreateCustomerPhone(w http.ResponseWriter, r *http.Request)
+	UpdateCustomerPhone(w http.ResponseWriter, r *http.Request)
+	DeleteCustomerPhone(w http.ResponseWriter, r *http.Request)
 }
 
 type customerHandler struct {
@@ -53,18 +72,28 @@ func NewCustomerHandler(l slog.Logger, m *metrics.CustomerMetrics, s service.Cus
 func (h *customerHandler) GetCustomers(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	ctx := h.getContext(r)
-	h.logger.Debug("GET all customers request", "traceID", ctx.Value("traceID"))
+	h.logger.Debug("GET customer list request", "traceID", ctx.Value("traceID"))
 
-	customers, err := h.customerSvc.GetCustomerList(ctx)
+	filter, err := parseCustomerFilter(r)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "GET", "/v1/customers", now)
+		h.buildBadRequestResponse(ctx, w, err, "GET", "/v1/customers", now)
+		return
+	}
+
+	customerPage, err := h.customerSvc.GetCustomerList(ctx, *filter)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers", now)
 		return
 	}
 
 	h.metrics.MeasureDuration(now, "GET", "/v1/customers", "200")
 	h.metrics.IncReqByStatusCode("200")
 
-	h.buildResponse(w, "All customers", now, map[string]interface{}{"page_size": len(customers), "page_content": customers})
+	h.buildResponse(w, "Customer list", now, map[string]interface{}{
+		"page_size":    len(customerPage.Customers),
+		"next_cursor":  customerPage.NextCursor,
+		"page_content": maskCustomers(customerPage.Customers),
+	})
 }
 
 func (h *customerHandler) GetCustomerByID(w http.ResponseWriter, r *http.Request) {
@@ -77,74 +106,87 @@ func (h *customerHandler) GetCustomerByID(w http.ResponseWriter, r *http.Request
 
 	customer, err := h.customerSvc.GetCustomerByID(ctx, id)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v1/customers/{customerId}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/{customerId}", now)
+		return
+	}
+	if h.notModified(w, r, customer, "/v1/customers/{customerId}", now) {
 		return
 	}
 
 	h.metrics.MeasureDuration(now, "GET", "/v1/customers/{customerId}", "200")
 	h.metrics.IncReqByStatusCode("200")
 
-	h.buildResponse(w, fmt.Sprintf("Customer by ID: %s", id), now, map[string]interface{}{"customer": customer})
+	h.buildResponse(w, fmt.Sprintf("Customer by ID: %s", id), now, map[string]interface{}{"customer": maskCustomer(customer)})
 }
 
-func (h *customerHandler) V2GetCustomerByID(w http.ResponseWriter, r *http.Request) {
+func (h *customerHandler) GetCustomerByEmail(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	ctx := h.getContext(r)
-	h.logger.Debug("GET customer by ID request", "traceID", ctx.Value("traceID"))
+	h.logger.Debug("GET customer by email request", "traceID", ctx.Value("traceID"))
 
 	vars := mux.Vars(r)
-	id := vars["id"]
+	email := vars["email"]
 
-	customer, err := h.customerSvc.V2GetCustomerByID(ctx, id)
+	customer, err := h.customerSvc.GetCustomerByEmail(ctx, email)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v2/customers/{customerId}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/email/{customerEmail}", now)
+		return
+	}
+	if h.notModified(w, r, customer, "/v1/customers/email/{customerEmail}", now) {
 		return
 	}
 
-	h.metrics.MeasureDuration(now, "GET", "/v2/customers/{customerId}", "200")
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/email/{customerEmail}", "200")
 	h.metrics.IncReqByStatusCode("200")
 
-	h.buildResponse(w, fmt.Sprintf("Customer by ID: %s", id), now, map[string]interface{}{"customer": customer})
+	h.buildResponse(w, fmt.Sprintf("Customer by email: %s", email), now, map[string]interface{}{"customer": maskCustomer(customer)})
 }
 
-func (h *customerHandler) GetCustomerByEmail(w http.ResponseWriter, r *http.Request) {
+// GetCustomerByDocument never echoes the CPF of the path, it's masked in the response
+func (h *customerHandler) GetCustomerByDocument(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	ctx := h.getContext(r)
-	h.logger.Debug("GET customer by email request", "traceID", ctx.Value("traceID"))
+	h.logger.Debug("GET customer by document request", "traceID", ctx.Value("traceID"))
 
 	vars := mux.Vars(r)
-	email := vars["email"]
+	cpf := vars["cpf"]
 
-	customer, err := h.customerSvc.GetCustomerByEmail(ctx, email)
+	customer, err := h.customerSvc.GetCustomerByDocument(ctx, cpf)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v1/customers/email/{customerEmail}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/document/{cpf}", now)
+		return
+	}
+	if h.notModified(w, r, customer, "/v1/customers/document/{cpf}", now) {
 		return
 	}
 
-	h.metrics.MeasureDuration(now, "GET", "/v1/customers/email/{customerEmail}", "200")
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/document/{cpf}", "200")
 	h.metrics.IncReqByStatusCode("200")
 
-	h.buildResponse(w, fmt.Sprintf("Customer by email: %s", email), now, map[string]interface{}{"customer": customer})
+	h.buildResponse(w, "Customer by document", now, map[string]interface{}{"customer": maskCustomer(customer)})
 }
 
-func (h *customerHandler) V2GetCustomerByEmail(w http.ResponseWriter, r *http.Request) {
+func (h *customerHandler) GetCustomerByPhone(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	ctx := h.getContext(r)
-	h.logger.Debug("GET customer by email request", "traceID", ctx.Value("traceID"))
+	h.logger.Debug("GET customer by phone request", "traceID", ctx.Value("traceID"))
 
 	vars := mux.Vars(r)
-	email := vars["email"]
+	phone := vars["phone"]
 
-	customer, err := h.customerSvc.V2GetCustomerByEmail(ctx, email)
+	customer, err := h.customerSvc.GetCustomerByPhone(ctx, phone)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v2/customers/email/{customerEmail}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/phone/{phone}", now)
+		return
+	}
+	if h.notModified(w, r, customer, "/v1/customers/phone/{phone}", now) {
 		return
 	}
 
-	h.metrics.MeasureDuration(now, "GET", "/v2/customers/email/{customerEmail}", "200")
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/phone/{phone}", "200")
 	h.metrics.IncReqByStatusCode("200")
 
-	h.buildResponse(w, fmt.Sprintf("Customer by email: %s", email), now, map[string]interface{}{"customer": customer})
+	h.buildResponse(w, fmt.Sprintf("Customer by phone: %s", phone), now, map[string]interface{}{"customer": maskCustomer(customer)})
 }
 
 func (h *customerHandler) GetCustomerByName(w http.ResponseWriter, r *http.Request) {
@@ -157,34 +199,92 @@ func (h *customerHandler) GetCustomerByName(w http.ResponseWriter, r *http.Reque
 
 	customer, err := h.customerSvc.GetCustomerByName(ctx, name)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v1/customers/name/{customerName}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/name/{customerName}", now)
+		return
+	}
+	if h.notModified(w, r, customer, "/v1/customers/name/{customerName}", now) {
 		return
 	}
 
 	h.metrics.MeasureDuration(now, "GET", "/v1/customers/name/{customerName}", "200")
 	h.metrics.IncReqByStatusCode("200")
 
-	h.buildResponse(w, fmt.Sprintf("Customer by name: %s", name), now, map[string]interface{}{"customer": customer})
+	h.buildResponse(w, fmt.Sprintf("Customer by name: %s", name), now, map[string]interface{}{"customer": maskCustomer(customer)})
 }
 
-func (h *customerHandler) V2GetCustomerByName(w http.ResponseWriter, r *http.Request) {
+func (h *customerHandler) SearchCustomers(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	ctx := h.getContext(r)
-	h.logger.Debug("GET customer by name request", "traceID", ctx.Value("traceID"))
+	h.logger.Debug("GET customer search request", "traceID", ctx.Value("traceID"))
 
-	vars := mux.Vars(r)
-	name := vars["name"]
+	query := r.URL.Query()
+	search := entity.CustomerSearch{
+		Query:  query.Get("q"),
+		Cursor: query.Get("cursor"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			h.buildBadRequestResponse(ctx, w, entity.InvalidField("limit", "must be an integer"), "GET", "/v1/customers/search", now)
+			return
+		}
+		search.Limit = value
+	}
+
+	searchPage, err := h.customerSvc.SearchCustomers(ctx, search)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/search", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/search", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Customer search: %s", search.Query), now, map[string]interface{}{
+		"page_size":    len(searchPage.Customers),
+		"next_cursor":  searchPage.NextCursor,
+		"page_content": maskScoredCustomers(searchPage.Customers),
+	})
+}
+
+func (h *customerHandler) GetUpcomingBirthdays(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET upcoming birthdays request", "traceID", ctx.Value("traceID"))
+
+	query := r.URL.Query()
+	filter := entity.BirthdayFilter{Cursor: query.Get("cursor")}
+	if days := query.Get("days"); days != "" {
+		value, err := strconv.Atoi(days)
+		if err != nil {
+			h.buildBadRequestResponse(ctx, w, entity.InvalidField("days", "must be an integer"), "GET", "/v1/customers/birthdays", now)
+			return
+		}
+		filter.Days = value
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			h.buildBadRequestResponse(ctx, w, entity.InvalidField("limit", "must be an integer"), "GET", "/v1/customers/birthdays", now)
+			return
+		}
+		filter.Limit = value
+	}
 
-	customer, err := h.customerSvc.V2GetCustomerByName(ctx, name)
+	birthdayPage, err := h.customerSvc.GetUpcomingBirthdays(ctx, filter)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v2/customers/name/{customerName}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/birthdays", now)
 		return
 	}
 
-	h.metrics.MeasureDuration(now, "GET", "/v2/customers/name/{customerName}", "200")
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/birthdays", "200")
 	h.metrics.IncReqByStatusCode("200")
 
-	h.buildResponse(w, fmt.Sprintf("Customer by name: %s", name), now, map[string]interface{}{"customer": customer})
+	h.buildResponse(w, "Upcoming birthdays", now, map[string]interface{}{
+		"page_size":    len(birthdayPage.Customers),
+		"next_cursor":  birthdayPage.NextCursor,
+		"page_content": maskUpcomingBirthdays(birthdayPage.Customers),
+	})
 }
 
 func (h *customerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
@@ -193,15 +293,15 @@ func (h *customerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request)
 	h.logger.Debug("POST customer request", "traceID", ctx.Value("traceID"))
 
 	var customer entity.Customer
-	err := json.NewDecoder(r.Body).Decode(&customer)
+	err := decodeJSON(r, &customer)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "POST", "/v1/customers", now)
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/customers", now)
 		return
 	}
 
 	id, err := h.customerSvc.CreateCustomer(ctx, customer)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "POST", "/v1/customers", now)
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/customers", now)
 		return
 	}
 
@@ -222,25 +322,72 @@ func (h *customerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request)
 	id := vars["id"]
 	var customer entity.Customer
 
-	err := json.NewDecoder(r.Body).Decode(&customer)
+	err := decodeJSON(r, &customer)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "PUT", "/v1/customers", now)
+		h.buildBadRequestResponse(ctx, w, err, "PUT", "/v1/customers", now)
 		return
 	}
 	customer.ID = &id
 
-	err = h.customerSvc.UpdateCustomer(ctx, customer)
+	ifMatch, err := parseIfMatch(r, id)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "PUT", "/v1/customers", now)
+		h.buildBadRequestResponse(ctx, w, err, "PUT", "/v1/customers", now)
+		return
+	}
+
+	updated, err := h.customerSvc.UpdateCustomer(ctx, customer, ifMatch)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "PUT", "/v1/customers", now)
 		return
 	}
 
 	h.metrics.MeasureDuration(now, "PUT", "/v1/customers", "200")
 	h.metrics.IncReqByStatusCode("200")
 
+	w.Header().Set("ETag", formatETag(*updated.ID, updated.Version))
 	h.buildResponse(w, "Customer updated", now, map[string]interface{}{"id": id})
 }
 
+func (h *customerHandler) PatchCustomer(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("PATCH customer by ID request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	fields, err := decodeMergePatch(r)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PATCH", "/v1/customers/{customerId}", now)
+		return
+	}
+
+	patch, err := parseCustomerPatch(id, fields)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PATCH", "/v1/customers/{customerId}", now)
+		return
+	}
+
+	ifMatch, err := parseIfMatch(r, id)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PATCH", "/v1/customers/{customerId}", now)
+		return
+	}
+
+	customer, err := h.customerSvc.PatchCustomer(ctx, *patch, ifMatch)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "PATCH", "/v1/customers/{customerId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "PATCH", "/v1/customers/{customerId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	w.Header().Set("ETag", formatETag(*customer.ID, customer.Version))
+
+	h.buildResponse(w, "Customer patched", now, map[string]interface{}{"customer": maskCustomer(customer)})
+}
+
 func (h *customerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	ctx := h.getContext(r)
@@ -249,9 +396,15 @@ func (h *customerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request)
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.customerSvc.DeleteCustomerByID(ctx, id)
+	ifMatch, err := parseIfMatch(r, id)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "DELETE", "/v1/customers/{customerId}", now)
+		return
+	}
+
+	err = h.customerSvc.DeleteCustomerByID(ctx, id, ifMatch)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "DELETE", "/v1/customers/{customerId}", now)
+		h.buildErrorResponse(ctx, w, err, "DELETE", "/v1/customers/{customerId}", now)
 		return
 	}
 
@@ -261,6 +414,76 @@ func (h *customerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request)
 	h.buildResponse(w, "Customer deleted", now, map[string]interface{}{})
 }
 
+// notModified sets the customer ETag and answers 304 when the client already has that version
+func (h *customerHandler) notModified(w http.ResponseWriter, r *http.Request, customer *entity.Customer, uri string, start time.Time) bool {
+	etag := formatETag(*customer.ID, customer.Version)
+	w.Header().Set("ETag", etag)
+	if !matchesIfNoneMatch(r, etag) {
+		return false
+	}
+
+	h.metrics.MeasureDuration(start, "GET", uri, "304")
+	h.metrics.IncReqByStatusCode("304")
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+func (h *customerHandler) RestoreCustomer(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST customer restore request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	customer, err := h.customerSvc.RestoreCustomerByID(ctx, id)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/customers/{customerId}/restore", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/customers/{customerId}/restore", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	w.Header().Set("ETag", formatETag(*customer.ID, customer.Version))
+	h.buildResponse(w, "Customer restored", now, map[string]interface{}{"customer": maskCustomer(customer)})
+}
+
+// EraseCustomer must be routed behind AdminOnly, the erase can't be undone
+func (h *customerHandler) EraseCustomer(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST customer erase request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	erasure := entity.CustomerErasure{
+		CustomerID: vars["id"],
+		Actor:      r.Header.Get("X-Actor"),
+	}
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil && err != io.EOF {
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/customers/{customerId}/erase", now)
+		return
+	}
+	erasure.Reason = body.Reason
+
+	err = h.customerSvc.EraseCustomerByID(ctx, erasure)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/customers/{customerId}/erase", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/customers/{customerId}/erase", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Customer erased", now, map[string]interface{}{"id": erasure.CustomerID})
+}
+
 func (h *customerHandler) getContext(r *http.Request) context.Context {
 	traceID := r.Header.Get("X-Trace-ID")
 	if traceID == "" {
@@ -271,6 +494,109 @@ func (h *customerHandler) getContext(r *http.Request) context.Context {
 	return ctx
 }
 
+func parseCustomerFilter(r *http.Request) (*entity.CustomerFilter, error) {
+	query := r.URL.Query()
+	filter := &entity.CustomerFilter{
+		Cursor:      query.Get("cursor"),
+		Name:        query.Get("name"),
+		Surname:     query.Get("surname"),
+		EmailDomain: query.Get("email_domain"),
+		SortBy:      query.Get("sort_by"),
+		SortOrder:   query.Get("sort_order"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, entity.InvalidField("limit", "must be an integer")
+		}
+		filter.Limit = value
+	}
+
+	if createdFrom := query.Get("created_from"); createdFrom != "" {
+		value, _, err := parseQueryTime(createdFrom)
+		if err != nil {
+			return nil, entity.InvalidField("created_from", "must be a RFC3339 timestamp or a date")
+		}
+		filter.CreatedFrom = &value
+	}
+
+	if createdTo := query.Get("created_to"); createdTo != "" {
+		value, date, err := parseQueryTime(createdTo)
+		if err != nil {
+			return nil, entity.InvalidField("created_to", "must be a RFC3339 timestamp or a date")
+		}
+		if date {
+			before := value.AddDate(0, 0, 1)
+			filter.CreatedBefore = &before
+		} else {
+			filter.CreatedTo = &value
+		}
+	}
+
+	if minAge := query.Get("min_age"); minAge != "" {
+		value, err := strconv.Atoi(minAge)
+		if err != nil {
+			return nil, entity.InvalidField("min_age", "must be an integer")
+		}
+		filter.MinAge = &value
+	}
+
+	if maxAge := query.Get("max_age"); maxAge != "" {
+		value, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return nil, entity.InvalidField("max_age", "must be an integer")
+		}
+		filter.MaxAge = &value
+	}
+
+	return filter, nil
+}
+
+func parseCustomerPatch(id string, fields map[string]json.RawMessage) (*entity.CustomerPatch, error) {
+	patch := &entity.CustomerPatch{ID: id}
+	invalidFields := make([]entity.FieldError, 0)
+	for _, field := range sortedKeys(fields) {
+		value := fields[field]
+		var err error
+		switch field {
+		case "name":
+			patch.Name, err = patchString(value)
+		case "surname":
+			patch.Surname, err = patchString(value)
+		case "email":
+			patch.Email, err = patchString(value)
+		case "birthdate":
+			patch.Birthdate, err = patchDate(value)
+		case "cpf":
+			patch.CPF, err = patchOptionalString(value)
+		case "customer_id", "created_at", "updated_at", "version":
+			err = fmt.Errorf("is read-only")
+		default:
+			err = fmt.Errorf("is unknown")
+		}
+		if err != nil {
+			invalidFields = append(invalidFields, entity.FieldError{Field: field, Message: err.Error()})
+		}
+	}
+
+	if len(invalidFields) > 0 {
+		return nil, &entity.ValidationError{Fields: invalidFields}
+	}
+	return patch, nil
+}
+
+// parseQueryTime accepts both RFC3339 timestamps and plain dates (YYYY-MM-DD), a date is the
+// start of its day and tells the caller so the bounds can include the whole day
+func parseQueryTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), false, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	return t, true, err
+}
+
 func (h *customerHandler) buildResponse(w http.ResponseWriter, message string, start time.Time, data map[string]interface{}) {
 	res := &response{
 		Message:     message,
@@ -283,11 +609,32 @@ func (h *customerHandler) buildResponse(w http.ResponseWriter, message string, s
 	json.NewEncoder(w).Encode(res)
 }
 
-func (h *customerHandler) buildErrorResponse(w http.ResponseWriter, error string, statusCode int, method string, uri string, start time.Time) {
-	h.metrics.MeasureDuration(start, method, uri, fmt.Sprint(statusCode))
-	h.metrics.IncReqByStatusCode(fmt.Sprint(statusCode))
+// buildBadRequestResponse answers a request that couldn't be parsed, a parsed one with invalid
+// fields gets the same 422 the validation of the service answers
+func (h *customerHandler) buildBadRequestResponse(ctx context.Context, w http.ResponseWriter, err error, method string, uri string, start time.Time) {
+	var validationErr *entity.ValidationError
+	if errors.As(err, &validationErr) || errors.Is(err, entity.ErrCustomerVersionMismatch) {
+		h.buildErrorResponse(ctx, w, err, method, uri, start)
+		return
+	}
+
+	traceID, _ := ctx.Value("traceID").(string)
+	h.writeProblem(w, newProblem(http.StatusBadRequest, err.Error(), traceID), method, uri, start)
+}
+
+func (h *customerHandler) buildErrorResponse(ctx context.Context, w http.ResponseWriter, err error, method string, uri string, start time.Time) {
+	traceID, _ := ctx.Value("traceID").(string)
+	p := errorProblem(err, traceID)
+	if p.Status >= http.StatusInternalServerError {
+		h.logger.Error(fmt.Sprintf("Error on %s customer", method), "uri", uri, "error", err, "traceID", ctx.Value("traceID"))
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	h.buildResponse(w, fmt.Sprintf("Error on %s customer: %s", method, error), start, map[string]interface{}{})
+	h.writeProblem(w, p, method, uri, start)
+}
+
+func (h *customerHandler) writeProblem(w http.ResponseWriter, p *problem, method string, uri string, start time.Time) {
+	h.metrics.MeasureDuration(start, method, uri, fmt.Sprint(p.Status))
+	h.metrics.IncReqByStatusCode(fmt.Sprint(p.Status))
+
+	writeProblem(w, p)
 }
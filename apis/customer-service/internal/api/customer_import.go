@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"cmd/customer-service/internal/domain/entity"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxImportLineSize bounds the memory taken by a single NDJSON row
+const maxImportLineSize = 64 << 10
+
+var errUnsupportedImportType = errors.New("Content-Type must be text/csv or application/x-ndjson")
+
+// csvImportColumns are the columns an import CSV may have, all but cpf are required
+var csvImportColumns = map[string]bool{
+	"name":      true,
+	"surname":   true,
+	"email":     true,
+	"birthdate": true,
+	"cpf":       false,
+}
+
+// newImportReader picks the reader of the Content-Type, a CSV header is read and checked right away
+// so a wrong file is answered with a 400 before any row is imported
+func newImportReader(r *http.Request) (entity.ImportReader, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, errUnsupportedImportType
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return newCSVImportReader(r.Body)
+	case "application/x-ndjson", "application/ndjson":
+		return newNDJSONImportReader(r.Body), nil
+	}
+	return nil, errUnsupportedImportType
+}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVImportReader(body io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(body)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV must have a header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CSV header is invalid: %s", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := csvImportColumns[name]; !ok {
+			return nil, fmt.Errorf("CSV column %q is unknown", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("CSV column %q is repeated", name)
+		}
+		columns[name] = i
+	}
+	for name, required := range csvImportColumns {
+		if _, ok := columns[name]; required && !ok {
+			return nil, fmt.Errorf("CSV column %q is missing", name)
+		}
+	}
+	reader.FieldsPerRecord = len(header)
+
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvImportReader) Read() (*entity.ImportRow, error) {
+	record, err := c.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		// the reader resumes on the next line, only this row is lost
+		return &entity.ImportRow{Line: parseErr.StartLine, Err: entity.InvalidField("row", fmt.Sprintf("is not valid CSV: %s", parseErr.Err))}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	row := &entity.ImportRow{Line: line}
+	value := func(name string) string {
+		i, ok := c.columns[name]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row.Customer = entity.Customer{
+		Name:    value("name"),
+		Surname: value("surname"),
+		Email:   value("email"),
+	}
+	if cpf := value("cpf"); cpf != "" {
+		row.Customer.CPF = &cpf
+	}
+	if birthdate := value("birthdate"); birthdate != "" {
+		row.Customer.Birthdate, err = entity.ParseDate(birthdate)
+		if err != nil {
+			row.Err = entity.InvalidField("birthdate", "must be a date formatted as YYYY-MM-DD")
+		}
+	}
+
+	return row, nil
+}
+
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONImportReader(body io.Reader) *ndjsonImportReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), maxImportLineSize)
+
+	return &ndjsonImportReader{scanner: scanner}
+}
+
+func (n *ndjsonImportReader) Read() (*entity.ImportRow, error) {
+	for n.scanner.Scan() {
+		n.line++
+		data := bytes.TrimSpace(n.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		row := &entity.ImportRow{Line: n.line}
+		err := decodeJSONFrom(bytes.NewReader(data), &row.Customer)
+		var validationErr *entity.ValidationError
+		if err != nil && !errors.As(err, &validationErr) {
+			err = entity.InvalidField("row", "must be a JSON object")
+		}
+		row.Err = err
+		return row, nil
+	}
+
+	err := n.scanner.Err()
+	if errors.Is(err, bufio.ErrTooLong) {
+		return nil, entity.InvalidField("body", fmt.Sprintf("line %d is longer than %d bytes", n.line+1, maxImportLineSize))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
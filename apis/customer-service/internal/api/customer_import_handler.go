@@ -0,0 +1,103 @@
+package api
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// importResultLine is written for every row, status is created, or valid on dry runs, or failed
+type importResultLine struct {
+	Line       int                 `json:"line"`
+	Status     string              `json:"status"`
+	CustomerID *string             `json:"customer_id,omitempty"`
+	Detail     string              `json:"detail,omitempty"`
+	Errors     []entity.FieldError `json:"errors,omitempty"`
+}
+
+// importSummaryLine is the last line of an import, error is set when it stopped before the end of the file
+type importSummaryLine struct {
+	Summary *entity.ImportSummary `json:"summary"`
+	Error   *problem              `json:"error,omitempty"`
+}
+
+// ImportCustomers streams the result of each row as NDJSON while the body is still being read, so neither
+// the file nor the results are held in memory. Rows imported before a failure stay imported.
+func (h *customerHandler) ImportCustomers(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST customers import request", "traceID", ctx.Value("traceID"))
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			h.buildBadRequestResponse(ctx, w, entity.InvalidField("dry_run", "must be a boolean"), "POST", "/v1/customers/import", now)
+			return
+		}
+		dryRun = parsed
+	}
+
+	rows, err := newImportReader(r)
+	if errors.Is(err, errUnsupportedImportType) {
+		traceID, _ := ctx.Value("traceID").(string)
+		h.writeProblem(w, newProblem(http.StatusUnsupportedMediaType, err.Error(), traceID), "POST", "/v1/customers/import", now)
+		return
+	}
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/customers/import", now)
+		return
+	}
+
+	// by default the server drains the body before the response starts, the rows are still needed then
+	controller := http.NewResponseController(w)
+	if err := controller.EnableFullDuplex(); err != nil {
+		h.logger.Debug("Full duplex isn't supported", "error", err, "traceID", ctx.Value("traceID"))
+	}
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+
+	traceID, _ := ctx.Value("traceID").(string)
+	encoder := json.NewEncoder(w)
+	summary, err := h.customerSvc.ImportCustomers(ctx, rows, dryRun, func(results []entity.ImportResult) error {
+		for _, result := range results {
+			if err := encoder.Encode(newImportResultLine(result, dryRun, traceID)); err != nil {
+				return err
+			}
+		}
+		return controller.Flush()
+	})
+
+	last := importSummaryLine{Summary: summary}
+	if err != nil {
+		last.Error = errorProblem(err, traceID)
+		if last.Error.Status >= http.StatusInternalServerError {
+			h.logger.Error("Error on POST customers import", "error", err, "traceID", ctx.Value("traceID"))
+		}
+	}
+	encoder.Encode(last)
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/customers/import", "200")
+	h.metrics.IncReqByStatusCode("200")
+}
+
+// newImportResultLine shows the errors of a row like the problem of a single create would
+func newImportResultLine(result entity.ImportResult, dryRun bool, traceID string) importResultLine {
+	line := importResultLine{Line: result.Line, Status: "created", CustomerID: result.CustomerID}
+	if dryRun {
+		line.Status = "valid"
+	}
+	if result.Err != nil {
+		p := errorProblem(result.Err, traceID)
+		line.Status = "failed"
+		line.Detail = p.Detail
+		line.Errors = p.Errors
+	}
+
+	return line
+}
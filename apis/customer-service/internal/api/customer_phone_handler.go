@@ -0,0 +1,127 @@
+package api
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *customerHandler) GetCustomerPhones(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET customer phones request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	phones, err := h.customerSvc.GetCustomerPhones(ctx, id)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/{customerId}/phones", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/{customerId}/phones", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Phones of customer: %s", id), now, map[string]interface{}{"phones": phones})
+}
+
+func (h *customerHandler) GetCustomerPhoneByID(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET customer phone by ID request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+	phoneID := vars["phoneId"]
+
+	phone, err := h.customerSvc.GetCustomerPhoneByID(ctx, id, phoneID)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/customers/{customerId}/phones/{phoneId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/customers/{customerId}/phones/{phoneId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Phone by ID: %s", phoneID), now, map[string]interface{}{"phone": phone})
+}
+
+func (h *customerHandler) CreateCustomerPhone(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST customer phone request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	var phone entity.Phone
+	err := decodeJSON(r, &phone)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/customers/{customerId}/phones", now)
+		return
+	}
+	phone.CustomerID = vars["id"]
+
+	id, err := h.customerSvc.CreateCustomerPhone(ctx, phone)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/customers/{customerId}/phones", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/customers/{customerId}/phones", "201")
+	h.metrics.IncReqByStatusCode("201")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	h.buildResponse(w, "Customer phone created", now, map[string]interface{}{"id": id})
+}
+
+func (h *customerHandler) UpdateCustomerPhone(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("PUT customer phone by ID request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	phoneID := vars["phoneId"]
+	var phone entity.Phone
+	err := decodeJSON(r, &phone)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PUT", "/v1/customers/{customerId}/phones/{phoneId}", now)
+		return
+	}
+	phone.ID = &phoneID
+	phone.CustomerID = vars["id"]
+
+	err = h.customerSvc.UpdateCustomerPhone(ctx, phone)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "PUT", "/v1/customers/{customerId}/phones/{phoneId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "PUT", "/v1/customers/{customerId}/phones/{phoneId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Customer phone updated", now, map[string]interface{}{"id": phoneID})
+}
+
+func (h *customerHandler) DeleteCustomerPhone(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("DELETE customer phone by ID request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	phoneID := vars["phoneId"]
+
+	err := h.customerSvc.DeleteCustomerPhone(ctx, vars["id"], phoneID)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "DELETE", "/v1/customers/{customerId}/phones/{phoneId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "DELETE", "/v1/customers/{customerId}/phones/{phoneId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Customer phone deleted", now, map[string]interface{}{})
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// HealthCheck returns an error when the dependency it checks can't be used
+type HealthCheck func(ctx context.Context) error
+
+type HealthHandler interface {
+	GetHealth(w http.ResponseWriter, r *http.Request)
+}
+
+type healthHandler struct {
+	logger   slog.Logger
+	database HealthCheck
+	cache    HealthCheck
+}
+
+func NewHealthHandler(l slog.Logger, database HealthCheck, cache HealthCheck) HealthHandler {
+	return &healthHandler{
+		logger:   *l.With("layer", "health-handler"),
+		database: database,
+		cache:    cache,
+	}
+}
+
+// GetHealth reports the status of every dependency. The service is only unhealthy
+// without the DB, without the cache it's degraded but still serves every request.
+func (h *healthHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	databaseStatus := h.check(ctx, "database", h.database)
+	cacheStatus := h.check(ctx, "cache", h.cache)
+
+	message, statusCode := "Healthy", http.StatusOK
+	if cacheStatus != "up" {
+		message = "Degraded"
+	}
+	if databaseStatus != "up" {
+		message, statusCode = "Unhealthy", http.StatusServiceUnavailable
+	}
+
+	res := &response{
+		Message:     message,
+		Timestamp:   time.Now(),
+		ElapsedTime: fmt.Sprintf("%dms", time.Since(now).Milliseconds()),
+		Data: map[string]interface{}{
+			"database": databaseStatus,
+			"cache":    cacheStatus,
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *healthHandler) check(ctx context.Context, name string, check HealthCheck) string {
+	err := check(ctx)
+	if err != nil {
+		h.logger.Error("Health check failed", "dependency", name, "error", err)
+		return "down"
+	}
+	return "up"
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+)
+
+// maskCustomer copies a customer with its CPF masked. The customer may be shared by the
+// local cache, so it's never changed in place.
+func maskCustomer(customer *entity.Customer) *entity.Customer {
+	if customer == nil || customer.CPF == nil {
+		return customer
+	}
+	masked := *customer
+	cpf := entity.MaskCPF(*customer.CPF)
+	masked.CPF = &cpf
+	return &masked
+}
+
+func maskCustomers(customers []*entity.Customer) []*entity.Customer {
+	masked := make([]*entity.Customer, len(customers))
+	for i, customer := range customers {
+		masked[i] = maskCustomer(customer)
+	}
+	return masked
+}
+
+func maskScoredCustomers(customers []*entity.ScoredCustomer) []*entity.ScoredCustomer {
+	masked := make([]*entity.ScoredCustomer, len(customers))
+	for i, customer := range customers {
+		masked[i] = &entity.ScoredCustomer{Customer: *maskCustomer(&customer.Customer), Score: customer.Score}
+	}
+	return masked
+}
+
+func maskUpcomingBirthdays(customers []*entity.UpcomingBirthday) []*entity.UpcomingBirthday {
+	masked := make([]*entity.UpcomingBirthday, len(customers))
+	for i, customer := range customers {
+		masked[i] = &entity.UpcomingBirthday{Customer: *maskCustomer(&customer.Customer), NextBirthday: customer.NextBirthday, Turning: customer.Turning}
+	}
+	return masked
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// decodeMergePatch reads a JSON Merge Patch (RFC 7396) body, keeping which members were sent
+// so absent fields can be told apart from the ones set to null
+func decodeMergePatch(r *http.Request) (map[string]json.RawMessage, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+		return nil, fmt.Errorf("Content-Type must be %s", mergePatchContentType)
+	}
+
+	var patch map[string]json.RawMessage
+	err = json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil || patch == nil {
+		return nil, fmt.Errorf("Merge patch must be a JSON object")
+	}
+
+	return patch, nil
+}
+
+// patchString decodes a patched string member of a required column, removing it with null
+// isn't allowed
+func patchString(value json.RawMessage) (*string, error) {
+	if string(value) == "null" {
+		return nil, fmt.Errorf("can't be removed")
+	}
+
+	var s string
+	err := json.Unmarshal(value, &s)
+	if err != nil {
+		return nil, fmt.Errorf("must be a string")
+	}
+
+	return &s, nil
+}
+
+// patchDate decodes a patched date member of a required column
+func patchDate(value json.RawMessage) (*entity.Date, error) {
+	if string(value) == "null" {
+		return nil, fmt.Errorf("can't be removed")
+	}
+
+	var date entity.Date
+	err := json.Unmarshal(value, &date)
+	if err != nil {
+		return nil, fmt.Errorf("must be a date formatted as YYYY-MM-DD")
+	}
+
+	return &date, nil
+}
+
+// patchOptionalString decodes a patched string member of a nullable column, null removes it
+// and is returned as an empty string
+func patchOptionalString(value json.RawMessage) (*string, error) {
+	if string(value) == "null" {
+		empty := ""
+		return &empty, nil
+	}
+
+	var s string
+	err := json.Unmarshal(value, &s)
+	if err != nil {
+		return nil, fmt.Errorf("must be a string or null")
+	}
+
+	return &s, nil
+}
+
+// sortedKeys keeps the field errors of a patch in a stable order
+func sortedKeys(fields map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
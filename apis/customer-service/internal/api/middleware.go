@@ -0,0 +1,31 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Deprecated serves an aliased route and points clients to the v1 route that replaces it
+func Deprecated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		successor := strings.Replace(r.URL.Path, "/v2/", "/v1/", 1)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		next(w, r)
+	}
+}
+
+// AdminOnly lets the request through only with the admin token as a bearer token.
+// Privileged routes are disabled altogether when no token is configured.
+func AdminOnly(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			writeProblem(w, newProblem(http.StatusForbidden, "Admin token is missing or invalid", r.Header.Get("X-Trace-ID")))
+			return
+		}
+		next(w, r)
+	}
+}
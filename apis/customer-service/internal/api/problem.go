@@ -0,0 +1,75 @@
+package api
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const problemContentType = "application/problem+json"
+
+// problem is the RFC 7807 body of every error response. The trace ID lets support find
+// the logs of a failed request, they hold the detail hidden from clients.
+type problem struct {
+	Type    string              `json:"type"`
+	Title   string              `json:"title"`
+	Status  int                 `json:"status"`
+	Detail  string              `json:"detail,omitempty"`
+	TraceID string              `json:"trace_id,omitempty"`
+	Errors  []entity.FieldError `json:"errors,omitempty"`
+}
+
+func newProblem(statusCode int, detail string, traceID string) *problem {
+	return &problem{
+		Type:    "about:blank",
+		Title:   http.StatusText(statusCode),
+		Status:  statusCode,
+		Detail:  detail,
+		TraceID: traceID,
+	}
+}
+
+func writeProblem(w http.ResponseWriter, p *problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// errorStatusCode maps the typed domain errors to a status code, any other error is unexpected
+func errorStatusCode(err error) int {
+	var validationErr *entity.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, entity.ErrCustomerNotFound), errors.Is(err, entity.ErrAddressNotFound),
+		errors.Is(err, entity.ErrPhoneNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, entity.ErrCustomerConflict):
+		return http.StatusConflict
+	case errors.Is(err, entity.ErrCustomerVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, entity.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// errorProblem builds the problem of a domain error. Only the messages of typed errors are
+// shown, the others may carry driver text.
+func errorProblem(err error, traceID string) *problem {
+	statusCode := errorStatusCode(err)
+	switch statusCode {
+	case http.StatusServiceUnavailable:
+		return newProblem(statusCode, "A dependency is unavailable, try again later", traceID)
+	case http.StatusInternalServerError:
+		return newProblem(statusCode, "Unexpected error, report the trace ID to support", traceID)
+	}
+
+	p := newProblem(statusCode, err.Error(), traceID)
+	var validationErr *entity.ValidationError
+	if errors.As(err, &validationErr) {
+		p.Errors = validationErr.Fields
+	}
+	return p
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// decodeJSON decodes a request body into v, rejecting the fields v doesn't have. Unknown
+// and mistyped fields are reported as field errors, like the ones of the validation.
+func decodeJSON(r *http.Request, v interface{}) error {
+	return decodeJSONFrom(r.Body, v)
+}
+
+func decodeJSONFrom(body io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &entity.ValidationError{Fields: []entity.FieldError{{Field: strings.Trim(field, "\""), Message: "is unknown"}}}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		message := fmt.Sprintf("must be a %s", typeErr.Type)
+		if typeErr.Type == reflect.TypeOf(entity.Date{}) {
+			message = "must be a date formatted as YYYY-MM-DD"
+		}
+		return &entity.ValidationError{Fields: []entity.FieldError{{Field: typeErr.Field, Message: message}}}
+	}
+
+	return fmt.Errorf("Body must be a JSON object: %s", err)
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	AddressTypeHome    = "home"
+	AddressTypeWork    = "work"
+	AddressTypeBilling = "billing"
+)
+
+// Address is where a customer can be reached or billed. A customer with addresses always has
+// exactly one default, the one orders ship to unless told otherwise.
+type Address struct {
+	ID         *string `json:"address_id" db:"address_id"`
+	CustomerID string  `json:"customer_id" db:"customer_id"`
+	Type       string  `json:"type" db:"type"`
+	Street     string  `json:"street" db:"street"`
+	Number     string  `json:"number" db:"number"`
+	Complement string  `json:"complement" db:"complement"`
+	District   string  `json:"district" db:"district"`
+	City       string  `json:"city" db:"city"`
+	// State is the two letter code of a Brazilian state, like SP
+	State string `json:"state" db:"state"`
+	// CEP is the Brazilian postal code, formatted as 01310-100
+	CEP       string `json:"cep" db:"cep"`
+	IsDefault bool   `json:"is_default" db:"is_default"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
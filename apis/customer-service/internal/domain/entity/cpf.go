@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"log/slog"
+)
+
+// MaskCPF hides the first three digits and the check digits of a CPF stored as its 11 digits,
+// like ***.456.789-**, so it can be shown in responses and logs
+func MaskCPF(cpf string) string {
+	if cpf == "" {
+		return ""
+	}
+	if len(cpf) != 11 {
+		return "***.***.***-**"
+	}
+	return "***." + cpf[3:6] + "." + cpf[6:9] + "-**"
+}
+
+// customerLog and customerPatchLog have the fields of the logged types without their LogValue method
+type (
+	customerLog      Customer
+	customerPatchLog CustomerPatch
+)
+
+// LogValue masks the CPF of logged customers
+func (c Customer) LogValue() slog.Value {
+	if c.CPF != nil {
+		masked := MaskCPF(*c.CPF)
+		c.CPF = &masked
+	}
+	return slog.AnyValue(customerLog(c))
+}
+
+// LogValue masks the CPF of logged patches
+func (p CustomerPatch) LogValue() slog.Value {
+	if p.CPF != nil {
+		masked := MaskCPF(*p.CPF)
+		p.CPF = &masked
+	}
+	return slog.AnyValue(customerPatchLog(p))
+}
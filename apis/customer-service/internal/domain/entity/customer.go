@@ -5,12 +5,102 @@ import (
 )
 
 type Customer struct {
-	ID        *string `json:"customer_id" db:"customer_id"`
-	Name      string  `json:"name" db:"name"`
-	Surname   string  `json:"surname" db:"surname"`
-	Email     string  `json:"email" db:"email"`
-	Birthdate string  `json:"birthdate" db:"birthdate"`
+	ID      *string `json:"customer_id" db:"customer_id"`
+	Name    string  `json:"name" db:"name"`
+	Surname string  `json:"surname" db:"surname"`
+	Email   string  `json:"email" db:"email"`
+	// Birthdate is null when the stored value couldn't be converted to a date
+	Birthdate Date `json:"birthdate" db:"birthdate"`
+	// CPF is the optional Brazilian tax ID, stored as its 11 digits and masked in responses and logs
+	CPF *string `json:"cpf,omitempty" db:"cpf"`
+	// Version is increased by every mutation, it's exposed as the ETag of the customer
+	Version int64 `json:"version" db:"version"`
+	// Addresses are only loaded when a single customer is read, the default one comes first
+	Addresses []*Address `json:"addresses,omitempty" db:"-"`
+	// Phones are loaded along with the addresses, the primary one comes first
+	Phones []*Phone `json:"phones,omitempty" db:"-"`
 
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// CustomerPatch holds the fields sent in a merge patch, nil fields are left untouched
+type CustomerPatch struct {
+	ID        string
+	Name      *string
+	Surname   *string
+	Email     *string
+	Birthdate *Date
+	// CPF set to an empty string removes it
+	CPF *string
+}
+
+func (p CustomerPatch) IsEmpty() bool {
+	return p.Name == nil && p.Surname == nil && p.Email == nil && p.Birthdate == nil && p.CPF == nil
+}
+
+// CustomerErasure is a request to irreversibly anonymize a customer, Actor and Reason go to the audit log
+type CustomerErasure struct {
+	CustomerID string
+	Actor      string
+	Reason     string
+}
+
+type CustomerFilter struct {
+	Limit       int
+	Cursor      string
+	Name        string
+	Surname     string
+	EmailDomain string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// CreatedBefore is the exclusive bound of a created_to date, the whole day is included
+	CreatedBefore *time.Time
+	// MinAge and MaxAge bound the age in full years, customers without birthdate are left out
+	MinAge    *int
+	MaxAge    *int
+	SortBy    string
+	SortOrder string
+}
+
+type CustomerPage struct {
+	Customers  []*Customer
+	NextCursor *string
+}
+
+type CustomerSearch struct {
+	Query  string
+	Limit  int
+	Cursor string
+}
+
+// ScoredCustomer is a search result, Score is the relevance of the match from 0 to 1
+type ScoredCustomer struct {
+	Customer
+	Score float64 `json:"score"`
+}
+
+type CustomerSearchPage struct {
+	Customers  []*ScoredCustomer
+	NextCursor *string
+}
+
+// BirthdayFilter lists the customers whose birthday is in the next Days days, today included
+type BirthdayFilter struct {
+	Days   int
+	Limit  int
+	Cursor string
+}
+
+// UpcomingBirthday is a customer with its next birthday and the age it turns on that day.
+// Customers born on February 29 have their birthday on February 28 in common years.
+type UpcomingBirthday struct {
+	Customer
+	NextBirthday Date `json:"next_birthday"`
+	Turning      int  `json:"turning"`
+}
+
+type BirthdayPage struct {
+	Customers  []*UpcomingBirthday
+	NextCursor *string
+}
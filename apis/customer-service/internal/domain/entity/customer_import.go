@@ -0,0 +1,29 @@
+package entity
+
+// ImportRow is a row of an import file and the line it starts at, Err is set when the row couldn't be
+// decoded into a customer
+type ImportRow struct {
+	Line     int
+	Customer Customer
+	Err      error
+}
+
+// ImportReader yields the rows of an import file one at a time and io.EOF after the last one,
+// so files of any size are imported without being held in memory
+type ImportReader interface {
+	Read() (*ImportRow, error)
+}
+
+// ImportResult is the outcome of a single row, CustomerID is only set when the customer was created
+type ImportResult struct {
+	Line       int
+	CustomerID *string
+	Err        error
+}
+
+type ImportSummary struct {
+	Total     int  `json:"total"`
+	Succeeded int  `json:"succeeded"`
+	Failed    int  `json:"failed"`
+	DryRun    bool `json:"dry_run"`
+}
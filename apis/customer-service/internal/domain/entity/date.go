@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Date is a calendar day without time or zone, like a birthdate. It's formatted as
+// YYYY-MM-DD in JSON and stored in date columns, the zero Date is null in both.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{t}, nil
+}
+
+// Today is the current day in UTC
+func Today() Date {
+	now := time.Now().UTC()
+	return NewDate(now.Year(), now.Month(), now.Day())
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(time.DateOnly)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON fails like a mistyped field, so the decoder reports which field has the bad date
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err == nil {
+		*d, err = ParseDate(value)
+	}
+	if err != nil {
+		return &json.UnmarshalTypeError{Value: string(data), Type: reflect.TypeOf(Date{})}
+	}
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+func (d *Date) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = NewDate(value.Year(), value.Month(), value.Day())
+	case string:
+		return d.scanString(value)
+	case []byte:
+		return d.scanString(string(value))
+	default:
+		return fmt.Errorf("Can't scan %T into a Date", src)
+	}
+	return nil
+}
+
+func (d *Date) scanString(value string) error {
+	date, err := ParseDate(value)
+	if err != nil {
+		return fmt.Errorf("Can't scan %q into a Date: %w", value, err)
+	}
+	*d = date
+	return nil
+}
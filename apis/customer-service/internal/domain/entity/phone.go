@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+)
+
+// The phone types of the workshop telefone table
+const (
+	PhoneTypeMobile   = "CELULAR"
+	PhoneTypeLandline = "FIXO"
+	PhoneTypeWork     = "TRABALHO"
+)
+
+// Phone is a number a customer can be reached at. A customer has at most one primary phone,
+// the one support calls first.
+type Phone struct {
+	ID         *string `json:"phone_id" db:"phone_id"`
+	CustomerID string  `json:"customer_id" db:"customer_id"`
+	Type       string  `json:"type" db:"type"`
+	// Number is a Brazilian number in E.164, like +5511987654321
+	Number    string `json:"number" db:"number"`
+	IsPrimary bool   `json:"is_primary" db:"is_primary"`
+
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -6,11 +6,26 @@ import (
 )
 
 type CustomerGateway interface {
-	GetCustomerList(ctx context.Context) ([]*entity.Customer, error)
+	GetCustomerList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error)
 	GetCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error)
 	GetCustomerByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error)
+	GetCustomerByDocument(ctx context.Context, cpf string) (*entity.Customer, error)
+	GetCustomerByPhone(ctx context.Context, number string) (*entity.Customer, error)
 	GetCustomerByName(ctx context.Context, customerName string) (*entity.Customer, error)
+	SearchCustomers(ctx context.Context, search entity.CustomerSearch) (*entity.CustomerSearchPage, error)
+	ExportCustomers(ctx context.Context, filter entity.CustomerFilter, write func(customer *entity.Customer) error) error
+	GetUpcomingBirthdays(ctx context.Context, filter entity.BirthdayFilter) (*entity.BirthdayPage, error)
 	CreateCustomer(ctx context.Context, customer entity.Customer) (*string, error)
-	UpdateCustomer(ctx context.Context, customer entity.Customer) error
-	DeleteCustomerByID(ctx context.Context, customerID string) error
+	ImportCustomers(ctx context.Context, customers []*entity.Customer, dryRun bool) ([]error, error)
+	UpdateCustomer(ctx context.Context, customer entity.Customer, ifMatch *int64) error
+	PatchCustomer(ctx context.Context, patch entity.CustomerPatch, ifMatch *int64) error
+	DeleteCustomerByID(ctx context.Context, customerID string, ifMatch *int64) error
+	RestoreCustomerByID(ctx context.Context, customerID string) error
+	EraseCustomerByID(ctx context.Context, erasure entity.CustomerErasure) error
+	CreateCustomerAddress(ctx context.Context, address entity.Address) (*string, error)
+	UpdateCustomerAddress(ctx context.Context, address entity.Address) error
+	DeleteCustomerAddress(ctx context.Context, customerID string, addressID string) error
+	CreateCustomerPhone(ctx context.Context, phone entity.Phone) (*string, error)
+	UpdateCustomerPhone(ctx context.Context, phone entity.Phone) error
+	DeleteCustomerPhone(ctx context.Context, customerID string, phoneID string) error
 }
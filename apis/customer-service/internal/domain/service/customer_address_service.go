@@ -0,0 +1,102 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"fmt"
+)
+
+// GetCustomerAddresses reads the addresses from the customer, so they are served from its cache
+func (s *customerService) GetCustomerAddresses(ctx context.Context, customerID string) ([]*entity.Address, error) {
+	s.logger.Info("Getting customer addresses", "customerID", customerID, "traceID", ctx.Value("traceID"))
+	customer, err := s.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		s.logger.Error("Failed to get customer of the addresses", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	if customer.Addresses == nil {
+		return []*entity.Address{}, nil
+	}
+	return customer.Addresses, nil
+}
+
+func (s *customerService) GetCustomerAddressByID(ctx context.Context, customerID string, addressID string) (*entity.Address, error) {
+	s.logger.Info("Getting customer address by ID", "customerID", customerID, "ID", addressID, "traceID", ctx.Value("traceID"))
+	customer, err := s.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		s.logger.Error("Failed to get customer of the address", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return findAddress(customer, addressID)
+}
+
+func (s *customerService) CreateCustomerAddress(ctx context.Context, address entity.Address) (*string, error) {
+	s.logger.Info("Creating customer address", "customerID", address.CustomerID, "traceID", ctx.Value("traceID"))
+	err := normalizeAddress(&address)
+	if err != nil {
+		s.logger.Error("Invalid customer address", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	id, err := s.customerGtw.CreateCustomerAddress(ctx, address)
+	if err != nil {
+		s.logger.Error("Failed to create customer address", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// UpdateCustomerAddress replaces an address. The default flag can't be taken away from the
+// default address, it moves when another address is made the default.
+func (s *customerService) UpdateCustomerAddress(ctx context.Context, address entity.Address) error {
+	s.logger.Info("Updating customer address", "customerID", address.CustomerID, "ID", address.ID, "traceID", ctx.Value("traceID"))
+	err := normalizeAddress(&address)
+	if err != nil {
+		s.logger.Error("Invalid customer address", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	customer, err := s.customerGtw.GetCustomerByID(ctx, address.CustomerID)
+	if err != nil {
+		s.logger.Error("Failed to get customer of the address", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+	current, err := findAddress(customer, *address.ID)
+	if err != nil {
+		return err
+	}
+	if current.IsDefault && !address.IsDefault {
+		return entity.InvalidField("is_default", "can't be unset, make another address the default instead")
+	}
+
+	err = s.customerGtw.UpdateCustomerAddress(ctx, address)
+	if err != nil {
+		s.logger.Error("Failed to update customer address", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
+
+func (s *customerService) DeleteCustomerAddress(ctx context.Context, customerID string, addressID string) error {
+	s.logger.Info("Deleting customer address", "customerID", customerID, "ID", addressID, "traceID", ctx.Value("traceID"))
+	err := s.customerGtw.DeleteCustomerAddress(ctx, customerID, addressID)
+	if err != nil {
+		s.logger.Error("Failed to delete customer address", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
+
+func findAddress(customer *entity.Customer, addressID string) (*entity.Address, error) {
+	for _, address := range customer.Addresses {
+		if *address.ID == addressID {
+			return address, nil
+		}
+	}
+	return nil, fmt.Errorf("%w with ID=%s", entity.ErrAddressNotFound, addressID)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Limits of the varchar columns of the customer_addresses table
+const (
+	maxStreetLength     = 200
+	maxNumberLength     = 10
+	maxComplementLength = 100
+	maxDistrictLength   = 100
+	maxCityLength       = 100
+)
+
+var cepPattern = regexp.MustCompile(`^(\d{5})-?(\d{3})$`)
+
+var addressTypes = map[string]bool{
+	entity.AddressTypeHome:    true,
+	entity.AddressTypeWork:    true,
+	entity.AddressTypeBilling: true,
+}
+
+// brazilianStates maps the codes and the unaccented lowercase names of the states to their code
+var brazilianStates = map[string]string{
+	"ac": "AC", "acre": "AC",
+	"al": "AL", "alagoas": "AL",
+	"ap": "AP", "amapa": "AP",
+	"am": "AM", "amazonas": "AM",
+	"ba": "BA", "bahia": "BA",
+	"ce": "CE", "ceara": "CE",
+	"df": "DF", "distrito federal": "DF",
+	"es": "ES", "espirito santo": "ES",
+	"go": "GO", "goias": "GO",
+	"ma": "MA", "maranhao": "MA",
+	"mt": "MT", "mato grosso": "MT",
+	"ms": "MS", "mato grosso do sul": "MS",
+	"mg": "MG", "minas gerais": "MG",
+	"pa": "PA", "para": "PA",
+	"pb": "PB", "paraiba": "PB",
+	"pr": "PR", "parana": "PR",
+	"pe": "PE", "pernambuco": "PE",
+	"pi": "PI", "piaui": "PI",
+	"rj": "RJ", "rio de janeiro": "RJ",
+	"rn": "RN", "rio grande do norte": "RN",
+	"rs": "RS", "rio grande do sul": "RS",
+	"ro": "RO", "rondonia": "RO",
+	"rr": "RR", "roraima": "RR",
+	"sc": "SC", "santa catarina": "SC",
+	"sp": "SP", "sao paulo": "SP",
+	"se": "SE", "sergipe": "SE",
+	"to": "TO", "tocantins": "TO",
+}
+
+// unaccent only covers the accents used in the names of the states
+var unaccent = strings.NewReplacer("á", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i", "ó", "o", "ô", "o", "õ", "o", "ú", "u", "ç", "c")
+
+// normalizeAddress checks an address, as sent on create and update, and rewrites its state
+// as a code and its CEP with the dash, the way they are stored
+func normalizeAddress(address *entity.Address) error {
+	errs := fieldErrors{}
+	if !addressTypes[address.Type] {
+		errs.add("type", "must be home, work or billing")
+	}
+	checkAddressText(&errs, "street", address.Street, maxStreetLength, true)
+	checkAddressText(&errs, "number", address.Number, maxNumberLength, true)
+	checkAddressText(&errs, "complement", address.Complement, maxComplementLength, false)
+	checkAddressText(&errs, "district", address.District, maxDistrictLength, false)
+	checkAddressText(&errs, "city", address.City, maxCityLength, true)
+
+	state, ok := normalizeState(address.State)
+	if !ok {
+		errs.add("state", "must be the code or the name of a Brazilian state")
+	}
+	cep, ok := normalizeCEP(address.CEP)
+	if !ok {
+		errs.add("cep", "must have 8 digits, formatted as 01310-100 or 01310100")
+	}
+
+	if err := errs.err(); err != nil {
+		return err
+	}
+	address.State = state
+	address.CEP = cep
+	return nil
+}
+
+func checkAddressText(errs *fieldErrors, field string, value string, max int, required bool) {
+	if !required && value == "" {
+		return
+	}
+	if !validLength(value, max) {
+		errs.add(field, fmt.Sprintf("must have between 1 and %d characters", max))
+	}
+}
+
+func normalizeState(value string) (string, bool) {
+	name := strings.Join(strings.Fields(unaccent.Replace(strings.ToLower(value))), " ")
+	state, ok := brazilianStates[name]
+	return state, ok
+}
+
+func normalizeCEP(value string) (string, bool) {
+	match := cepPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return "", false
+	}
+	return match[1] + "-" + match[2], true
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"cmd/customer-service/test"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NormalizeAddress(t *testing.T) {
+	address := func(change func(a *entity.Address)) entity.Address {
+		a := *test.AnAddress
+		change(&a)
+		return a
+	}
+
+	scenarios := []struct {
+		name          string
+		address       entity.Address
+		wantState     string
+		wantCEP       string
+		invalidFields []string
+	}{
+		{"valid address", *test.AnAddress, "SP", "01310-200", nil},
+		{"state name and CEP without dash", address(func(a *entity.Address) { a.State, a.CEP = " são  paulo", "01310200" }), "SP", "01310-200", nil},
+		{"lowercase state code", address(func(a *entity.Address) { a.State = "rj" }), "RJ", "01310-200", nil},
+		{"empty address", entity.Address{}, "", "", []string{"type", "street", "number", "city", "state", "cep"}},
+		{"unknown type and state", address(func(a *entity.Address) { a.Type, a.State = "office", "XX" }), "", "", []string{"type", "state"}},
+		{"malformed CEP", address(func(a *entity.Address) { a.CEP = "1310-200" }), "", "", []string{"cep"}},
+		{"complement too long", address(func(a *entity.Address) { a.Complement = strings.Repeat("a", maxComplementLength+1) }), "", "", []string{"complement"}},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := normalizeAddress(&tt.address)
+			if tt.invalidFields == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantState, tt.address.State)
+				assert.Equal(t, tt.wantCEP, tt.address.CEP)
+				return
+			}
+
+			var validationErr *entity.ValidationError
+			assert.True(t, errors.As(err, &validationErr))
+			fields := make([]string, 0)
+			for _, field := range validationErr.Fields {
+				fields = append(fields, field.Field)
+			}
+			assert.Equal(t, tt.invalidFields, fields)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"strings"
+)
+
+var cpfSeparators = strings.NewReplacer(".", "", "-", "", " ", "")
+
+// normalizeCPF strips the punctuation of a CPF, like 123.456.789-09, and checks its two check digits
+func normalizeCPF(value string) (string, bool) {
+	digits := cpfSeparators.Replace(strings.TrimSpace(value))
+	if len(digits) != 11 {
+		return "", false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	// a single repeated digit passes the check digits but is never issued
+	if strings.Count(digits, digits[:1]) == len(digits) {
+		return "", false
+	}
+
+	// the first check digit weighs the 9 base digits from 10 down to 2, the second one
+	// weighs them and the first check digit from 11 down to 2
+	for n := 9; n <= 10; n++ {
+		sum := 0
+		for i := 0; i < n; i++ {
+			sum += int(digits[i]-'0') * (n + 1 - i)
+		}
+		if sum*10%11%10 != int(digits[n]-'0') {
+			return "", false
+		}
+	}
+
+	return digits, true
+}
+
+// cpfDigits returns the stored form of a validated CPF, an empty one is no CPF
+func cpfDigits(cpf *string) *string {
+	if cpf == nil || *cpf == "" {
+		return nil
+	}
+	digits, _ := normalizeCPF(*cpf)
+	return &digits
+}
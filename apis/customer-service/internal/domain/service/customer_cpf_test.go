@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NormalizeCPF(t *testing.T) {
+	scenarios := []struct {
+		name   string
+		cpf    string
+		want   string
+		wantOk bool
+	}{
+		{"formatted", "529.982.247-25", "52998224725", true},
+		{"digits only", "52998224725", "52998224725", true},
+		{"first check digit of zero", "111.444.777-35", "11144477735", true},
+		{"wrong first check digit", "529.982.247-35", "", false},
+		{"wrong second check digit", "529.982.247-24", "", false},
+		{"repeated digit", "111.111.111-11", "", false},
+		{"too short", "529.982.247-2", "", false},
+		{"letters", "529.982.24A-25", "", false},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := normalizeCPF(tt.cpf)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
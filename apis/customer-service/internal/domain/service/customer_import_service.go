@@ -0,0 +1,84 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"errors"
+	"io"
+)
+
+// ImportCustomers validates the rows like CreateCustomer and inserts the valid ones in batches of
+// importBatchSize, each batch in its own transaction. The results of every batch are reported
+// before the next one is read, in the order of the rows. A dry run goes through the same inserts,
+// so duplicates are found as well, but rolls them back. Each batch is rolled back before the next one
+// is read, so a dry run only finds the duplicates of existing customers and of rows of the same batch:
+// a row repeating one of an earlier batch is reported valid, and fails the real import.
+func (s *customerService) ImportCustomers(ctx context.Context, rows entity.ImportReader, dryRun bool, report func(results []entity.ImportResult) error) (*entity.ImportSummary, error) {
+	s.logger.Info("Importing customers", "dryRun", dryRun, "traceID", ctx.Value("traceID"))
+	summary := &entity.ImportSummary{DryRun: dryRun}
+
+	for done := false; !done; {
+		results := make([]entity.ImportResult, 0, importBatchSize)
+		customers := make([]*entity.Customer, 0, importBatchSize)
+		// pending has the index in results of each customer sent to the gateway
+		pending := make([]int, 0, importBatchSize)
+
+		for len(results) < importBatchSize {
+			row, err := rows.Read()
+			if errors.Is(err, io.EOF) {
+				done = true
+				break
+			}
+			if err != nil {
+				s.logger.Error("Failed to read import row", "error", err, "traceID", ctx.Value("traceID"))
+				return summary, err
+			}
+
+			result := entity.ImportResult{Line: row.Line, Err: row.Err}
+			row.Customer = trimCustomer(row.Customer)
+			if result.Err == nil {
+				result.Err = validateCustomer(row.Customer)
+			}
+			if result.Err == nil {
+				customer := row.Customer
+				customer.CPF = cpfDigits(customer.CPF)
+				customers = append(customers, &customer)
+				pending = append(pending, len(results))
+			}
+			results = append(results, result)
+		}
+
+		if len(customers) > 0 {
+			errs, err := s.customerGtw.ImportCustomers(ctx, customers, dryRun)
+			if err != nil {
+				s.logger.Error("Failed to import customers", "error", err, "traceID", ctx.Value("traceID"))
+				return summary, err
+			}
+			for i, customer := range customers {
+				result := &results[pending[i]]
+				result.Err = errs[i]
+				if result.Err == nil && !dryRun {
+					result.CustomerID = customer.ID
+				}
+			}
+		}
+
+		for _, result := range results {
+			summary.Total++
+			if result.Err != nil {
+				summary.Failed++
+			} else {
+				summary.Succeeded++
+			}
+		}
+		if len(results) > 0 {
+			if err := report(results); err != nil {
+				s.logger.Error("Failed to report import results", "error", err, "traceID", ctx.Value("traceID"))
+				return summary, err
+			}
+		}
+	}
+
+	s.logger.Info("Imported customers", "summary", summary, "traceID", ctx.Value("traceID"))
+	return summary, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"cmd/customer-service/mocks"
+	"cmd/customer-service/test"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// sliceImportReader yields rows from memory, like the readers of the API do from the body
+type sliceImportReader struct {
+	rows []entity.ImportRow
+}
+
+func (s *sliceImportReader) Read() (*entity.ImportRow, error) {
+	if len(s.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+	return &row, nil
+}
+
+func Test_CustomerSvc_ImportCustomers(t *testing.T) {
+	ctx := context.Background()
+	duplicate := *test.ACustomer
+	duplicate.Email = "taken@example.com"
+	invalid := *test.ACustomer
+	invalid.Email = "not-an-email"
+
+	gtw := new(mocks.CustomerGateway)
+	svc := NewCustomerService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw)
+	conflict := errors.New("email is already in use")
+	gtw.On("ImportCustomers", ctx, mock.MatchedBy(func(customers []*entity.Customer) bool { return len(customers) == 2 }), false).
+		Run(func(args mock.Arguments) {
+			customers := args.Get(1).([]*entity.Customer)
+			customers[0].ID = &test.CustomerID
+		}).
+		Return([]error{nil, conflict}, nil)
+
+	rows := &sliceImportReader{rows: []entity.ImportRow{
+		{Line: 2, Customer: *test.ACustomer},
+		{Line: 3, Customer: invalid},
+		{Line: 4, Err: entity.InvalidField("birthdate", "must be a date formatted as YYYY-MM-DD")},
+		{Line: 5, Customer: duplicate},
+	}}
+	var reported []entity.ImportResult
+	summary, err := svc.ImportCustomers(ctx, rows, false, func(results []entity.ImportResult) error {
+		reported = append(reported, results...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.ImportSummary{Total: 4, Succeeded: 1, Failed: 3}, summary)
+	// results keep the order of the rows, whatever failed them
+	assert.Equal(t, []int{2, 3, 4, 5}, []int{reported[0].Line, reported[1].Line, reported[2].Line, reported[3].Line})
+	assert.Equal(t, &test.CustomerID, reported[0].CustomerID)
+	assert.NoError(t, reported[0].Err)
+	assert.Error(t, reported[1].Err)
+	assert.Error(t, reported[2].Err)
+	assert.ErrorIs(t, reported[3].Err, conflict)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CustomerSvc_ImportCustomers_DryRun(t *testing.T) {
+	ctx := context.Background()
+	gtw := new(mocks.CustomerGateway)
+	svc := NewCustomerService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw)
+	gtw.On("ImportCustomers", ctx, mock.Anything, true).
+		Run(func(args mock.Arguments) {
+			args.Get(1).([]*entity.Customer)[0].ID = &test.CustomerID
+		}).
+		Return([]error{nil}, nil)
+
+	var reported []entity.ImportResult
+	summary, err := svc.ImportCustomers(ctx, &sliceImportReader{rows: []entity.ImportRow{{Line: 1, Customer: *test.ACustomer}}}, true, func(results []entity.ImportResult) error {
+		reported = append(reported, results...)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.ImportSummary{Total: 1, Succeeded: 1, DryRun: true}, summary)
+	// nothing was created, so there is no ID to show
+	assert.Nil(t, reported[0].CustomerID)
+}
+
+func Test_CustomerSvc_ImportCustomers_GatewayError(t *testing.T) {
+	ctx := context.Background()
+	gtw := new(mocks.CustomerGateway)
+	svc := NewCustomerService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw)
+	gtw.On("ImportCustomers", ctx, mock.Anything, false).Return(nil, entity.ErrUnavailable)
+
+	reports := 0
+	summary, err := svc.ImportCustomers(ctx, &sliceImportReader{rows: []entity.ImportRow{{Line: 1, Customer: *test.ACustomer}}}, false, func(results []entity.ImportResult) error {
+		reports++
+		return nil
+	})
+
+	assert.ErrorIs(t, err, entity.ErrUnavailable)
+	assert.Equal(t, 0, summary.Total)
+	assert.Equal(t, 0, reports)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"fmt"
+)
+
+// GetCustomerByPhone accepts the number in any format normalizePhoneNumber understands
+func (s *customerService) GetCustomerByPhone(ctx context.Context, number string) (*entity.Customer, error) {
+	s.logger.Info("Getting customer by phone", "number", number, "traceID", ctx.Value("traceID"))
+	normalized, ok := normalizePhoneNumber(number)
+	if !ok {
+		return nil, entity.InvalidField("phone", "must be a Brazilian number with area code, like +55 11 98765-4321")
+	}
+
+	customer, err := s.customerGtw.GetCustomerByPhone(ctx, normalized)
+	if err != nil {
+		s.logger.Error("Failed to get customer by phone", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// GetCustomerPhones reads the phones from the customer, so they are served from its cache
+func (s *customerService) GetCustomerPhones(ctx context.Context, customerID string) ([]*entity.Phone, error) {
+	s.logger.Info("Getting customer phones", "customerID", customerID, "traceID", ctx.Value("traceID"))
+	customer, err := s.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		s.logger.Error("Failed to get customer of the phones", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	if customer.Phones == nil {
+		return []*entity.Phone{}, nil
+	}
+	return customer.Phones, nil
+}
+
+func (s *customerService) GetCustomerPhoneByID(ctx context.Context, customerID string, phoneID string) (*entity.Phone, error) {
+	s.logger.Info("Getting customer phone by ID", "customerID", customerID, "ID", phoneID, "traceID", ctx.Value("traceID"))
+	customer, err := s.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		s.logger.Error("Failed to get customer of the phone", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	for _, phone := range customer.Phones {
+		if *phone.ID == phoneID {
+			return phone, nil
+		}
+	}
+	return nil, fmt.Errorf("%w with ID=%s", entity.ErrPhoneNotFound, phoneID)
+}
+
+func (s *customerService) CreateCustomerPhone(ctx context.Context, phone entity.Phone) (*string, error) {
+	s.logger.Info("Creating customer phone", "customerID", phone.CustomerID, "traceID", ctx.Value("traceID"))
+	err := normalizePhone(&phone)
+	if err != nil {
+		s.logger.Error("Invalid customer phone", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	id, err := s.customerGtw.CreateCustomerPhone(ctx, phone)
+	if err != nil {
+		s.logger.Error("Failed to create customer phone", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return id, nil
+}
+
+func (s *customerService) UpdateCustomerPhone(ctx context.Context, phone entity.Phone) error {
+	s.logger.Info("Updating customer phone", "customerID", phone.CustomerID, "ID", phone.ID, "traceID", ctx.Value("traceID"))
+	err := normalizePhone(&phone)
+	if err != nil {
+		s.logger.Error("Invalid customer phone", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	err = s.customerGtw.UpdateCustomerPhone(ctx, phone)
+	if err != nil {
+		s.logger.Error("Failed to update customer phone", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
+
+func (s *customerService) DeleteCustomerPhone(ctx context.Context, customerID string, phoneID string) error {
+	s.logger.Info("Deleting customer phone", "customerID", customerID, "ID", phoneID, "traceID", ctx.Value("traceID"))
+	err := s.customerGtw.DeleteCustomerPhone(ctx, customerID, phoneID)
+	if err != nil {
+		s.logger.Error("Failed to delete customer phone", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
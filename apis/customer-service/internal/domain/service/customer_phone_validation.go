@@ -0,0 +1,62 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"regexp"
+	"strings"
+)
+
+// nationalPhonePattern matches a two digit area code followed by a 9 digit mobile number,
+// which starts with 9, or by an 8 digit landline number
+var nationalPhonePattern = regexp.MustCompile(`^[1-9]{2}(9\d{8}|[2-9]\d{7})$`)
+
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+var phoneTypes = map[string]bool{
+	entity.PhoneTypeMobile:   true,
+	entity.PhoneTypeLandline: true,
+	entity.PhoneTypeWork:     true,
+}
+
+// normalizePhone checks a phone, as sent on create and update, and rewrites its type in
+// uppercase and its number in E.164, the way they are stored
+func normalizePhone(phone *entity.Phone) error {
+	errs := fieldErrors{}
+	phoneType := strings.ToUpper(strings.TrimSpace(phone.Type))
+	if !phoneTypes[phoneType] {
+		errs.add("type", "must be CELULAR, FIXO or TRABALHO")
+	}
+	number, ok := normalizePhoneNumber(phone.Number)
+	if !ok {
+		errs.add("number", "must be a Brazilian number with area code, like +55 11 98765-4321")
+	}
+
+	if err := errs.err(); err != nil {
+		return err
+	}
+	phone.Type = phoneType
+	phone.Number = number
+	return nil
+}
+
+// normalizePhoneNumber turns a Brazilian number, written with or without the country code,
+// the trunk prefix and separators, into E.164 like +5511987654321
+func normalizePhoneNumber(value string) (string, bool) {
+	digits := phoneSeparators.Replace(strings.TrimSpace(value))
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		if !strings.HasPrefix(digits, "+55") {
+			return "", false
+		}
+		digits = digits[3:]
+	case strings.HasPrefix(digits, "0"):
+		digits = digits[1:]
+	case len(digits) > 11 && strings.HasPrefix(digits, "55"):
+		digits = digits[2:]
+	}
+
+	if !nationalPhonePattern.MatchString(digits) {
+		return "", false
+	}
+	return "+55" + digits, true
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"cmd/customer-service/test"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NormalizePhoneNumber(t *testing.T) {
+	scenarios := []struct {
+		name   string
+		number string
+		want   string
+		wantOk bool
+	}{
+		{"E.164 mobile", "+5511987654321", "+5511987654321", true},
+		{"formatted mobile", "+55 (11) 98765-4321", "+5511987654321", true},
+		{"country code without plus", "5511987654321", "+5511987654321", true},
+		{"trunk prefix", "011 98765-4321", "+5511987654321", true},
+		{"national landline", "8123991924", "+558123991924", true},
+		{"foreign country code", "+14155552671", "", false},
+		{"area code with zero", "1087654321", "", false},
+		{"mobile without leading 9", "11887654321", "", false},
+		{"too short", "87654321", "", false},
+		{"letters", "11 9876-ABCD", "", false},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := normalizePhoneNumber(tt.number)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_NormalizePhone(t *testing.T) {
+	phone := *test.APhone
+	phone.Type = "celular"
+	phone.Number = "(11) 98765-4321"
+	assert.NoError(t, normalizePhone(&phone))
+	assert.Equal(t, *test.APhone, phone)
+
+	var validationErr *entity.ValidationError
+	err := normalizePhone(&entity.Phone{Type: "PAGER", Number: "123"})
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Fields, 2)
+}
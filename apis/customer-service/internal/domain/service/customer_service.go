@@ -3,71 +3,98 @@ package service
 import (
 	"cmd/customer-service/internal/domain/entity"
 	"cmd/customer-service/internal/domain/gateway"
-	"cmd/customer-service/internal/resources/cache"
 	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"log/slog"
 )
 
+const (
+	defaultPageLimit   = 50
+	maxPageLimit       = 500
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	minSearchQuery     = 2
+	maxAge             = 150
+	defaultBirthdays   = 7
+	maxBirthdays       = 90
+	// importBatchSize rows are inserted per transaction, it bounds the memory an import holds
+	importBatchSize = 500
+)
+
+var customerSortFields = map[string]bool{
+	"id":         true,
+	"name":       true,
+	"surname":    true,
+	"email":      true,
+	"created_at": true,
+}
+
 type CustomerService interface {
-	GetCustomerList(ctx context.Context) ([]*entity.Customer, error)
+	GetCustomerList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error)
 	GetCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error)
-	V2GetCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error)
 	GetCustomerByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error)
-	V2GetCustomerByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error)
+	GetCustomerByDocument(ctx context.Context, cpf string) (*entity.Customer, error)
+	GetCustomerByPhone(ctx context.Context, number string) (*entity.Customer, error)
 	GetCustomerByName(ctx context.Context, customerName string) (*entity.Customer, error)
-	V2GetCustomerByName(ctx context.Context, customerName string) (*entity.Customer, error)
+	SearchCustomers(ctx context.Context, search entity.CustomerSearch) (*entity.CustomerSearchPage, error)
+	ExportCustomers(ctx context.Context, filter entity.CustomerFilter, write func(customer *entity.Customer) error) error
+	GetUpcomingBirthdays(ctx context.Context, filter entity.BirthdayFilter) (*entity.BirthdayPage, error)
 	CreateCustomer(ctx context.Context, customer entity.Customer) (*string, error)
-	UpdateCustomer(ctx context.Context, customer entity.Customer) error
-	DeleteCustomerByID(ctx context.Context, customerID string) error
+	ImportCustomers(ctx context.Context, rows entity.ImportReader, dryRun bool, report func(results []entity.ImportResult) error) (*entity.ImportSummary, error)
+	UpdateCustomer(ctx context.Context, customer entity.Customer, ifMatch *int64) (*entity.Customer, error)
+	PatchCustomer(ctx context.Context, patch entity.CustomerPatch, ifMatch *int64) (*entity.Customer, error)
+	DeleteCustomerByID(ctx context.Context, customerID string, ifMatch *int64) error
+	RestoreCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error)
+	EraseCustomerByID(ctx context.Context, erasure entity.CustomerErasure) error
+	GetCustomerAddresses(ctx context.Context, customerID string) ([]*entity.Address, error)
+	GetCustomerAddressByID(ctx context.Context, customerID string, addressID string) (*entity.Address, error)
+	CreateCustomerAddress(ctx context.Context, address entity.Address) (*string, error)
+	UpdateCustomerAddress(ctx context.Context, address entity.Address) error
+	DeleteCustomerAddress(ctx context.Context, customerID string, addressID string) error
+	GetCustomerPhones(ctx context.Context, customerID string) ([]*entity.Phone, error)
+	GetCustomerPhoneByID(ctx context.Context, customerID string, phoneID string) (*entity.Phone, error)
+	CreateCustomerPhone(ctx context.Context, phone entity.Phone) (*string, error)
+	UpdateCustomerPhone(ctx context.Context, phone entity.Phone) error
+	DeleteCustomerPhone(ctx context.Context, customerID string, phoneID string) error
 }
 
 type customerService struct {
-	logger        slog.Logger
-	customerGtw   gateway.CustomerGateway
-	customerCache cache.CustomerCache
+	logger      slog.Logger
+	customerGtw gateway.CustomerGateway
 }
 
-func NewCustomerService(l slog.Logger, g gateway.CustomerGateway, c cache.CustomerCache) CustomerService {
+func NewCustomerService(l slog.Logger, g gateway.CustomerGateway) CustomerService {
 	return &customerService{
-		logger:        *l.With("layer", "customer-service"),
-		customerGtw:   g,
-		customerCache: c,
+		logger:      *l.With("layer", "customer-service"),
+		customerGtw: g,
 	}
 }
 
-func (s *customerService) GetCustomerList(ctx context.Context) ([]*entity.Customer, error) {
-	s.logger.Info("Getting all customers", "traceID", ctx.Value("traceID"))
-	customerList, err := s.customerGtw.GetCustomerList(ctx)
+func (s *customerService) GetCustomerList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error) {
+	s.logger.Info("Getting customer list", "filter", filter, "traceID", ctx.Value("traceID"))
+	err := normalizeCustomerFilter(&filter)
 	if err != nil {
+		s.logger.Error("Invalid customer list filter", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
 
-	return customerList, nil
-}
-
-func (s *customerService) GetCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error) {
-	s.logger.Info("Getting customer by ID", "ID", customerID, "traceID", ctx.Value("traceID"))
-	customer, err := s.customerGtw.GetCustomerByID(ctx, customerID)
+	customerPage, err := s.customerGtw.GetCustomerList(ctx, filter)
 	if err != nil {
-		s.logger.Error("Failed to get customer by ID", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
 
-	return customer, nil
+	return customerPage, nil
 }
 
-func (s *customerService) V2GetCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error) {
+func (s *customerService) GetCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error) {
 	s.logger.Info("Getting customer by ID", "ID", customerID, "traceID", ctx.Value("traceID"))
-	customer, err := s.customerCache.ReadCacheByID(ctx, customerID)
+	customer, err := s.customerGtw.GetCustomerByID(ctx, customerID)
 	if err != nil {
-		customer, err = s.customerGtw.GetCustomerByID(ctx, customerID)
-		if err != nil {
-			s.logger.Error("Failed to get customer by ID", "error", err, "traceID", ctx.Value("traceID"))
-			return nil, err
-		}
-		s.customerCache.WriteCacheByID(ctx, *customer)
-		return customer, nil
+		s.logger.Error("Failed to get customer by ID", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
 	}
 
 	return customer, nil
@@ -84,18 +111,18 @@ func (s *customerService) GetCustomerByEmail(ctx context.Context, customerEmail
 	return customer, nil
 }
 
-func (s *customerService) V2GetCustomerByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error) {
-	s.logger.Info("Getting customer by email", "email", customerEmail, "traceID", ctx.Value("traceID"))
+// GetCustomerByDocument accepts the CPF with or without its punctuation, it's only logged masked
+func (s *customerService) GetCustomerByDocument(ctx context.Context, cpf string) (*entity.Customer, error) {
+	digits, ok := normalizeCPF(cpf)
+	s.logger.Info("Getting customer by document", "cpf", entity.MaskCPF(digits), "traceID", ctx.Value("traceID"))
+	if !ok {
+		return nil, entity.InvalidField("cpf", "must be a valid CPF, formatted as 123.456.789-09 or 12345678909")
+	}
 
-	customer, err := s.customerCache.ReadCacheByEmail(ctx, customerEmail)
+	customer, err := s.customerGtw.GetCustomerByDocument(ctx, digits)
 	if err != nil {
-		customer, err = s.customerGtw.GetCustomerByEmail(ctx, customerEmail)
-		if err != nil {
-			s.logger.Error("Failed to get customer by email", "error", err, "traceID", ctx.Value("traceID"))
-			return nil, err
-		}
-		s.customerCache.WriteCacheByEmail(ctx, *customer)
-		return customer, nil
+		s.logger.Error("Failed to get customer by document", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
 	}
 
 	return customer, nil
@@ -112,26 +139,70 @@ func (s *customerService) GetCustomerByName(ctx context.Context, customerName st
 	return customer, nil
 }
 
-func (s *customerService) V2GetCustomerByName(ctx context.Context, customerName string) (*entity.Customer, error) {
-	s.logger.Info("Getting customer by name", "name", customerName, "traceID", ctx.Value("traceID"))
+func (s *customerService) SearchCustomers(ctx context.Context, search entity.CustomerSearch) (*entity.CustomerSearchPage, error) {
+	s.logger.Info("Searching customers", "search", search, "traceID", ctx.Value("traceID"))
+	err := normalizeCustomerSearch(&search)
+	if err != nil {
+		s.logger.Error("Invalid customer search", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
 
-	customer, err := s.customerCache.ReadCacheByName(ctx, customerName)
+	searchPage, err := s.customerGtw.SearchCustomers(ctx, search)
 	if err != nil {
-		customer, err = s.customerGtw.GetCustomerByName(ctx, customerName)
-		if err != nil {
-			s.logger.Error("Failed to get customer by name", "error", err, "traceID", ctx.Value("traceID"))
-			return nil, err
-		}
+		s.logger.Error("Failed to search customers", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
 
-		s.customerCache.WriteCacheByName(ctx, *customer)
-		return customer, nil
+	return searchPage, nil
+}
+
+// ExportCustomers takes the filters of the list, but not its pagination, every matching customer is written
+func (s *customerService) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, write func(customer *entity.Customer) error) error {
+	s.logger.Info("Exporting customers", "filter", filter, "traceID", ctx.Value("traceID"))
+	// the query string of a list page can be reused as is, its limit and cursor are left out
+	filter.Limit, filter.Cursor = 0, ""
+	err := normalizeCustomerFilterFields(&filter)
+	if err != nil {
+		s.logger.Error("Invalid customer export filter", "error", err, "traceID", ctx.Value("traceID"))
+		return err
 	}
 
-	return customer, nil
+	err = s.customerGtw.ExportCustomers(ctx, filter, write)
+	if err != nil {
+		s.logger.Error("Failed to export customers", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
+
+func (s *customerService) GetUpcomingBirthdays(ctx context.Context, filter entity.BirthdayFilter) (*entity.BirthdayPage, error) {
+	s.logger.Info("Getting upcoming birthdays", "filter", filter, "traceID", ctx.Value("traceID"))
+	err := normalizeBirthdayFilter(&filter)
+	if err != nil {
+		s.logger.Error("Invalid upcoming birthdays filter", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	birthdayPage, err := s.customerGtw.GetUpcomingBirthdays(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get upcoming birthdays", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return birthdayPage, nil
 }
 
 func (s *customerService) CreateCustomer(ctx context.Context, customer entity.Customer) (*string, error) {
 	s.logger.Info("Creating new customer", "data", customer, "traceID", ctx.Value("traceID"))
+	customer = trimCustomer(customer)
+	err := validateCustomer(customer)
+	if err != nil {
+		s.logger.Error("Invalid customer", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+	customer.CPF = cpfDigits(customer.CPF)
+
 	id, err := s.customerGtw.CreateCustomer(ctx, customer)
 	if err != nil {
 		s.logger.Error("Failed to create customer", "error", err, "traceID", ctx.Value("traceID"))
@@ -141,20 +212,67 @@ func (s *customerService) CreateCustomer(ctx context.Context, customer entity.Cu
 	return id, nil
 }
 
-func (s *customerService) UpdateCustomer(ctx context.Context, customer entity.Customer) error {
+func (s *customerService) UpdateCustomer(ctx context.Context, customer entity.Customer, ifMatch *int64) (*entity.Customer, error) {
 	s.logger.Info("Updating customer", "data", customer)
-	err := s.customerGtw.UpdateCustomer(ctx, customer)
+	customer = trimCustomer(customer)
+	err := validateCustomer(customer)
+	if err != nil {
+		s.logger.Error("Invalid customer", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+	customer.CPF = cpfDigits(customer.CPF)
+
+	err = s.customerGtw.UpdateCustomer(ctx, customer, ifMatch)
 	if err != nil {
 		s.logger.Error("Failed to update customer by ID", "error", err, "traceID", ctx.Value("traceID"))
-		return err
+		return nil, err
 	}
 
-	return nil
+	updated, err := s.customerGtw.GetCustomerByID(ctx, *customer.ID)
+	if err != nil {
+		s.logger.Error("Failed to get updated customer", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return updated, nil
 }
 
-func (s *customerService) DeleteCustomerByID(ctx context.Context, customerID string) error {
+func (s *customerService) PatchCustomer(ctx context.Context, patch entity.CustomerPatch, ifMatch *int64) (*entity.Customer, error) {
+	s.logger.Info("Patching customer", "data", patch, "traceID", ctx.Value("traceID"))
+	patch = trimCustomerPatch(patch)
+	err := validateCustomerPatch(patch)
+	if err != nil {
+		s.logger.Error("Invalid customer patch", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+	if patch.CPF != nil && *patch.CPF != "" {
+		patch.CPF = cpfDigits(patch.CPF)
+	}
+
+	// an empty merge patch changes nothing, it just returns the current customer
+	if !patch.IsEmpty() {
+		err = s.customerGtw.PatchCustomer(ctx, patch, ifMatch)
+		if err != nil {
+			s.logger.Error("Failed to patch customer by ID", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+	}
+
+	customer, err := s.customerGtw.GetCustomerByID(ctx, patch.ID)
+	if err != nil {
+		s.logger.Error("Failed to get patched customer", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+	if patch.IsEmpty() && ifMatch != nil && customer.Version != *ifMatch {
+		return nil, fmt.Errorf("%w with ID=%s, expected version %d", entity.ErrCustomerVersionMismatch, patch.ID, *ifMatch)
+	}
+
+	return customer, nil
+}
+
+func (s *customerService) DeleteCustomerByID(ctx context.Context, customerID string, ifMatch *int64) error {
 	s.logger.Info("Deleting customer by ID", "ID", customerID)
-	err := s.customerGtw.DeleteCustomerByID(ctx, customerID)
+	err := s.customerGtw.DeleteCustomerByID(ctx, customerID, ifMatch)
 	if err != nil {
 		s.logger.Error("Failed to delete customer by ID", "error", err, "traceID", ctx.Value("traceID"))
 		return err
@@ -162,3 +280,118 @@ func (s *customerService) DeleteCustomerByID(ctx context.Context, customerID str
 
 	return nil
 }
+
+func (s *customerService) RestoreCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error) {
+	s.logger.Info("Restoring customer by ID", "ID", customerID, "traceID", ctx.Value("traceID"))
+	err := s.customerGtw.RestoreCustomerByID(ctx, customerID)
+	if err != nil {
+		s.logger.Error("Failed to restore customer by ID", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	customer, err := s.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		s.logger.Error("Failed to get restored customer", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (s *customerService) EraseCustomerByID(ctx context.Context, erasure entity.CustomerErasure) error {
+	s.logger.Warn("Erasing customer by ID", "ID", erasure.CustomerID, "actor", erasure.Actor, "traceID", ctx.Value("traceID"))
+	if strings.TrimSpace(erasure.Actor) == "" {
+		return entity.InvalidField("X-Actor", "is required for the audit log")
+	}
+
+	err := s.customerGtw.EraseCustomerByID(ctx, erasure)
+	if err != nil {
+		s.logger.Error("Failed to erase customer by ID", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
+
+func normalizeCustomerFilter(filter *entity.CustomerFilter) error {
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxPageLimit {
+		return entity.InvalidField("limit", fmt.Sprintf("must be between 1 and %d", maxPageLimit))
+	}
+
+	return normalizeCustomerFilterFields(filter)
+}
+
+// normalizeCustomerFilterFields checks the sort and the filters shared by the list and the export,
+// not the pagination of the list
+func normalizeCustomerFilterFields(filter *entity.CustomerFilter) error {
+	if filter.SortBy == "" {
+		filter.SortBy = "id"
+	}
+	if !customerSortFields[filter.SortBy] {
+		return entity.InvalidField("sort_by", fmt.Sprintf("can't be %s", filter.SortBy))
+	}
+
+	if filter.SortOrder == "" {
+		filter.SortOrder = "asc"
+	}
+	if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		return entity.InvalidField("sort_order", "must be asc or desc")
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return entity.InvalidField("created_from", "must be before created_to")
+	}
+	if filter.CreatedFrom != nil && filter.CreatedBefore != nil && !filter.CreatedFrom.Before(*filter.CreatedBefore) {
+		return entity.InvalidField("created_from", "must be before created_to")
+	}
+
+	if filter.MinAge != nil && (*filter.MinAge < 0 || *filter.MinAge > maxAge) {
+		return entity.InvalidField("min_age", fmt.Sprintf("must be between 0 and %d", maxAge))
+	}
+	if filter.MaxAge != nil && (*filter.MaxAge < 0 || *filter.MaxAge > maxAge) {
+		return entity.InvalidField("max_age", fmt.Sprintf("must be between 0 and %d", maxAge))
+	}
+	if filter.MinAge != nil && filter.MaxAge != nil && *filter.MinAge > *filter.MaxAge {
+		return entity.InvalidField("min_age", "can't be greater than max_age")
+	}
+
+	return nil
+}
+
+// normalizeBirthdayFilter counts today as the first day, so days=1 lists today's birthdays
+func normalizeBirthdayFilter(filter *entity.BirthdayFilter) error {
+	if filter.Days == 0 {
+		filter.Days = defaultBirthdays
+	}
+	if filter.Days < 0 || filter.Days > maxBirthdays {
+		return entity.InvalidField("days", fmt.Sprintf("must be between 1 and %d", maxBirthdays))
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxPageLimit {
+		return entity.InvalidField("limit", fmt.Sprintf("must be between 1 and %d", maxPageLimit))
+	}
+
+	return nil
+}
+
+func normalizeCustomerSearch(search *entity.CustomerSearch) error {
+	search.Query = strings.Join(strings.Fields(search.Query), " ")
+	if utf8.RuneCountInString(search.Query) < minSearchQuery {
+		return entity.InvalidField("q", fmt.Sprintf("must have at least %d characters", minSearchQuery))
+	}
+
+	if search.Limit == 0 {
+		search.Limit = defaultSearchLimit
+	}
+	if search.Limit < 0 || search.Limit > maxSearchLimit {
+		return entity.InvalidField("limit", fmt.Sprintf("must be between 1 and %d", maxSearchLimit))
+	}
+
+	return nil
+}
@@ -6,35 +6,42 @@ import (
 	"cmd/customer-service/test"
 	"context"
 	"errors"
+	"io"
+	"log/slog"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var customerSvc = new(mocks.CustomerService)
 
 func Test_CustomerSvc_GetCustomerList(t *testing.T) {
 	type args struct {
-		ctx context.Context
+		ctx    context.Context
+		filter entity.CustomerFilter
 	}
 
 	scenarios := []struct {
 		name        string
 		args        args
-		want        []*entity.Customer
+		want        *entity.CustomerPage
 		expectedErr error
 	}{
-		{"success", args{context.Background()}, test.ACustomerArray, nil},
-		{"error", args{context.TODO()}, nil, errors.New("error")},
+		{"success", args{context.Background(), entity.CustomerFilter{Limit: 2}}, test.ACustomerPage, nil},
+		{"next page", args{context.Background(), entity.CustomerFilter{Limit: 2, Cursor: test.NextCursor}}, test.ALastCustomerPage, nil},
+		{"error", args{context.TODO(), entity.CustomerFilter{SortBy: "invalid"}}, nil, errors.New("error")},
 	}
 
 	for _, tt := range scenarios {
 		tt := tt
 
-		customerSvc.On("GetCustomerList", tt.args.ctx).Return(tt.want, tt.expectedErr)
+		customerSvc.On("GetCustomerList", tt.args.ctx, tt.args.filter).Return(tt.want, tt.expectedErr)
 
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := customerSvc.GetCustomerList(tt.args.ctx)
+			got, err := customerSvc.GetCustomerList(tt.args.ctx, tt.args.filter)
 
 			assert.ErrorIs(t, err, tt.expectedErr)
 			assert.Equal(t, tt.want, got)
@@ -110,10 +117,30 @@ func Test_CustomerSvc_CreateCustomer(t *testing.T) {
 	}
 }
 
+func Test_CustomerSvc_CreateCustomer_TrimsFields(t *testing.T) {
+	ctx := context.Background()
+	gtw := new(mocks.CustomerGateway)
+	svc := NewCustomerService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw)
+
+	// the blanks don't count towards the column limits, they aren't stored
+	padded := *test.ACustomer
+	padded.Name = "  " + strings.Repeat("a", maxNameLength) + "  "
+	padded.Email = " " + padded.Email + " "
+	stored := *test.ACustomer
+	stored.Name = strings.Repeat("a", maxNameLength)
+	gtw.On("CreateCustomer", ctx, stored).Return(&test.CustomerID, nil).Once()
+
+	id, err := svc.CreateCustomer(ctx, padded)
+	assert.NoError(t, err)
+	assert.Equal(t, &test.CustomerID, id)
+	gtw.AssertExpectations(t)
+}
+
 func Test_CustomerSvc_UpdateCustomer(t *testing.T) {
 	type args struct {
 		ctx      context.Context
 		customer entity.Customer
+		ifMatch  *int64
 	}
 
 	aCustomerNotFound := &entity.Customer{
@@ -125,21 +152,24 @@ func Test_CustomerSvc_UpdateCustomer(t *testing.T) {
 	scenarios := []struct {
 		name        string
 		args        args
+		want        *entity.Customer
 		expectedErr error
 	}{
-		{"success", args{context.Background(), *test.ACustomer}, nil},
-		{"error", args{context.Background(), *aCustomerNotFound}, errors.New("not found")},
+		{"success", args{context.Background(), *test.ACustomer, nil}, test.ACustomer, nil},
+		{"error", args{context.Background(), *aCustomerNotFound, nil}, nil, errors.New("not found")},
+		{"version mismatch", args{context.Background(), *test.ACustomer, &test.StaleVersion}, nil, entity.ErrCustomerVersionMismatch},
 	}
 
 	for _, tt := range scenarios {
 		tt := tt
 
-		customerSvc.On("UpdateCustomer", tt.args.ctx, tt.args.customer).Return(tt.expectedErr)
+		customerSvc.On("UpdateCustomer", tt.args.ctx, tt.args.customer, tt.args.ifMatch).Return(tt.want, tt.expectedErr)
 
 		t.Run(tt.name, func(t *testing.T) {
-			err := customerSvc.UpdateCustomer(tt.args.ctx, tt.args.customer)
+			got, err := customerSvc.UpdateCustomer(tt.args.ctx, tt.args.customer, tt.args.ifMatch)
 
 			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.want, got)
 			customerSvc.AssertExpectations(t)
 		})
 	}
@@ -149,6 +179,7 @@ func Test_CustomerSvc_DeleteCustomerByID(t *testing.T) {
 	type args struct {
 		ctx        context.Context
 		customerID string
+		ifMatch    *int64
 	}
 
 	scenarios := []struct {
@@ -156,20 +187,222 @@ func Test_CustomerSvc_DeleteCustomerByID(t *testing.T) {
 		args        args
 		expectedErr error
 	}{
-		{"success", args{context.Background(), test.CustomerID}, nil},
-		{"error", args{context.Background(), "errorID"}, errors.New("not found")},
+		{"success", args{context.Background(), test.CustomerID, nil}, nil},
+		{"error", args{context.Background(), "errorID", nil}, errors.New("not found")},
+		{"version mismatch", args{context.Background(), test.CustomerID, &test.StaleVersion}, entity.ErrCustomerVersionMismatch},
 	}
 
 	for _, tt := range scenarios {
 		tt := tt
 
-		customerSvc.On("DeleteCustomerByID", tt.args.ctx, tt.args.customerID).Return(tt.expectedErr)
+		customerSvc.On("DeleteCustomerByID", tt.args.ctx, tt.args.customerID, tt.args.ifMatch).Return(tt.expectedErr)
 
 		t.Run(tt.name, func(t *testing.T) {
-			err := customerSvc.DeleteCustomerByID(tt.args.ctx, tt.args.customerID)
+			err := customerSvc.DeleteCustomerByID(tt.args.ctx, tt.args.customerID, tt.args.ifMatch)
 
 			assert.ErrorIs(t, err, tt.expectedErr)
 			customerSvc.AssertExpectations(t)
 		})
 	}
 }
+
+func Test_CustomerSvc_RestoreCustomerByID(t *testing.T) {
+	type args struct {
+		ctx        context.Context
+		customerID string
+	}
+
+	scenarios := []struct {
+		name        string
+		args        args
+		want        *entity.Customer
+		expectedErr error
+	}{
+		{"success", args{context.Background(), test.CustomerID}, test.ACustomer, nil},
+		{"not deleted", args{context.Background(), test.ErrorID}, nil, entity.ErrCustomerNotFound},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		customerSvc.On("RestoreCustomerByID", tt.args.ctx, tt.args.customerID).Return(tt.want, tt.expectedErr)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := customerSvc.RestoreCustomerByID(tt.args.ctx, tt.args.customerID)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.want, got)
+			customerSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_CustomerSvc_EraseCustomerByID(t *testing.T) {
+	ctx := context.Background()
+	gtw := new(mocks.CustomerGateway)
+	svc := NewCustomerService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw)
+
+	erasure := entity.CustomerErasure{CustomerID: test.CustomerID, Actor: "dpo@mock.com", Reason: "GDPR request"}
+	gtw.On("EraseCustomerByID", ctx, erasure).Return(nil).Once()
+	assert.NoError(t, svc.EraseCustomerByID(ctx, erasure))
+
+	// nothing is erased without someone to hold accountable in the audit log
+	assert.Error(t, svc.EraseCustomerByID(ctx, entity.CustomerErasure{CustomerID: test.CustomerID, Actor: " "}))
+	gtw.AssertExpectations(t)
+}
+
+func Test_CustomerSvc_ExportCustomers(t *testing.T) {
+	ctx := context.Background()
+	gtw := new(mocks.CustomerGateway)
+	svc := NewCustomerService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw)
+	write := func(customer *entity.Customer) error { return nil }
+
+	gtw.On("ExportCustomers", ctx, entity.CustomerFilter{Name: "Jo", SortBy: "id", SortOrder: "asc"}, mock.Anything).Return(nil).Twice()
+	assert.NoError(t, svc.ExportCustomers(ctx, entity.CustomerFilter{Name: "Jo"}, write))
+	// the pagination of a list page is ignored, even a limit the list would refuse
+	assert.NoError(t, svc.ExportCustomers(ctx, entity.CustomerFilter{Limit: maxPageLimit * 10, Cursor: test.NextCursor, Name: "Jo"}, write))
+
+	// the filters are checked like the ones of the list, before anything is read
+	assert.Error(t, svc.ExportCustomers(ctx, entity.CustomerFilter{SortBy: "password"}, write))
+	gtw.AssertExpectations(t)
+}
+
+func Test_NormalizeCustomerFilter(t *testing.T) {
+	createdFrom := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	createdTo := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextDay := createdTo.AddDate(0, 0, 1)
+	young, old, tooOld := 18, 65, maxAge+1
+
+	scenarios := []struct {
+		name        string
+		filter      entity.CustomerFilter
+		want        entity.CustomerFilter
+		expectedErr bool
+	}{
+		{"defaults", entity.CustomerFilter{}, entity.CustomerFilter{Limit: defaultPageLimit, SortBy: "id", SortOrder: "asc"}, false},
+		{"keeps values", entity.CustomerFilter{Limit: 10, SortBy: "email", SortOrder: "desc"}, entity.CustomerFilter{Limit: 10, SortBy: "email", SortOrder: "desc"}, false},
+		{"limit too big", entity.CustomerFilter{Limit: maxPageLimit + 1}, entity.CustomerFilter{}, true},
+		{"negative limit", entity.CustomerFilter{Limit: -1}, entity.CustomerFilter{}, true},
+		{"invalid sort field", entity.CustomerFilter{SortBy: "password"}, entity.CustomerFilter{}, true},
+		{"invalid sort order", entity.CustomerFilter{SortOrder: "up"}, entity.CustomerFilter{}, true},
+		{"inverted created range", entity.CustomerFilter{CreatedFrom: &createdFrom, CreatedTo: &createdTo}, entity.CustomerFilter{}, true},
+		{"created on a single day", entity.CustomerFilter{CreatedFrom: &createdTo, CreatedBefore: &nextDay}, entity.CustomerFilter{Limit: defaultPageLimit, SortBy: "id", SortOrder: "asc", CreatedFrom: &createdTo, CreatedBefore: &nextDay}, false},
+		{"created before the start of the range", entity.CustomerFilter{CreatedFrom: &nextDay, CreatedBefore: &nextDay}, entity.CustomerFilter{}, true},
+		{"age range", entity.CustomerFilter{MinAge: &young, MaxAge: &old}, entity.CustomerFilter{Limit: defaultPageLimit, SortBy: "id", SortOrder: "asc", MinAge: &young, MaxAge: &old}, false},
+		{"inverted age range", entity.CustomerFilter{MinAge: &old, MaxAge: &young}, entity.CustomerFilter{}, true},
+		{"age too big", entity.CustomerFilter{MaxAge: &tooOld}, entity.CustomerFilter{}, true},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			err := normalizeCustomerFilter(&filter)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			if !tt.expectedErr {
+				assert.Equal(t, tt.want, filter)
+			}
+		})
+	}
+}
+
+func Test_NormalizeBirthdayFilter(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		filter      entity.BirthdayFilter
+		want        entity.BirthdayFilter
+		expectedErr bool
+	}{
+		{"defaults", entity.BirthdayFilter{}, entity.BirthdayFilter{Days: defaultBirthdays, Limit: defaultPageLimit}, false},
+		{"keeps values", entity.BirthdayFilter{Days: 30, Limit: 10}, entity.BirthdayFilter{Days: 30, Limit: 10}, false},
+		{"window too big", entity.BirthdayFilter{Days: maxBirthdays + 1}, entity.BirthdayFilter{}, true},
+		{"negative window", entity.BirthdayFilter{Days: -1}, entity.BirthdayFilter{}, true},
+		{"limit too big", entity.BirthdayFilter{Limit: maxPageLimit + 1}, entity.BirthdayFilter{}, true},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			err := normalizeBirthdayFilter(&filter)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			if !tt.expectedErr {
+				assert.Equal(t, tt.want, filter)
+			}
+		})
+	}
+}
+
+func Test_NormalizeCustomerSearch(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		search      entity.CustomerSearch
+		want        entity.CustomerSearch
+		expectedErr bool
+	}{
+		{"defaults", entity.CustomerSearch{Query: "João"}, entity.CustomerSearch{Query: "João", Limit: defaultSearchLimit}, false},
+		{"collapses spaces", entity.CustomerSearch{Query: "  joao   silva ", Limit: 5}, entity.CustomerSearch{Query: "joao silva", Limit: 5}, false},
+		{"query too short", entity.CustomerSearch{Query: " ã "}, entity.CustomerSearch{}, true},
+		{"limit too big", entity.CustomerSearch{Query: "joao", Limit: maxSearchLimit + 1}, entity.CustomerSearch{}, true},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			search := tt.search
+			err := normalizeCustomerSearch(&search)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			if !tt.expectedErr {
+				assert.Equal(t, tt.want, search)
+			}
+		})
+	}
+}
+
+func Test_CustomerSvc_UpdateCustomerAddress(t *testing.T) {
+	ctx := context.Background()
+	gtw := new(mocks.CustomerGateway)
+	svc := NewCustomerService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw)
+
+	customer := *test.ACustomer
+	customer.Addresses = []*entity.Address{test.AnAddress}
+	gtw.On("GetCustomerByID", ctx, test.CustomerID).Return(&customer, nil)
+
+	address := *test.AnAddress
+	address.Street = "Rua Augusta"
+	gtw.On("UpdateCustomerAddress", ctx, address).Return(nil).Once()
+	assert.NoError(t, svc.UpdateCustomerAddress(ctx, address))
+
+	// the default address keeps the flag until another one takes it
+	address.IsDefault = false
+	var validationErr *entity.ValidationError
+	assert.ErrorAs(t, svc.UpdateCustomerAddress(ctx, address), &validationErr)
+
+	missingID := "missingID"
+	address.ID = &missingID
+	assert.ErrorIs(t, svc.UpdateCustomerAddress(ctx, address), entity.ErrAddressNotFound)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CustomerSvc_GetCustomerByPhone(t *testing.T) {
+	ctx := context.Background()
+	gtw := new(mocks.CustomerGateway)
+	svc := NewCustomerService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw)
+
+	// the number is looked up the way it's stored
+	gtw.On("GetCustomerByPhone", ctx, test.APhone.Number).Return(test.ACustomer, nil).Once()
+	customer, err := svc.GetCustomerByPhone(ctx, "(11) 98765-4321")
+	assert.NoError(t, err)
+	assert.Equal(t, test.ACustomer, customer)
+
+	var validationErr *entity.ValidationError
+	_, err = svc.GetCustomerByPhone(ctx, "98765")
+	assert.ErrorAs(t, err, &validationErr)
+	gtw.AssertExpectations(t)
+}
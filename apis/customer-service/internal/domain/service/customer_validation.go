@@ -0,0 +1,125 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the varchar columns of the customers table
+const (
+	maxNameLength  = 30
+	maxEmailLength = 200
+)
+
+// fieldErrors collects every invalid field before failing, so clients can fix them all at once
+type fieldErrors []entity.FieldError
+
+func (f *fieldErrors) add(field string, message string) {
+	*f = append(*f, entity.FieldError{Field: field, Message: message})
+}
+
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &entity.ValidationError{Fields: f}
+}
+
+// trimCustomer drops the blanks around the text fields, so the lengths checked are the ones stored
+func trimCustomer(customer entity.Customer) entity.Customer {
+	customer.Name = strings.TrimSpace(customer.Name)
+	customer.Surname = strings.TrimSpace(customer.Surname)
+	customer.Email = strings.TrimSpace(customer.Email)
+	return customer
+}
+
+func trimCustomerPatch(patch entity.CustomerPatch) entity.CustomerPatch {
+	patch.Name = trimmed(patch.Name)
+	patch.Surname = trimmed(patch.Surname)
+	patch.Email = trimmed(patch.Email)
+	return patch
+}
+
+func trimmed(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	return &trimmed
+}
+
+// validateCustomer checks a full customer, as sent on create and update, where every field is required
+func validateCustomer(customer entity.Customer) error {
+	errs := fieldErrors{}
+	checkName(&errs, "name", customer.Name)
+	checkName(&errs, "surname", customer.Surname)
+	checkEmail(&errs, customer.Email)
+	checkBirthdate(&errs, customer.Birthdate)
+	checkCPF(&errs, customer.CPF)
+	return errs.err()
+}
+
+// validateCustomerPatch only checks the fields present in the patch
+func validateCustomerPatch(patch entity.CustomerPatch) error {
+	errs := fieldErrors{}
+	if patch.Name != nil {
+		checkName(&errs, "name", *patch.Name)
+	}
+	if patch.Surname != nil {
+		checkName(&errs, "surname", *patch.Surname)
+	}
+	if patch.Email != nil {
+		checkEmail(&errs, *patch.Email)
+	}
+	if patch.Birthdate != nil {
+		checkBirthdate(&errs, *patch.Birthdate)
+	}
+	checkCPF(&errs, patch.CPF)
+	return errs.err()
+}
+
+func checkName(errs *fieldErrors, field string, value string) {
+	if !validLength(value, maxNameLength) {
+		errs.add(field, fmt.Sprintf("must have between 1 and %d characters", maxNameLength))
+	}
+}
+
+func checkEmail(errs *fieldErrors, value string) {
+	if strings.TrimSpace(value) == "" {
+		errs.add("email", "is required")
+		return
+	}
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || !validLength(value, maxEmailLength) {
+		errs.add("email", fmt.Sprintf("must be a valid address with at most %d characters", maxEmailLength))
+	}
+}
+
+// checkBirthdate only checks the date is set and past, the JSON decoding already checked its format
+func checkBirthdate(errs *fieldErrors, value entity.Date) {
+	if value.IsZero() {
+		errs.add("birthdate", "is required")
+		return
+	}
+	if value.After(entity.Today().Time) {
+		errs.add("birthdate", "can't be in the future")
+	}
+}
+
+// checkCPF only checks a CPF that was sent, it's optional
+func checkCPF(errs *fieldErrors, value *string) {
+	if value == nil || *value == "" {
+		return
+	}
+	if _, ok := normalizeCPF(*value); !ok {
+		errs.add("cpf", "must be a valid CPF, formatted as 123.456.789-09 or 12345678909")
+	}
+}
+
+// validLength checks the value isn't blank and fits its column as it's stored, blanks included
+func validLength(value string, max int) bool {
+	return strings.TrimSpace(value) != "" && utf8.RuneCountInString(value) <= max
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"cmd/customer-service/test"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateCustomer(t *testing.T) {
+	invalidCPF := "123.456.789-00"
+	birthdate := entity.NewDate(1990, time.March, 7)
+	tomorrow := entity.Date{Time: entity.Today().AddDate(0, 0, 1)}
+	scenarios := []struct {
+		name          string
+		customer      entity.Customer
+		invalidFields []string
+	}{
+		{"valid customer", *test.ACustomer, nil},
+		{"empty customer", entity.Customer{}, []string{"name", "surname", "email", "birthdate"}},
+		{"name too long", entity.Customer{Name: strings.Repeat("a", maxNameLength+1), Surname: "Doe", Email: "john@mock.com", Birthdate: birthdate}, []string{"name"}},
+		{"padded name too long", entity.Customer{Name: " " + strings.Repeat("a", maxNameLength) + " ", Surname: "Doe", Email: "john@mock.com", Birthdate: birthdate}, []string{"name"}},
+		{"invalid email and future birthdate", entity.Customer{Name: "John", Surname: "Doe", Email: "john@", Birthdate: tomorrow}, []string{"email", "birthdate"}},
+		{"invalid CPF", entity.Customer{Name: "John", Surname: "Doe", Email: "john@mock.com", Birthdate: birthdate, CPF: &invalidCPF}, []string{"cpf"}},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCustomer(tt.customer)
+			if tt.invalidFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *entity.ValidationError
+			assert.True(t, errors.As(err, &validationErr))
+			fields := make([]string, 0)
+			for _, field := range validationErr.Fields {
+				fields = append(fields, field.Field)
+			}
+			assert.Equal(t, tt.invalidFields, fields)
+		})
+	}
+}
+
+func Test_ValidateCustomerPatch(t *testing.T) {
+	valid := func(value string) *string { return &value }
+	date := func(value entity.Date) *entity.Date { return &value }
+
+	scenarios := []struct {
+		name        string
+		patch       entity.CustomerPatch
+		expectedErr bool
+	}{
+		{"empty patch", entity.CustomerPatch{ID: test.CustomerID}, false},
+		{"valid fields", entity.CustomerPatch{ID: test.CustomerID, Name: valid("João"), Email: valid("joao@mock.com"), Birthdate: date(entity.NewDate(1990, time.March, 7))}, false},
+		{"blank name", entity.CustomerPatch{ID: test.CustomerID, Name: valid("  ")}, true},
+		{"surname too long", entity.CustomerPatch{ID: test.CustomerID, Surname: valid(strings.Repeat("a", maxNameLength+1))}, true},
+		{"invalid email", entity.CustomerPatch{ID: test.CustomerID, Email: valid("John <john@mock.com>")}, true},
+		{"empty birthdate", entity.CustomerPatch{ID: test.CustomerID, Birthdate: date(entity.Date{})}, true},
+		{"future birthdate", entity.CustomerPatch{ID: test.CustomerID, Birthdate: date(entity.Date{Time: entity.Today().AddDate(1, 0, 0)})}, true},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCustomerPatch(tt.patch)
+			assert.Equal(t, tt.expectedErr, err != nil)
+		})
+	}
+}
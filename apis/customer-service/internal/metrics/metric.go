@@ -13,6 +13,7 @@ type CustomerMetrics struct {
 	ReqByStatusCode  *prometheus.CounterVec
 	Duration         *prometheus.HistogramVec
 	ExternalDuration *prometheus.HistogramVec
+	CacheRequests    *prometheus.CounterVec
 }
 
 var bucket = []float64{0.0, 0.001, 0.002, 0.003, 0.005, 0.007, 0.009, 0.01, 0.015, 0.02, 0.023, 0.025, 0.027, 0.029, 0.03, 0.031, 0.033, 0.035, 0.04, 0.05, 0.1, 0.15, 0.2, 0.25, 0.3}
@@ -36,9 +37,13 @@ func NewCustomerMetrics(l slog.Logger, reg prometheus.Registerer) *CustomerMetri
 			Help:    "Duration of external request",
 			Buckets: bucket},
 			[]string{"service", "resource", "status", "method", "uri"}),
+		CacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_requests",
+			Help: "Cache lookups by tier, key family and result"},
+			[]string{"service", "tier", "family", "result"}),
 	}
 
-	reg.MustRegister(m.ReqByStatusCode, m.Duration, m.ExternalDuration)
+	reg.MustRegister(m.ReqByStatusCode, m.Duration, m.ExternalDuration, m.CacheRequests)
 	return m
 }
 
@@ -64,3 +69,7 @@ func (m *CustomerMetrics) MeasureExternalDuration(start time.Time, resource stri
 		"status":   statusCode,
 	}).Observe(float64(time.Since(start).Seconds()))
 }
+
+func (m *CustomerMetrics) IncCacheRequest(tier string, family string, result string) {
+	m.CacheRequests.With(prometheus.Labels{"service": m.service, "tier": tier, "family": family, "result": result}).Inc()
+}
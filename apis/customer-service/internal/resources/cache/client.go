@@ -36,9 +36,10 @@ func GetCacheClient(logger slog.Logger) (*redis.Client, error) {
 		WriteTimeout: writeTimeout,
 	})
 
+	// the service can run on the DB alone, the cache breaker keeps probing until Redis is back
 	pong, err := client.Ping(context.Background()).Result()
 	if err != nil {
-		return nil, fmt.Errorf("Failed to ping DB: %s", err)
+		logger.Error("Failed to ping cache, starting with cache unavailable", "error", err)
 	}
 
 	logger.Debug("Conn pool created",
@@ -0,0 +1,473 @@
+package cache
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrCacheUnavailable = errors.New("cache is unavailable")
+
+// maxPendingEvictions bounds the mutations remembered while the breaker is open
+const maxPendingEvictions = 10000
+
+type BreakerConfig struct {
+	Threshold int
+	Interval  time.Duration
+}
+
+// cacheFlusher drops every entry of a customer cache at once
+type cacheFlusher interface {
+	evictAll(ctx context.Context) error
+}
+
+// BreakerCustomerCache is a CustomerCache that can report whether Redis is reachable
+type BreakerCustomerCache interface {
+	CustomerCache
+	invalidationPublisher
+	Check(ctx context.Context) error
+}
+
+// breakerCustomerCache stops calling Redis after Threshold consecutive failures and pings it
+// every Interval until it answers again. Mutations made meanwhile can't evict their keys,
+// so they're replayed once Redis is back before the breaker closes.
+type breakerCustomerCache struct {
+	logger slog.Logger
+	client *redis.Client
+	next   CustomerCache
+	config BreakerConfig
+
+	mu                sync.Mutex
+	open              bool
+	failures          int
+	pendingEvictions  map[string]entity.Customer
+	pendingListEvict  bool
+	pendingOverflowed bool
+}
+
+func NewBreakerCustomerCache(l slog.Logger, client *redis.Client, c CustomerCache, cfg BreakerConfig) BreakerCustomerCache {
+	b := &breakerCustomerCache{
+		logger:           *l.With("layer", "customer-cache-breaker"),
+		client:           client,
+		next:             c,
+		config:           cfg,
+		pendingEvictions: make(map[string]entity.Customer),
+	}
+
+	err := client.Ping(context.Background()).Err()
+	if err != nil {
+		b.trip(err)
+	}
+
+	return b
+}
+
+func GetBreakerConfig() (*BreakerConfig, error) {
+	threshold, err := strconv.Atoi(os.Getenv("CACHE_BREAKER_THRESHOLD"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_BREAKER_THRESHOLD from .env: %s", err)
+	}
+
+	interval, err := time.ParseDuration(os.Getenv("CACHE_BREAKER_INTERVAL"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_BREAKER_INTERVAL from .env: %s", err)
+	}
+
+	return &BreakerConfig{
+		Threshold: threshold,
+		Interval:  interval,
+	}, nil
+}
+
+func (b *breakerCustomerCache) Check(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.open {
+		return ErrCacheUnavailable
+	}
+	return nil
+}
+
+func (b *breakerCustomerCache) ReadCacheByID(ctx context.Context, customerID string) (*CachedCustomer, error) {
+	if b.isOpen() {
+		return nil, ErrCacheUnavailable
+	}
+	cached, err := b.next.ReadCacheByID(ctx, customerID)
+	b.report(err)
+	return cached, err
+}
+
+func (b *breakerCustomerCache) WriteCacheByID(ctx context.Context, customerID string, customer *entity.Customer, stale *CachedCustomer) error {
+	if b.isOpen() {
+		return ErrCacheUnavailable
+	}
+	err := b.next.WriteCacheByID(ctx, customerID, customer, stale)
+	b.report(err)
+	return err
+}
+
+func (b *breakerCustomerCache) ReadCacheByEmail(ctx context.Context, customerEmail string) (*CachedCustomer, error) {
+	if b.isOpen() {
+		return nil, ErrCacheUnavailable
+	}
+	cached, err := b.next.ReadCacheByEmail(ctx, customerEmail)
+	b.report(err)
+	return cached, err
+}
+
+func (b *breakerCustomerCache) WriteCacheByEmail(ctx context.Context, customerEmail string, customer *entity.Customer, stale *CachedCustomer) error {
+	if b.isOpen() {
+		return ErrCacheUnavailable
+	}
+	err := b.next.WriteCacheByEmail(ctx, customerEmail, customer, stale)
+	b.report(err)
+	return err
+}
+
+func (b *breakerCustomerCache) ReadCacheByDocument(ctx context.Context, cpf string) (*CachedCustomer, error) {
+	if b.isOpen() {
+		return nil, ErrCacheUnavailable
+	}
+	cached, err := b.next.ReadCacheByDocument(ctx, cpf)
+	b.report(err)
+	return cached, err
+}
+
+func (b *breakerCustomerCache) WriteCacheByDocument(ctx context.Context, cpf string, customer *entity.Customer, stale *CachedCustomer) error {
+	if b.isOpen() {
+		return ErrCacheUnavailable
+	}
+	err := b.next.WriteCacheByDocument(ctx, cpf, customer, stale)
+	b.report(err)
+	return err
+}
+
+func (b *breakerCustomerCache) ReadCacheByName(ctx context.Context, customerName string) (*CachedCustomer, error) {
+	if b.isOpen() {
+		return nil, ErrCacheUnavailable
+	}
+	cached, err := b.next.ReadCacheByName(ctx, customerName)
+	b.report(err)
+	return cached, err
+}
+
+func (b *breakerCustomerCache) WriteCacheByName(ctx context.Context, customerName string, customer *entity.Customer, stale *CachedCustomer) error {
+	if b.isOpen() {
+		return ErrCacheUnavailable
+	}
+	err := b.next.WriteCacheByName(ctx, customerName, customer, stale)
+	b.report(err)
+	return err
+}
+
+func (b *breakerCustomerCache) ReadCacheList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error) {
+	if b.isOpen() {
+		return nil, ErrCacheUnavailable
+	}
+	page, err := b.next.ReadCacheList(ctx, filter)
+	b.report(err)
+	return page, err
+}
+
+func (b *breakerCustomerCache) WriteCacheList(ctx context.Context, filter entity.CustomerFilter, page entity.CustomerPage) error {
+	if b.isOpen() {
+		return ErrCacheUnavailable
+	}
+	err := b.next.WriteCacheList(ctx, filter, page)
+	b.report(err)
+	return err
+}
+
+// RefreshCache is replayed as an eviction, the refreshed data may be outdated by then
+func (b *breakerCustomerCache) RefreshCache(ctx context.Context, customer entity.Customer) error {
+	if b.deferEviction(customer) {
+		return ErrCacheUnavailable
+	}
+	err := b.next.RefreshCache(ctx, customer)
+	b.report(err)
+	if err != nil {
+		b.recordEviction(customer)
+		return err
+	}
+	b.retryPending(ctx)
+	return nil
+}
+
+func (b *breakerCustomerCache) EvictCache(ctx context.Context, customer entity.Customer) error {
+	if b.deferEviction(customer) {
+		return ErrCacheUnavailable
+	}
+	err := b.next.EvictCache(ctx, customer)
+	b.report(err)
+	if err != nil {
+		b.recordEviction(customer)
+		return err
+	}
+	b.retryPending(ctx)
+	return nil
+}
+
+func (b *breakerCustomerCache) EvictCacheBatch(ctx context.Context, customers []entity.Customer) error {
+	b.mu.Lock()
+	if b.open {
+		for _, customer := range customers {
+			b.pend(customer)
+		}
+		b.mu.Unlock()
+		return ErrCacheUnavailable
+	}
+	b.mu.Unlock()
+
+	err := b.next.EvictCacheBatch(ctx, customers)
+	b.report(err)
+	if err != nil {
+		b.mu.Lock()
+		for _, customer := range customers {
+			b.pend(customer)
+		}
+		b.mu.Unlock()
+		return err
+	}
+	b.retryPending(ctx)
+	return nil
+}
+
+func (b *breakerCustomerCache) EvictCacheList(ctx context.Context) error {
+	b.mu.Lock()
+	if b.open {
+		b.pendingListEvict = true
+		b.mu.Unlock()
+		return ErrCacheUnavailable
+	}
+	b.mu.Unlock()
+
+	err := b.next.EvictCacheList(ctx)
+	b.report(err)
+	if err != nil {
+		b.mu.Lock()
+		b.pendingListEvict = true
+		b.mu.Unlock()
+		return err
+	}
+	b.retryPending(ctx)
+	return nil
+}
+
+// Publish is skipped while the breaker is open, the replicas that miss the message drop their
+// local entries once their local TTL runs out
+func (b *breakerCustomerCache) Publish(ctx context.Context, channel string, message interface{}) error {
+	if b.isOpen() {
+		return ErrCacheUnavailable
+	}
+	err := b.client.Publish(ctx, channel, message).Err()
+	b.report(err)
+	return err
+}
+
+func (b *breakerCustomerCache) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// deferEviction remembers the customer to evict once Redis is back, if the breaker is open
+func (b *breakerCustomerCache) deferEviction(customer entity.Customer) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return false
+	}
+	b.pend(customer)
+
+	return true
+}
+
+// recordEviction remembers an eviction that failed, the breaker may still be closed so it's
+// retried after the next eviction that succeeds, or replayed when the breaker closes
+func (b *breakerCustomerCache) recordEviction(customer entity.Customer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pend(customer)
+}
+
+// pend is called under the lock
+func (b *breakerCustomerCache) pend(customer entity.Customer) {
+	if len(b.pendingEvictions) >= maxPendingEvictions {
+		b.pendingOverflowed = true
+		return
+	}
+	key := customer.Email + "|" + customer.Name
+	if customer.CPF != nil {
+		key += "|" + *customer.CPF
+	}
+	if customer.ID != nil {
+		key = *customer.ID + "|" + key
+	}
+	b.pendingEvictions[key] = customer
+	b.pendingListEvict = true
+}
+
+// takePending swaps out the pending evictions, so Redis can be called without the lock. It's
+// called under the lock.
+func (b *breakerCustomerCache) takePending() (map[string]entity.Customer, bool, bool) {
+	evictions, listEvict, overflowed := b.pendingEvictions, b.pendingListEvict, b.pendingOverflowed
+	b.pendingEvictions = make(map[string]entity.Customer)
+	b.pendingListEvict = false
+	b.pendingOverflowed = false
+	return evictions, listEvict, overflowed
+}
+
+// restorePending merges back the evictions that weren't done, next to the ones pended meanwhile.
+// It's called under the lock.
+func (b *breakerCustomerCache) restorePending(evictions map[string]entity.Customer, listEvict bool, overflowed bool) {
+	for key, customer := range evictions {
+		if len(b.pendingEvictions) >= maxPendingEvictions {
+			overflowed = true
+			break
+		}
+		b.pendingEvictions[key] = customer
+	}
+	b.pendingListEvict = b.pendingListEvict || listEvict
+	b.pendingOverflowed = b.pendingOverflowed || overflowed
+}
+
+func (b *breakerCustomerCache) hasPending() bool {
+	return len(b.pendingEvictions) > 0 || b.pendingListEvict || b.pendingOverflowed
+}
+
+// retryPending evicts what failed while the breaker was closed, once an eviction succeeded again
+func (b *breakerCustomerCache) retryPending(ctx context.Context) {
+	b.mu.Lock()
+	if b.open || !b.hasPending() {
+		b.mu.Unlock()
+		return
+	}
+	evictions, listEvict, overflowed := b.takePending()
+	b.mu.Unlock()
+
+	err := b.evict(ctx, evictions, listEvict, overflowed)
+	b.report(err)
+	if err != nil {
+		b.logger.Error("Failed to retry cache evictions", "error", err, "traceID", ctx.Value("traceID"))
+		b.mu.Lock()
+		b.restorePending(evictions, listEvict, overflowed)
+		b.mu.Unlock()
+	}
+}
+
+func (b *breakerCustomerCache) report(err error) {
+	if err == nil || err == redis.Nil || errors.Is(err, context.Canceled) {
+		b.mu.Lock()
+		b.failures = 0
+		b.mu.Unlock()
+		return
+	}
+
+	b.mu.Lock()
+	b.failures++
+	tripped := b.failures >= b.config.Threshold
+	b.mu.Unlock()
+	if tripped {
+		b.trip(err)
+	}
+}
+
+func (b *breakerCustomerCache) trip(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.open {
+		return
+	}
+	b.open = true
+	b.logger.Error("Cache is unavailable, bypassing it", "error", err)
+	go b.probe()
+}
+
+func (b *breakerCustomerCache) probe() {
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		err := b.client.Ping(ctx).Err()
+		if err != nil {
+			b.logger.Debug("Cache is still unavailable", "error", err)
+			continue
+		}
+
+		err = b.replay(ctx)
+		if err != nil {
+			b.logger.Error("Failed to replay cache evictions", "error", err)
+			continue
+		}
+
+		b.logger.Info("Cache is available again")
+		return
+	}
+}
+
+// replay evicts what changed while the breaker was open and closes it. Redis is called without
+// the lock, the mutations made meanwhile are pended again and the breaker only closes under the
+// lock once nothing is pending, so no mutation slips between the last eviction and the closing.
+func (b *breakerCustomerCache) replay(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		if !b.hasPending() {
+			b.open = false
+			b.failures = 0
+			b.mu.Unlock()
+			return nil
+		}
+		evictions, listEvict, overflowed := b.takePending()
+		b.mu.Unlock()
+
+		err := b.evict(ctx, evictions, listEvict, overflowed)
+		if err != nil {
+			b.mu.Lock()
+			b.restorePending(evictions, listEvict, overflowed)
+			b.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// evict runs the pending evictions, the ones done are removed from evictions
+func (b *breakerCustomerCache) evict(ctx context.Context, evictions map[string]entity.Customer, listEvict bool, overflowed bool) error {
+	if overflowed {
+		flusher, ok := b.next.(cacheFlusher)
+		if !ok {
+			return fmt.Errorf("too many mutations while cache was unavailable and %T can't be flushed", b.next)
+		}
+		b.logger.Error("Too many mutations while cache was unavailable, evicting every customer entry")
+		err := flusher.evictAll(ctx)
+		if err != nil {
+			return err
+		}
+		clear(evictions)
+	}
+
+	for key, customer := range evictions {
+		err := b.next.EvictCache(ctx, customer)
+		if err != nil {
+			return err
+		}
+		delete(evictions, key)
+	}
+
+	if listEvict {
+		return b.next.EvictCacheList(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package cache_test
+
+import (
+	"cmd/customer-service/internal/metrics"
+	"cmd/customer-service/internal/resources/cache"
+	"cmd/customer-service/mocks"
+	"cmd/customer-service/test"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var aBreakerConfig = cache.BreakerConfig{Threshold: 2, Interval: 10 * time.Millisecond}
+
+func newBreakerCache(t *testing.T) (cache.BreakerCustomerCache, *miniredis.Miniredis) {
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr(), MaxRetries: -1})
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	redisCache := cache.NewCustomerCache(logger, metrics.NewCustomerMetrics(logger, prometheus.NewRegistry()), client, aCacheTTL, aDocumentHasher)
+	return cache.NewBreakerCustomerCache(logger, client, redisCache, aBreakerConfig), redisServer
+}
+
+func Test_BreakerCustomerCache_OpensAndRecovers(t *testing.T) {
+	ctx := context.Background()
+	breaker, redisServer := newBreakerCache(t)
+	customer := *test.ACustomer
+
+	assert.NoError(t, breaker.Check(ctx))
+	assert.NoError(t, breaker.WriteCacheByID(ctx, test.CustomerID, &customer, nil))
+
+	redisServer.Close()
+	for i := 0; i < aBreakerConfig.Threshold; i++ {
+		_, err := breaker.ReadCacheByID(ctx, test.CustomerID)
+		assert.Error(t, err)
+	}
+	assert.ErrorIs(t, breaker.Check(ctx), cache.ErrCacheUnavailable)
+
+	// while open Redis isn't called at all and evictions are kept for later
+	_, err := breaker.ReadCacheByID(ctx, test.CustomerID)
+	assert.ErrorIs(t, err, cache.ErrCacheUnavailable)
+	assert.ErrorIs(t, breaker.EvictCache(ctx, customer), cache.ErrCacheUnavailable)
+
+	assert.NoError(t, redisServer.Restart())
+	assert.Eventually(t, func() bool {
+		return breaker.Check(ctx) == nil
+	}, time.Second, 5*time.Millisecond)
+
+	// the entry cached before the outage was evicted once Redis came back
+	_, err = breaker.ReadCacheByID(ctx, test.CustomerID)
+	assert.ErrorIs(t, err, redis.Nil)
+}
+
+func Test_BreakerCustomerCache_StartsOpenWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+	redisServer := miniredis.RunT(t)
+	addr := redisServer.Addr()
+	redisServer.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+	redisCache := cache.NewCustomerCache(logger, metrics.NewCustomerMetrics(logger, prometheus.NewRegistry()), client, aCacheTTL, aDocumentHasher)
+	breaker := cache.NewBreakerCustomerCache(logger, client, redisCache, aBreakerConfig)
+
+	assert.ErrorIs(t, breaker.Check(ctx), cache.ErrCacheUnavailable)
+
+	assert.NoError(t, redisServer.StartAddr(addr))
+	assert.Eventually(t, func() bool {
+		return breaker.Check(ctx) == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func Test_BreakerCustomerCache_OverflowKeepsForeignKeys(t *testing.T) {
+	ctx := context.Background()
+	breaker, redisServer := newBreakerCache(t)
+	customer := *test.ACustomer
+
+	assert.NoError(t, redisServer.Set("order-id:1", "order"))
+	assert.NoError(t, breaker.WriteCacheByID(ctx, test.CustomerID, &customer, nil))
+	assert.NoError(t, breaker.WriteCacheByEmail(ctx, customer.Email, &customer, nil))
+
+	redisServer.Close()
+	for i := 0; i < aBreakerConfig.Threshold; i++ {
+		_, err := breaker.ReadCacheByID(ctx, test.CustomerID)
+		assert.Error(t, err)
+	}
+	assert.ErrorIs(t, breaker.Check(ctx), cache.ErrCacheUnavailable)
+
+	// more mutations than the breaker remembers, the replay has to drop every customer entry
+	for i := 0; i <= 10000; i++ {
+		mutated := customer
+		mutated.Email = fmt.Sprintf("customer-%d@mail.com", i)
+		assert.ErrorIs(t, breaker.EvictCache(ctx, mutated), cache.ErrCacheUnavailable)
+	}
+
+	assert.NoError(t, redisServer.Restart())
+	assert.Eventually(t, func() bool {
+		return breaker.Check(ctx) == nil
+	}, time.Second, 5*time.Millisecond)
+
+	assert.False(t, redisServer.Exists("customer-id:"+test.CustomerID))
+	assert.False(t, redisServer.Exists("customer-email:"+customer.Email))
+	order, err := redisServer.Get("order-id:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "order", order)
+}
+
+func Test_BreakerCustomerCache_RetriesEvictionFailedWhileClosed(t *testing.T) {
+	ctx := context.Background()
+	breaker, redisServer := newBreakerCache(t)
+	customer := *test.ACustomer
+	otherID := "otherID"
+	other := customer
+	other.ID = &otherID
+	other.Email = "other@mail.com"
+
+	assert.NoError(t, breaker.WriteCacheByID(ctx, test.CustomerID, &customer, nil))
+
+	// a single failure doesn't trip the breaker, but the eviction isn't lost
+	redisServer.SetError("LOADING")
+	assert.Error(t, breaker.EvictCache(ctx, customer))
+	redisServer.SetError("")
+	assert.NoError(t, breaker.Check(ctx))
+	assert.True(t, redisServer.Exists("customer-id:"+test.CustomerID))
+
+	// the next eviction that succeeds retries it
+	assert.NoError(t, breaker.EvictCache(ctx, other))
+	_, err := breaker.ReadCacheByID(ctx, test.CustomerID)
+	assert.ErrorIs(t, err, redis.Nil)
+}
+
+func Test_BreakerCustomerCache_ReplayDoesntHoldTheLock(t *testing.T) {
+	ctx := context.Background()
+	redisServer := miniredis.RunT(t)
+	addr := redisServer.Addr()
+	redisServer.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+	next := mocks.NewCustomerCache(t)
+	breaker := cache.NewBreakerCustomerCache(logger, client, next, aBreakerConfig)
+
+	customer := *test.ACustomer
+	otherID := "otherID"
+	other := customer
+	other.ID = &otherID
+	assert.ErrorIs(t, breaker.EvictCache(ctx, customer), cache.ErrCacheUnavailable)
+
+	evicting := make(chan struct{})
+	release := make(chan struct{})
+	next.On("EvictCache", mock.Anything, customer).Run(func(args mock.Arguments) {
+		close(evicting)
+		<-release
+	}).Return(nil).Once()
+	next.On("EvictCache", mock.Anything, other).Return(nil).Once()
+	next.On("EvictCacheList", mock.Anything).Return(nil)
+
+	assert.NoError(t, redisServer.StartAddr(addr))
+	<-evicting
+
+	// while the replay waits on Redis the breaker still answers, and mutations are pended for it
+	done := make(chan struct{})
+	go func() {
+		assert.ErrorIs(t, breaker.Check(ctx), cache.ErrCacheUnavailable)
+		assert.ErrorIs(t, breaker.EvictCache(ctx, other), cache.ErrCacheUnavailable)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("breaker blocked during the replay")
+	}
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return breaker.Check(ctx) == nil
+	}, time.Second, 5*time.Millisecond)
+}
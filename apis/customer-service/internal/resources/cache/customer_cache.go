@@ -2,161 +2,499 @@ package cache
 
 import (
 	"cmd/customer-service/internal/domain/entity"
+	"cmd/customer-service/internal/metrics"
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"os"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// tombstone is written over evicted keys so a concurrent cache-aside fill
+// holding data read before the mutation can't repopulate them
+const tombstone = "evicted"
+
+// replaceScript swaps a cached entry only if it still holds the value that was revalidated
+var replaceScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return false
+`)
+
+// refreshScript sets each key of KEYS[2:] to its entry and expiration in ARGV, after the ID and version
+// of the customer in ARGV[1] and ARGV[2]. A key holding that customer at the same or a later version
+// is kept, tombstones, negative entries and other customers are overwritten. KEYS[1] is the list
+// generation, moved on like every mutation does.
+var refreshScript = redis.NewScript(`
+for i = 2, #KEYS do
+	local keep = false
+	local current = redis.call("GET", KEYS[i])
+	if current then
+		local ok, cached = pcall(cjson.decode, current)
+		if ok and type(cached) == "table" and type(cached.customer) == "table" and cached.customer.customer_id == ARGV[1] then
+			local version = tonumber(cached.customer.version)
+			keep = version ~= nil and version >= tonumber(ARGV[2])
+		end
+	end
+	if not keep then
+		redis.call("SET", KEYS[i], ARGV[2 * i - 1], "PX", ARGV[2 * i])
+	end
+end
+return redis.call("INCR", KEYS[1])
+`)
+
+// CachedCustomer is the value stored for every key family. NotFound entries are
+// negative cache entries, and entries past FreshUntil are served while revalidated.
+type CachedCustomer struct {
+	Customer   *entity.Customer `json:"customer,omitempty"`
+	NotFound   bool             `json:"not_found,omitempty"`
+	FreshUntil time.Time        `json:"fresh_until"`
+
+	raw string
+}
+
+func (c *CachedCustomer) Stale() bool {
+	return !c.NotFound && time.Now().After(c.FreshUntil)
+}
+
 type CustomerCache interface {
-	ReadCacheByID(ctx context.Context, customerID string) (*entity.Customer, error)
-	WriteCacheByID(ctx context.Context, customer entity.Customer) error
-	ReadCacheByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error)
-	WriteCacheByEmail(ctx context.Context, customer entity.Customer) error
-	ReadCacheByName(ctx context.Context, customerName string) (*entity.Customer, error)
-	WriteCacheByName(ctx context.Context, customer entity.Customer) error
+	ReadCacheByID(ctx context.Context, customerID string) (*CachedCustomer, error)
+	WriteCacheByID(ctx context.Context, customerID string, customer *entity.Customer, stale *CachedCustomer) error
+	ReadCacheByEmail(ctx context.Context, customerEmail string) (*CachedCustomer, error)
+	WriteCacheByEmail(ctx context.Context, customerEmail string, customer *entity.Customer, stale *CachedCustomer) error
+	ReadCacheByDocument(ctx context.Context, cpf string) (*CachedCustomer, error)
+	WriteCacheByDocument(ctx context.Context, cpf string, customer *entity.Customer, stale *CachedCustomer) error
+	ReadCacheByName(ctx context.Context, customerName string) (*CachedCustomer, error)
+	WriteCacheByName(ctx context.Context, customerName string, customer *entity.Customer, stale *CachedCustomer) error
+	ReadCacheList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error)
+	WriteCacheList(ctx context.Context, filter entity.CustomerFilter, page entity.CustomerPage) error
+	RefreshCache(ctx context.Context, customer entity.Customer) error
+	EvictCache(ctx context.Context, customer entity.Customer) error
+	EvictCacheBatch(ctx context.Context, customers []entity.Customer) error
+	EvictCacheList(ctx context.Context) error
+}
+
+type CustomerCacheTTL struct {
+	ID        time.Duration
+	Email     time.Duration
+	Document  time.Duration
+	Name      time.Duration
+	List      time.Duration
+	Stale     time.Duration
+	Negative  time.Duration
+	Tombstone time.Duration
 }
 
 type customerCache struct {
-	logger   slog.Logger
-	client   *redis.Client
-	idKey    string
-	emailKey string
-	nameKey  string
+	logger     slog.Logger
+	metrics    *metrics.CustomerMetrics
+	client     *redis.Client
+	ttl        CustomerCacheTTL
+	idKey      string
+	emailKey   string
+	docKey     string
+	nameKey    string
+	listKey    string
+	listGenKey string
+	documents  DocumentHasher
 }
 
-func NewCustomerCache(l slog.Logger, client *redis.Client) CustomerCache {
+func NewCustomerCache(l slog.Logger, m *metrics.CustomerMetrics, client *redis.Client, ttl CustomerCacheTTL, h DocumentHasher) CustomerCache {
 	return &customerCache{
-		logger:   *l.With("layer", "customer-cache"),
-		client:   client,
-		idKey:    "customer-id:",
-		emailKey: "customer-email:",
-		nameKey:  "customer-name:",
+		logger:     *l.With("layer", "customer-cache"),
+		metrics:    m,
+		client:     client,
+		ttl:        ttl,
+		documents:  h,
+		idKey:      "customer-id:",
+		emailKey:   "customer-email:",
+		docKey:     "customer-document:",
+		nameKey:    "customer-name:",
+		listKey:    "customer-list:",
+		listGenKey: "customer-list-gen",
 	}
 }
 
-func (c *customerCache) ReadCacheByID(ctx context.Context, customerID string) (*entity.Customer, error) {
+func GetCustomerCacheTTL() (*CustomerCacheTTL, error) {
+	idTTL, err := time.ParseDuration(os.Getenv("CACHE_TTL_ID"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_TTL_ID from .env: %s", err)
+	}
+
+	emailTTL, err := time.ParseDuration(os.Getenv("CACHE_TTL_EMAIL"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_TTL_EMAIL from .env: %s", err)
+	}
+
+	documentTTL, err := time.ParseDuration(os.Getenv("CACHE_TTL_DOCUMENT"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_TTL_DOCUMENT from .env: %s", err)
+	}
+
+	nameTTL, err := time.ParseDuration(os.Getenv("CACHE_TTL_NAME"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_TTL_NAME from .env: %s", err)
+	}
+
+	listTTL, err := time.ParseDuration(os.Getenv("CACHE_TTL_LIST"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_TTL_LIST from .env: %s", err)
+	}
+
+	staleTTL, err := time.ParseDuration(os.Getenv("CACHE_TTL_STALE"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_TTL_STALE from .env: %s", err)
+	}
+
+	negativeTTL, err := time.ParseDuration(os.Getenv("CACHE_TTL_NEGATIVE"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_TTL_NEGATIVE from .env: %s", err)
+	}
+
+	tombstoneTTL, err := time.ParseDuration(os.Getenv("CACHE_TTL_TOMBSTONE"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_TTL_TOMBSTONE from .env: %s", err)
+	}
+
+	return &CustomerCacheTTL{
+		ID:        idTTL,
+		Email:     emailTTL,
+		Document:  documentTTL,
+		Name:      nameTTL,
+		List:      listTTL,
+		Stale:     staleTTL,
+		Negative:  negativeTTL,
+		Tombstone: tombstoneTTL,
+	}, nil
+}
+
+func (c *customerCache) ReadCacheByID(ctx context.Context, customerID string) (*CachedCustomer, error) {
 	c.logger.Debug("Getting customer cache", "customerID", customerID, "traceID", ctx.Value("traceID"))
-	key := c.idKey + customerID
+	return c.read(ctx, "id", c.idKey+customerID)
+}
 
-	data, err := c.client.Get(ctx, key).Result()
+func (c *customerCache) WriteCacheByID(ctx context.Context, customerID string, customer *entity.Customer, stale *CachedCustomer) error {
+	c.logger.Debug("Creating customer cache", "customerID", customerID, "traceID", ctx.Value("traceID"))
+	return c.write(ctx, "id", c.idKey+customerID, customer, stale, c.ttl.ID)
+}
+
+func (c *customerCache) ReadCacheByEmail(ctx context.Context, customerEmail string) (*CachedCustomer, error) {
+	c.logger.Debug("Getting customer cache by email", "customerEmail", customerEmail, "traceID", ctx.Value("traceID"))
+	return c.read(ctx, "email", c.emailKey+customerEmail)
+}
+
+func (c *customerCache) WriteCacheByEmail(ctx context.Context, customerEmail string, customer *entity.Customer, stale *CachedCustomer) error {
+	c.logger.Debug("Creating customer cache by email", "customerEmail", customerEmail, "traceID", ctx.Value("traceID"))
+	return c.write(ctx, "email", c.emailKey+customerEmail, customer, stale, c.ttl.Email)
+}
+
+// The document keys hold a hash of the CPF, so it never shows up in keys and logs
+func (c *customerCache) ReadCacheByDocument(ctx context.Context, cpf string) (*CachedCustomer, error) {
+	c.logger.Debug("Getting customer cache by document", "traceID", ctx.Value("traceID"))
+	return c.read(ctx, "document", c.docKey+c.documents.Hash(cpf))
+}
+
+func (c *customerCache) WriteCacheByDocument(ctx context.Context, cpf string, customer *entity.Customer, stale *CachedCustomer) error {
+	c.logger.Debug("Creating customer cache by document", "traceID", ctx.Value("traceID"))
+	return c.write(ctx, "document", c.docKey+c.documents.Hash(cpf), customer, stale, c.ttl.Document)
+}
+
+func (c *customerCache) ReadCacheByName(ctx context.Context, customerName string) (*CachedCustomer, error) {
+	c.logger.Debug("Getting customer cache by name", "customerName", customerName, "traceID", ctx.Value("traceID"))
+	return c.read(ctx, "name", c.nameKey+customerName)
+}
+
+func (c *customerCache) WriteCacheByName(ctx context.Context, customerName string, customer *entity.Customer, stale *CachedCustomer) error {
+	c.logger.Debug("Creating customer cache by name", "customerName", customerName, "traceID", ctx.Value("traceID"))
+	return c.write(ctx, "name", c.nameKey+customerName, customer, stale, c.ttl.Name)
+}
+
+func (c *customerCache) ReadCacheList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error) {
+	c.logger.Debug("Getting customer list cache", "filter", filter, "traceID", ctx.Value("traceID"))
+	key, err := c.listCacheKey(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.get(ctx, key)
 	if err != nil {
 		if err == redis.Nil {
-			c.logger.Error("Customer isn't cached", "error", err, "traceID", ctx.Value("traceID"))
+			c.metrics.IncCacheRequest("redis", "list", "miss")
+			c.logger.Debug("Customer list isn't cached", "traceID", ctx.Value("traceID"))
 			return nil, err
 		}
+		c.metrics.IncCacheRequest("redis", "list", "error")
+		c.logger.Error("Failed to get customer list cache", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
-	c.logger.Debug("Got customer cache", "data", data, "traceID", ctx.Value("traceID"))
 
-	var customer entity.Customer
-	err = json.Unmarshal([]byte(data), &customer)
+	var page entity.CustomerPage
+	err = json.Unmarshal([]byte(data), &page)
 	if err != nil {
-		c.logger.Error("Failed to unMarshal cached customer", "error", err, "traceID", ctx.Value("traceID"))
+		c.metrics.IncCacheRequest("redis", "list", "error")
+		c.logger.Error("Failed to unMarshal cached customer list", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
+	c.metrics.IncCacheRequest("redis", "list", "hit")
 
-	return &customer, nil
+	return &page, nil
 }
 
-func (c *customerCache) WriteCacheByID(ctx context.Context, customer entity.Customer) error {
-	c.logger.Debug("Creating customer cache", "data", customer, "traceID", ctx.Value("traceID"))
-	key := c.idKey + *customer.ID
+func (c *customerCache) WriteCacheList(ctx context.Context, filter entity.CustomerFilter, page entity.CustomerPage) error {
+	c.logger.Debug("Creating customer list cache", "filter", filter, "traceID", ctx.Value("traceID"))
+	key, err := c.listCacheKey(ctx, filter)
+	if err != nil {
+		return err
+	}
 
-	data, err := json.Marshal(customer)
+	data, err := json.Marshal(page)
 	if err != nil {
-		c.logger.Error("Failed to marshal customer", "error", err, "traceID", ctx.Value("traceID"))
+		c.logger.Error("Failed to marshal customer list", "error", err, "traceID", ctx.Value("traceID"))
 		return err
 	}
 
-	err = c.client.Set(ctx, key, data, 0).Err() // 0 means no expiration
+	start := time.Now()
+	err = c.client.SetNX(ctx, key, data, c.ttl.List).Err()
+	c.metrics.MeasureExternalDuration(start, "cache", "CustomerCache", "SET", "")
 	if err != nil {
-		c.logger.Error("Failed to create customer cache", "error", err, "traceID", ctx.Value("traceID"))
+		c.logger.Error("Failed to create customer list cache", "error", err, "traceID", ctx.Value("traceID"))
 		return err
 	}
 
 	return nil
 }
 
-func (c *customerCache) ReadCacheByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error) {
-	c.logger.Debug("Getting customer cache by email", "customerEmail", customerEmail, "traceID", ctx.Value("traceID"))
-	key := c.emailKey + customerEmail
+// RefreshCache overwrites every key family with the customer's current data, unless a key already
+// holds the same customer at a version as new. Two mutations refreshing in the opposite order of
+// their re-reads would otherwise leave the older version cached for a whole TTL.
+func (c *customerCache) RefreshCache(ctx context.Context, customer entity.Customer) error {
+	c.logger.Debug("Refreshing customer cache", "customerID", customer.ID, "traceID", ctx.Value("traceID"))
 
-	data, err := c.client.Get(ctx, key).Result()
+	keys := map[string]time.Duration{
+		c.idKey + *customer.ID:      c.ttl.ID,
+		c.emailKey + customer.Email: c.ttl.Email,
+		c.nameKey + customer.Name:   c.ttl.Name,
+	}
+	if customer.CPF != nil {
+		keys[c.docKey+c.documents.Hash(*customer.CPF)] = c.ttl.Document
+	}
+	scriptKeys := []string{c.listGenKey}
+	args := []interface{}{*customer.ID, customer.Version}
+	for key, ttl := range keys {
+		data, expiration, err := c.encode(&customer, ttl)
+		if err != nil {
+			c.logger.Error("Failed to marshal customer", "error", err, "traceID", ctx.Value("traceID"))
+			return err
+		}
+		scriptKeys = append(scriptKeys, key)
+		args = append(args, data, expiration.Milliseconds())
+	}
+
+	start := time.Now()
+	err := refreshScript.Run(ctx, c.client, scriptKeys, args...).Err()
+	c.metrics.MeasureExternalDuration(start, "cache", "CustomerCache", "SET", "")
 	if err != nil {
-		if err == redis.Nil {
-			c.logger.Error("Customer isn't cached", "error", err, "traceID", ctx.Value("traceID"))
-			return nil, err
+		c.logger.Error("Failed to refresh customer cache", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
+
+// EvictCache replaces every key family of the customer with a short-lived tombstone
+func (c *customerCache) EvictCache(ctx context.Context, customer entity.Customer) error {
+	c.logger.Debug("Evicting customer cache", "customerID", customer.ID, "traceID", ctx.Value("traceID"))
+
+	start := time.Now()
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.idKey+*customer.ID, tombstone, c.ttl.Tombstone)
+		pipe.Set(ctx, c.emailKey+customer.Email, tombstone, c.ttl.Tombstone)
+		pipe.Set(ctx, c.nameKey+customer.Name, tombstone, c.ttl.Tombstone)
+		if customer.CPF != nil {
+			pipe.Set(ctx, c.docKey+c.documents.Hash(*customer.CPF), tombstone, c.ttl.Tombstone)
 		}
-		return nil, err
+		pipe.Incr(ctx, c.listGenKey)
+		return nil
+	})
+	c.metrics.MeasureExternalDuration(start, "cache", "CustomerCache", "SET", "")
+	if err != nil {
+		c.logger.Error("Failed to evict customer cache", "error", err, "traceID", ctx.Value("traceID"))
+		return err
 	}
-	c.logger.Debug("Got customer cache", "data", data, "traceID", ctx.Value("traceID"))
 
-	var customer entity.Customer
-	err = json.Unmarshal([]byte(data), &customer)
+	return nil
+}
+
+// EvictCacheBatch is EvictCache for many customers at once, like an import. The tombstones are
+// pipelined and the list generation is moved on once for the whole batch.
+func (c *customerCache) EvictCacheBatch(ctx context.Context, customers []entity.Customer) error {
+	c.logger.Debug("Evicting customer cache batch", "customers", len(customers), "traceID", ctx.Value("traceID"))
+
+	start := time.Now()
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, customer := range customers {
+			if customer.ID != nil {
+				pipe.Set(ctx, c.idKey+*customer.ID, tombstone, c.ttl.Tombstone)
+			}
+			pipe.Set(ctx, c.emailKey+customer.Email, tombstone, c.ttl.Tombstone)
+			pipe.Set(ctx, c.nameKey+customer.Name, tombstone, c.ttl.Tombstone)
+			if customer.CPF != nil {
+				pipe.Set(ctx, c.docKey+c.documents.Hash(*customer.CPF), tombstone, c.ttl.Tombstone)
+			}
+		}
+		pipe.Incr(ctx, c.listGenKey)
+		return nil
+	})
+	c.metrics.MeasureExternalDuration(start, "cache", "CustomerCache", "SET", "")
 	if err != nil {
-		c.logger.Error("Failed to unMarshal cached customer", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		c.logger.Error("Failed to evict customer cache batch", "error", err, "traceID", ctx.Value("traceID"))
+		return err
 	}
 
-	return &customer, nil
+	return nil
 }
 
-func (c *customerCache) WriteCacheByEmail(ctx context.Context, customer entity.Customer) error {
-	c.logger.Debug("Creating customer cache by email", "data", customer, "traceID", ctx.Value("traceID"))
-	key := c.emailKey + customer.Email
+// EvictCacheList drops every cached list page by moving to a new list generation
+func (c *customerCache) EvictCacheList(ctx context.Context) error {
+	c.logger.Debug("Evicting customer list cache", "traceID", ctx.Value("traceID"))
 
-	data, err := json.Marshal(customer)
+	err := c.client.Incr(ctx, c.listGenKey).Err()
 	if err != nil {
-		c.logger.Error("Failed to marshal customer", "error", err, "traceID", ctx.Value("traceID"))
+		c.logger.Error("Failed to evict customer list cache", "error", err, "traceID", ctx.Value("traceID"))
 		return err
 	}
 
-	err = c.client.Set(ctx, key, data, 0).Err() // 0 means no expiration
+	return nil
+}
+
+// evictAll drops every customer key and moves to a new list generation. The Redis DB may be
+// shared with other services, so only the customer key families are unlinked
+func (c *customerCache) evictAll(ctx context.Context) error {
+	c.logger.Debug("Evicting every customer cache entry", "traceID", ctx.Value("traceID"))
+
+	for _, prefix := range []string{c.idKey, c.emailKey, c.docKey, c.nameKey, c.listKey} {
+		err := c.unlinkMatching(ctx, prefix+"*")
+		if err != nil {
+			c.logger.Error("Failed to evict customer cache entries", "error", err, "prefix", prefix, "traceID", ctx.Value("traceID"))
+			return err
+		}
+	}
+
+	return c.EvictCacheList(ctx)
+}
+
+func (c *customerCache) unlinkMatching(ctx context.Context, pattern string) error {
+	const batch = 500
+
+	keys := make([]string, 0, batch)
+	iter := c.client.Scan(ctx, 0, pattern, batch).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) < batch {
+			continue
+		}
+		err := c.client.Unlink(ctx, keys...).Err()
+		if err != nil {
+			return err
+		}
+		keys = keys[:0]
+	}
+	err := iter.Err()
 	if err != nil {
-		c.logger.Error("Failed to create customer cache", "error", err, "traceID", ctx.Value("traceID"))
 		return err
 	}
 
+	if len(keys) > 0 {
+		return c.client.Unlink(ctx, keys...).Err()
+	}
 	return nil
 }
 
-func (c *customerCache) ReadCacheByName(ctx context.Context, customerName string) (*entity.Customer, error) {
-	c.logger.Debug("Getting customer cache by name", "customerName", customerName, "traceID", ctx.Value("traceID"))
-	key := c.nameKey + customerName
+func (c *customerCache) listCacheKey(ctx context.Context, filter entity.CustomerFilter) (string, error) {
+	generation, err := c.get(ctx, c.listGenKey)
+	if err != nil && err != redis.Nil {
+		c.logger.Error("Failed to get customer list generation", "error", err, "traceID", ctx.Value("traceID"))
+		return "", err
+	}
 
+	data, err := json.Marshal(filter)
+	if err != nil {
+		c.logger.Error("Failed to marshal customer list filter", "error", err, "traceID", ctx.Value("traceID"))
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s:%x", c.listKey, generation, sha1.Sum(data)), nil
+}
+
+func (c *customerCache) get(ctx context.Context, key string) (string, error) {
+	start := time.Now()
 	data, err := c.client.Get(ctx, key).Result()
+	c.metrics.MeasureExternalDuration(start, "cache", "CustomerCache", "GET", "")
+	return data, err
+}
+
+func (c *customerCache) read(ctx context.Context, family string, key string) (*CachedCustomer, error) {
+	data, err := c.get(ctx, key)
 	if err != nil {
 		if err == redis.Nil {
-			c.logger.Error("Customer isn't cached", "error", err, "traceID", ctx.Value("traceID"))
+			c.metrics.IncCacheRequest("redis", family, "miss")
+			c.logger.Debug("Customer isn't cached", "family", family, "traceID", ctx.Value("traceID"))
 			return nil, err
 		}
+		c.metrics.IncCacheRequest("redis", family, "error")
+		c.logger.Error("Failed to get customer cache", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
-	c.logger.Debug("Got customer cache", "data", data, "traceID", ctx.Value("traceID"))
 
-	var customer entity.Customer
-	err = json.Unmarshal([]byte(data), &customer)
+	if data == tombstone {
+		c.metrics.IncCacheRequest("redis", family, "miss")
+		c.logger.Debug("Customer cache was evicted", "family", family, "traceID", ctx.Value("traceID"))
+		return nil, redis.Nil
+	}
+	c.logger.Debug("Got customer cache", "family", family, "traceID", ctx.Value("traceID"))
+
+	var cached CachedCustomer
+	err = json.Unmarshal([]byte(data), &cached)
 	if err != nil {
+		c.metrics.IncCacheRequest("redis", family, "error")
 		c.logger.Error("Failed to unMarshal cached customer", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
+	cached.raw = data
+	c.metrics.IncCacheRequest("redis", family, "hit")
 
-	return &customer, nil
+	return &cached, nil
 }
 
-func (c *customerCache) WriteCacheByName(ctx context.Context, customer entity.Customer) error {
-	c.logger.Debug("Creating customer cache by name", "data", customer, "traceID", ctx.Value("traceID"))
-	key := c.emailKey + customer.Email
-
-	data, err := json.Marshal(customer)
+// write fills absent keys only, so it never overwrites a refresh or a tombstone written by a
+// mutation that happened after the customer was read from the DB. When revalidating a stale
+// entry, it replaces the key only if it still holds that same stale entry.
+func (c *customerCache) write(ctx context.Context, family string, key string, customer *entity.Customer, stale *CachedCustomer, ttl time.Duration) error {
+	data, expiration, err := c.encode(customer, ttl)
 	if err != nil {
 		c.logger.Error("Failed to marshal customer", "error", err, "traceID", ctx.Value("traceID"))
 		return err
 	}
 
-	err = c.client.Set(ctx, key, data, 0).Err() // 0 means no expiration
+	start := time.Now()
+	defer c.metrics.MeasureExternalDuration(start, "cache", "CustomerCache", "SET", "")
+	if stale != nil {
+		err = replaceScript.Run(ctx, c.client, []string{key}, stale.raw, data, expiration.Milliseconds()).Err()
+		if err == redis.Nil {
+			c.logger.Debug("Customer cache changed while revalidating", "family", family, "traceID", ctx.Value("traceID"))
+			return nil
+		}
+	} else {
+		err = c.client.SetNX(ctx, key, data, expiration).Err()
+	}
 	if err != nil {
 		c.logger.Error("Failed to create customer cache", "error", err, "traceID", ctx.Value("traceID"))
 		return err
@@ -164,3 +502,43 @@ func (c *customerCache) WriteCacheByName(ctx context.Context, customer entity.Cu
 
 	return nil
 }
+
+// encode builds the cached entry and its expiration. A nil customer is cached as not found.
+func (c *customerCache) encode(customer *entity.Customer, ttl time.Duration) ([]byte, time.Duration, error) {
+	cached := CachedCustomer{Customer: customer, FreshUntil: time.Now().Add(ttl)}
+	expiration := ttl + c.ttl.Stale
+	if customer == nil {
+		cached.NotFound = true
+		cached.FreshUntil = time.Now().Add(c.ttl.Negative)
+		expiration = c.ttl.Negative
+	}
+
+	data, err := json.Marshal(cached)
+	return data, expiration, err
+}
+
+// DocumentHasher keys the customers' documents in the cache and in the invalidations. There are
+// few enough CPFs to brute-force a plain hash, so it's keyed with a secret of the deployment.
+type DocumentHasher struct {
+	secret []byte
+}
+
+func NewDocumentHasher(secret string) DocumentHasher {
+	return DocumentHasher{secret: []byte(secret)}
+}
+
+func GetDocumentHasher() (*DocumentHasher, error) {
+	secret := os.Getenv("CACHE_DOCUMENT_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("Failed to get CACHE_DOCUMENT_SECRET from .env: it's empty")
+	}
+
+	hasher := NewDocumentHasher(secret)
+	return &hasher, nil
+}
+
+func (h DocumentHasher) Hash(cpf string) string {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(cpf))
+	return hex.EncodeToString(mac.Sum(nil))
+}
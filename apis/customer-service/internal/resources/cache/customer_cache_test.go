@@ -0,0 +1,82 @@
+package cache_test
+
+import (
+	"cmd/customer-service/internal/metrics"
+	"cmd/customer-service/internal/resources/cache"
+	"cmd/customer-service/test"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CustomerCache_Metrics(t *testing.T) {
+	ctx := context.Background()
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr(), MaxRetries: -1})
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+	reg := prometheus.NewRegistry()
+	m := metrics.NewCustomerMetrics(logger, reg)
+	customerCache := cache.NewCustomerCache(logger, m, client, aCacheTTL, aDocumentHasher)
+
+	customer := *test.ACustomer
+	_, err := customerCache.ReadCacheByEmail(ctx, customer.Email)
+	assert.ErrorIs(t, err, redis.Nil)
+	assert.NoError(t, customerCache.WriteCacheByEmail(ctx, customer.Email, &customer, nil))
+	_, err = customerCache.ReadCacheByEmail(ctx, customer.Email)
+	assert.NoError(t, err)
+
+	redisServer.Close()
+	_, err = customerCache.ReadCacheByName(ctx, customer.Name)
+	assert.Error(t, err)
+
+	requests := func(family string, result string) float64 {
+		return testutil.ToFloat64(m.CacheRequests.WithLabelValues("of-customer-service", "redis", family, result))
+	}
+	assert.Equal(t, 1.0, requests("email", "miss"))
+	assert.Equal(t, 1.0, requests("email", "hit"))
+	assert.Equal(t, 1.0, requests("name", "error"))
+	assert.Equal(t, 0.0, requests("id", "hit"))
+
+	// GET and SET latencies are exposed next to the DB ones
+	count, err := testutil.GatherAndCount(reg, "external_request_duration_seconds")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func Test_DocumentHasher(t *testing.T) {
+	cpf := "52998224725"
+	plain := sha256.Sum256([]byte(cpf))
+
+	hash := aDocumentHasher.Hash(cpf)
+	assert.Equal(t, hash, aDocumentHasher.Hash(cpf))
+	assert.NotEqual(t, hex.EncodeToString(plain[:]), hash)
+	// without the secret of the deployment the keys can't be matched to CPFs
+	assert.NotEqual(t, hash, cache.NewDocumentHasher("another-secret").Hash(cpf))
+}
+
+func Test_CustomerCache_WriteCacheByDocument_KeysWithTheSecret(t *testing.T) {
+	ctx := context.Background()
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr(), MaxRetries: -1})
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+	customerCache := cache.NewCustomerCache(logger, metrics.NewCustomerMetrics(logger, prometheus.NewRegistry()), client, aCacheTTL, aDocumentHasher)
+
+	customer := *test.ACustomer
+	cpf := "52998224725"
+	customer.CPF = &cpf
+	assert.NoError(t, customerCache.WriteCacheByDocument(ctx, cpf, &customer, nil))
+
+	assert.True(t, redisServer.Exists("customer-document:"+aDocumentHasher.Hash(cpf)))
+	cached, err := customerCache.ReadCacheByDocument(ctx, cpf)
+	assert.NoError(t, err)
+	assert.Equal(t, customer.Email, cached.Customer.Email)
+}
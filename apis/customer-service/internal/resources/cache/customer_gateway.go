@@ -0,0 +1,416 @@
+package cache
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"cmd/customer-service/internal/domain/gateway"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type CachedReadPaths struct {
+	ByID       bool
+	ByEmail    bool
+	ByDocument bool
+	ByName     bool
+	List       bool
+}
+
+type (
+	cacheReader    func(ctx context.Context) (*CachedCustomer, error)
+	customerLoader func(ctx context.Context) (*entity.Customer, error)
+	cacheWriter    func(ctx context.Context, customer *entity.Customer, stale *CachedCustomer) error
+)
+
+type cachedCustomerGateway struct {
+	logger      slog.Logger
+	customerGtw gateway.CustomerGateway
+	cache       CustomerCache
+	readPaths   CachedReadPaths
+	documents   DocumentHasher
+	group       *singleflight.Group
+}
+
+// NewCachedCustomerGateway wraps a CustomerGateway with cache-aside reads for the
+// configured read paths and keeps the cache consistent on every mutation
+func NewCachedCustomerGateway(l slog.Logger, g gateway.CustomerGateway, c CustomerCache, p CachedReadPaths, h DocumentHasher) gateway.CustomerGateway {
+	return &cachedCustomerGateway{
+		logger:      *l.With("layer", "customer-cached-gateway"),
+		customerGtw: g,
+		cache:       c,
+		readPaths:   p,
+		documents:   h,
+		group:       &singleflight.Group{},
+	}
+}
+
+func GetCachedReadPaths() (*CachedReadPaths, error) {
+	paths := &CachedReadPaths{}
+	for _, path := range strings.Split(os.Getenv("CACHE_READ_PATHS"), ",") {
+		switch strings.TrimSpace(path) {
+		case "":
+		case "id":
+			paths.ByID = true
+		case "email":
+			paths.ByEmail = true
+		case "document":
+			paths.ByDocument = true
+		case "name":
+			paths.ByName = true
+		case "list":
+			paths.List = true
+		default:
+			return nil, fmt.Errorf("Failed to get CACHE_READ_PATHS from .env: unknown read path %s", path)
+		}
+	}
+
+	return paths, nil
+}
+
+func (g *cachedCustomerGateway) GetCustomerList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error) {
+	if !g.readPaths.List {
+		return g.customerGtw.GetCustomerList(ctx, filter)
+	}
+
+	page, err := g.cache.ReadCacheList(ctx, filter)
+	if err == nil {
+		return page, nil
+	}
+
+	key, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err, _ := g.group.Do("list:"+string(key), func() (interface{}, error) {
+		ctx := context.WithoutCancel(ctx)
+		page, err := g.customerGtw.GetCustomerList(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		g.cache.WriteCacheList(ctx, filter, *page)
+		return page, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*entity.CustomerPage), nil
+}
+
+func (g *cachedCustomerGateway) GetCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error) {
+	if !g.readPaths.ByID {
+		return g.customerGtw.GetCustomerByID(ctx, customerID)
+	}
+
+	return g.cacheAside(ctx, "id:"+customerID,
+		func(ctx context.Context) (*CachedCustomer, error) {
+			return g.cache.ReadCacheByID(ctx, customerID)
+		},
+		func(ctx context.Context) (*entity.Customer, error) {
+			return g.customerGtw.GetCustomerByID(ctx, customerID)
+		},
+		func(ctx context.Context, customer *entity.Customer, stale *CachedCustomer) error {
+			return g.cache.WriteCacheByID(ctx, customerID, customer, stale)
+		})
+}
+
+func (g *cachedCustomerGateway) GetCustomerByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error) {
+	if !g.readPaths.ByEmail {
+		return g.customerGtw.GetCustomerByEmail(ctx, customerEmail)
+	}
+
+	return g.cacheAside(ctx, "email:"+customerEmail,
+		func(ctx context.Context) (*CachedCustomer, error) {
+			return g.cache.ReadCacheByEmail(ctx, customerEmail)
+		},
+		func(ctx context.Context) (*entity.Customer, error) {
+			return g.customerGtw.GetCustomerByEmail(ctx, customerEmail)
+		},
+		func(ctx context.Context, customer *entity.Customer, stale *CachedCustomer) error {
+			return g.cache.WriteCacheByEmail(ctx, customerEmail, customer, stale)
+		})
+}
+
+func (g *cachedCustomerGateway) GetCustomerByDocument(ctx context.Context, cpf string) (*entity.Customer, error) {
+	if !g.readPaths.ByDocument {
+		return g.customerGtw.GetCustomerByDocument(ctx, cpf)
+	}
+
+	return g.cacheAside(ctx, "document:"+g.documents.Hash(cpf),
+		func(ctx context.Context) (*CachedCustomer, error) {
+			return g.cache.ReadCacheByDocument(ctx, cpf)
+		},
+		func(ctx context.Context) (*entity.Customer, error) {
+			return g.customerGtw.GetCustomerByDocument(ctx, cpf)
+		},
+		func(ctx context.Context, customer *entity.Customer, stale *CachedCustomer) error {
+			return g.cache.WriteCacheByDocument(ctx, cpf, customer, stale)
+		})
+}
+
+// GetCustomerByPhone isn't cached, support tooling looks numbers up rarely and the keys would have
+// to be evicted on every phone change
+func (g *cachedCustomerGateway) GetCustomerByPhone(ctx context.Context, number string) (*entity.Customer, error) {
+	return g.customerGtw.GetCustomerByPhone(ctx, number)
+}
+
+func (g *cachedCustomerGateway) GetCustomerByName(ctx context.Context, customerName string) (*entity.Customer, error) {
+	if !g.readPaths.ByName {
+		return g.customerGtw.GetCustomerByName(ctx, customerName)
+	}
+
+	return g.cacheAside(ctx, "name:"+customerName,
+		func(ctx context.Context) (*CachedCustomer, error) {
+			return g.cache.ReadCacheByName(ctx, customerName)
+		},
+		func(ctx context.Context) (*entity.Customer, error) {
+			return g.customerGtw.GetCustomerByName(ctx, customerName)
+		},
+		func(ctx context.Context, customer *entity.Customer, stale *CachedCustomer) error {
+			return g.cache.WriteCacheByName(ctx, customerName, customer, stale)
+		})
+}
+
+// SearchCustomers isn't cached, ranked results are rarely asked twice and can't be invalidated per customer
+func (g *cachedCustomerGateway) SearchCustomers(ctx context.Context, search entity.CustomerSearch) (*entity.CustomerSearchPage, error) {
+	return g.customerGtw.SearchCustomers(ctx, search)
+}
+
+// ExportCustomers streams from the DB, the export would only churn the cache
+func (g *cachedCustomerGateway) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, write func(customer *entity.Customer) error) error {
+	return g.customerGtw.ExportCustomers(ctx, filter, write)
+}
+
+// GetUpcomingBirthdays isn't cached either, it's run by marketing batches and changes with the day
+func (g *cachedCustomerGateway) GetUpcomingBirthdays(ctx context.Context, filter entity.BirthdayFilter) (*entity.BirthdayPage, error) {
+	return g.customerGtw.GetUpcomingBirthdays(ctx, filter)
+}
+
+func (g *cachedCustomerGateway) CreateCustomer(ctx context.Context, customer entity.Customer) (*string, error) {
+	id, err := g.customerGtw.CreateCustomer(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+	// drop negative entries cached for the new customer before it existed
+	g.cache.EvictCache(ctx, entity.Customer{ID: id, Email: customer.Email, Name: customer.Name, CPF: customer.CPF})
+
+	return id, nil
+}
+
+func (g *cachedCustomerGateway) ImportCustomers(ctx context.Context, customers []*entity.Customer, dryRun bool) ([]error, error) {
+	errs, err := g.customerGtw.ImportCustomers(ctx, customers, dryRun)
+	if err != nil || dryRun {
+		return errs, err
+	}
+	// drop negative entries cached for the new customers before they existed, in one round trip
+	imported := make([]entity.Customer, 0, len(customers))
+	for i, customer := range customers {
+		if errs[i] == nil {
+			imported = append(imported, entity.Customer{ID: customer.ID, Email: customer.Email, Name: customer.Name, CPF: customer.CPF})
+		}
+	}
+	if len(imported) > 0 {
+		g.cache.EvictCacheBatch(ctx, imported)
+	}
+
+	return errs, nil
+}
+
+func (g *cachedCustomerGateway) UpdateCustomer(ctx context.Context, customer entity.Customer, ifMatch *int64) error {
+	return g.mutate(ctx, *customer.ID, func() error {
+		return g.customerGtw.UpdateCustomer(ctx, customer, ifMatch)
+	})
+}
+
+func (g *cachedCustomerGateway) PatchCustomer(ctx context.Context, patch entity.CustomerPatch, ifMatch *int64) error {
+	return g.mutate(ctx, patch.ID, func() error {
+		return g.customerGtw.PatchCustomer(ctx, patch, ifMatch)
+	})
+}
+
+func (g *cachedCustomerGateway) DeleteCustomerByID(ctx context.Context, customerID string, ifMatch *int64) error {
+	customer, err := g.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	err = g.customerGtw.DeleteCustomerByID(ctx, customerID, ifMatch)
+	if err != nil {
+		return err
+	}
+	g.cache.EvictCache(ctx, *customer)
+
+	return nil
+}
+
+// RestoreCustomerByID overwrites the not-found entries cached while the customer was deleted
+func (g *cachedCustomerGateway) RestoreCustomerByID(ctx context.Context, customerID string) error {
+	err := g.customerGtw.RestoreCustomerByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	customer, err := g.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		g.logger.Error("Failed to get restored customer to refresh cache", "error", err, "traceID", ctx.Value("traceID"))
+		return nil
+	}
+	g.cache.RefreshCache(ctx, *customer)
+
+	return nil
+}
+
+// EraseCustomerByID evicts the keys of an active customer. A deleted one was already
+// evicted when it was deleted, its PII can't be read anymore to build the keys.
+func (g *cachedCustomerGateway) EraseCustomerByID(ctx context.Context, erasure entity.CustomerErasure) error {
+	customer, err := g.customerGtw.GetCustomerByID(ctx, erasure.CustomerID)
+	if err != nil && !errors.Is(err, entity.ErrCustomerNotFound) {
+		return err
+	}
+
+	err = g.customerGtw.EraseCustomerByID(ctx, erasure)
+	if err != nil {
+		return err
+	}
+	if customer != nil {
+		g.cache.EvictCache(ctx, *customer)
+	}
+
+	return nil
+}
+
+// The address mutations refresh the cached customer, its addresses are part of it
+func (g *cachedCustomerGateway) CreateCustomerAddress(ctx context.Context, address entity.Address) (*string, error) {
+	var id *string
+	err := g.mutate(ctx, address.CustomerID, func() error {
+		var err error
+		id, err = g.customerGtw.CreateCustomerAddress(ctx, address)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+func (g *cachedCustomerGateway) UpdateCustomerAddress(ctx context.Context, address entity.Address) error {
+	return g.mutate(ctx, address.CustomerID, func() error {
+		return g.customerGtw.UpdateCustomerAddress(ctx, address)
+	})
+}
+
+func (g *cachedCustomerGateway) DeleteCustomerAddress(ctx context.Context, customerID string, addressID string) error {
+	return g.mutate(ctx, customerID, func() error {
+		return g.customerGtw.DeleteCustomerAddress(ctx, customerID, addressID)
+	})
+}
+
+// mutate evicts the keys of the customer as it was before the change, since email and name may
+// change, then refreshes the cache with the customer as it is now
+func (g *cachedCustomerGateway) mutate(ctx context.Context, customerID string, change func() error) error {
+	oldCustomer, err := g.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	err = change()
+	if err != nil {
+		return err
+	}
+
+	g.cache.EvictCache(ctx, *oldCustomer)
+	updatedCustomer, err := g.customerGtw.GetCustomerByID(ctx, customerID)
+	if err != nil {
+		g.logger.Error("Failed to get updated customer to refresh cache", "error", err, "traceID", ctx.Value("traceID"))
+		return nil
+	}
+	g.cache.RefreshCache(ctx, *updatedCustomer)
+
+	return nil
+}
+
+// cacheAside serves cached entries (stale ones while a single goroutine revalidates them)
+// and coalesces concurrent misses of the same key into a single DB load
+func (g *cachedCustomerGateway) cacheAside(ctx context.Context, key string, read cacheReader, load customerLoader, write cacheWriter) (*entity.Customer, error) {
+	cached, err := read(ctx)
+	if err == nil {
+		if cached.Stale() {
+			g.revalidate(ctx, key, cached, load, write)
+		}
+		if cached.NotFound {
+			return nil, fmt.Errorf("%w (cached) with %s", entity.ErrCustomerNotFound, key)
+		}
+		return cached.Customer, nil
+	}
+
+	result, err, shared := g.group.Do(key, func() (interface{}, error) {
+		ctx := context.WithoutCancel(ctx)
+		customer, err := load(ctx)
+		if errors.Is(err, entity.ErrCustomerNotFound) {
+			write(ctx, nil, nil)
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		write(ctx, customer, nil)
+		return customer, nil
+	})
+	if shared {
+		g.logger.Debug("Coalesced customer cache miss", "key", key, "traceID", ctx.Value("traceID"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*entity.Customer), nil
+}
+
+func (g *cachedCustomerGateway) revalidate(ctx context.Context, key string, stale *CachedCustomer, load customerLoader, write cacheWriter) {
+	g.logger.Debug("Revalidating stale customer cache", "key", key, "traceID", ctx.Value("traceID"))
+	// the result isn't awaited, DoChan runs the refresh once for all concurrent stale reads
+	g.group.DoChan("revalidate:"+key, func() (interface{}, error) {
+		ctx := context.WithoutCancel(ctx)
+		customer, err := load(ctx)
+		if err != nil && !errors.Is(err, entity.ErrCustomerNotFound) {
+			g.logger.Error("Failed to revalidate customer cache", "key", key, "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		return nil, write(ctx, customer, stale)
+	})
+}
+
+// The phone mutations refresh the cached customer as well
+func (g *cachedCustomerGateway) CreateCustomerPhone(ctx context.Context, phone entity.Phone) (*string, error) {
+	var id *string
+	err := g.mutate(ctx, phone.CustomerID, func() error {
+		var err error
+		id, err = g.customerGtw.CreateCustomerPhone(ctx, phone)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+func (g *cachedCustomerGateway) UpdateCustomerPhone(ctx context.Context, phone entity.Phone) error {
+	return g.mutate(ctx, phone.CustomerID, func() error {
+		return g.customerGtw.UpdateCustomerPhone(ctx, phone)
+	})
+}
+
+func (g *cachedCustomerGateway) DeleteCustomerPhone(ctx context.Context, customerID string, phoneID string) error {
+	return g.mutate(ctx, customerID, func() error {
+		return g.customerGtw.DeleteCustomerPhone(ctx, customerID, phoneID)
+	})
+}
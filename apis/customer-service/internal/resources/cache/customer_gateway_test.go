@@ -0,0 +1,616 @@
+package cache_test
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"cmd/customer-service/internal/domain/gateway"
+	"cmd/customer-service/internal/metrics"
+	"cmd/customer-service/internal/resources/cache"
+	"cmd/customer-service/mocks"
+	"cmd/customer-service/test"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var aCacheTTL = cache.CustomerCacheTTL{
+	ID:        10 * time.Minute,
+	Email:     5 * time.Minute,
+	Document:  5 * time.Minute,
+	Name:      time.Minute,
+	List:      30 * time.Second,
+	Stale:     time.Minute,
+	Negative:  30 * time.Second,
+	Tombstone: 5 * time.Second,
+}
+
+var aDocumentHasher = cache.NewDocumentHasher("document-secret")
+
+var allReadPaths = cache.CachedReadPaths{ByID: true, ByEmail: true, ByDocument: true, ByName: true, List: true}
+
+func newCachedGateway(t *testing.T, paths cache.CachedReadPaths) (gateway.CustomerGateway, *mocks.CustomerGateway, cache.CustomerCache, *miniredis.Miniredis) {
+	return newCachedGatewayWithTTL(t, paths, aCacheTTL)
+}
+
+func newCachedGatewayWithTTL(t *testing.T, paths cache.CachedReadPaths, ttl cache.CustomerCacheTTL) (gateway.CustomerGateway, *mocks.CustomerGateway, cache.CustomerCache, *miniredis.Miniredis) {
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	gtw := new(mocks.CustomerGateway)
+	customerCache := cache.NewCustomerCache(logger, metrics.NewCustomerMetrics(logger, prometheus.NewRegistry()), client, ttl, aDocumentHasher)
+	return cache.NewCachedCustomerGateway(logger, gtw, customerCache, paths, aDocumentHasher), gtw, customerCache, redisServer
+}
+
+func Test_CachedCustomerGtw_ReadsAfterUpdate(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	oldCustomer := *test.ACustomer
+	updatedCustomer := oldCustomer
+	updatedCustomer.Name = "Johnny"
+	updatedCustomer.Email = "johnny.doe@example.com"
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&oldCustomer, nil).Twice()
+	gtw.On("GetCustomerByEmail", mock.Anything, oldCustomer.Email).Return(&oldCustomer, nil).Once()
+	gtw.On("GetCustomerByName", mock.Anything, oldCustomer.Name).Return(&oldCustomer, nil).Once()
+	gtw.On("UpdateCustomer", ctx, updatedCustomer, (*int64)(nil)).Return(nil).Once()
+
+	// warm every key family with the old data
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	_, err = cachedGtw.GetCustomerByEmail(ctx, oldCustomer.Email)
+	assert.NoError(t, err)
+	_, err = cachedGtw.GetCustomerByName(ctx, oldCustomer.Name)
+	assert.NoError(t, err)
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&updatedCustomer, nil)
+	gtw.On("GetCustomerByEmail", mock.Anything, oldCustomer.Email).Return(nil, errors.New("not found"))
+	gtw.On("GetCustomerByName", mock.Anything, oldCustomer.Name).Return(nil, errors.New("not found"))
+	assert.NoError(t, cachedGtw.UpdateCustomer(ctx, updatedCustomer, nil))
+
+	got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	assert.Equal(t, &updatedCustomer, got)
+
+	got, err = cachedGtw.GetCustomerByEmail(ctx, updatedCustomer.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, &updatedCustomer, got)
+
+	got, err = cachedGtw.GetCustomerByName(ctx, updatedCustomer.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, &updatedCustomer, got)
+
+	got, err = cachedGtw.GetCustomerByEmail(ctx, oldCustomer.Email)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+
+	got, err = cachedGtw.GetCustomerByName(ctx, oldCustomer.Name)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_ReadsAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Twice()
+	gtw.On("GetCustomerByEmail", mock.Anything, customer.Email).Return(&customer, nil).Once()
+	gtw.On("DeleteCustomerByID", ctx, test.CustomerID, (*int64)(nil)).Return(nil).Once()
+
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	_, err = cachedGtw.GetCustomerByEmail(ctx, customer.Email)
+	assert.NoError(t, err)
+
+	assert.NoError(t, cachedGtw.DeleteCustomerByID(ctx, test.CustomerID, nil))
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(nil, errors.New("not found"))
+	gtw.On("GetCustomerByEmail", mock.Anything, customer.Email).Return(nil, errors.New("not found"))
+
+	got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+
+	got, err = cachedGtw.GetCustomerByEmail(ctx, customer.Email)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_StaleFillAfterUpdate(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, customerCache, _ := newCachedGateway(t, allReadPaths)
+
+	oldCustomer := *test.ACustomer
+	updatedCustomer := oldCustomer
+	updatedCustomer.Surname = "Smith"
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&oldCustomer, nil).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&updatedCustomer, nil).Once()
+	gtw.On("UpdateCustomer", ctx, updatedCustomer, (*int64)(nil)).Return(nil).Once()
+	assert.NoError(t, cachedGtw.UpdateCustomer(ctx, updatedCustomer, nil))
+
+	// a read that loaded the old row before the update fills the cache late
+	assert.NoError(t, customerCache.WriteCacheByID(ctx, test.CustomerID, &oldCustomer, nil))
+	assert.NoError(t, customerCache.WriteCacheByEmail(ctx, oldCustomer.Email, &oldCustomer, nil))
+
+	got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	assert.Equal(t, &updatedCustomer, got)
+
+	got, err = cachedGtw.GetCustomerByEmail(ctx, oldCustomer.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, &updatedCustomer, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_StaleFillAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, customerCache, _ := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once()
+	gtw.On("DeleteCustomerByID", ctx, test.CustomerID, (*int64)(nil)).Return(nil).Once()
+	assert.NoError(t, cachedGtw.DeleteCustomerByID(ctx, test.CustomerID, nil))
+
+	assert.NoError(t, customerCache.WriteCacheByID(ctx, test.CustomerID, &customer, nil))
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(nil, errors.New("not found")).Once()
+	got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_InterleavedMutations(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	customer.Version = 1
+	first := customer
+	first.Surname = "Smith"
+	first.Version = 2
+	second := first
+	second.Surname = "Brown"
+	second.Version = 3
+
+	// the first update re-reads its version, then the second update runs all the way through
+	// before the first one gets to refresh the cache
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once()
+	gtw.On("UpdateCustomer", ctx, first, (*int64)(nil)).Return(nil).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&first, nil).Once().Run(func(mock.Arguments) {
+		assert.NoError(t, cachedGtw.UpdateCustomer(ctx, second, nil))
+	})
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&first, nil).Once()
+	gtw.On("UpdateCustomer", ctx, second, (*int64)(nil)).Return(nil).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&second, nil).Once()
+	assert.NoError(t, cachedGtw.UpdateCustomer(ctx, first, nil))
+
+	// the late refresh of the first update doesn't replace the version of the second one
+	got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	assert.Equal(t, &second, got)
+
+	got, err = cachedGtw.GetCustomerByEmail(ctx, customer.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, &second, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_TTLPerKeyFamily(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, redisServer := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once()
+	gtw.On("GetCustomerByEmail", mock.Anything, customer.Email).Return(&customer, nil).Once()
+	gtw.On("GetCustomerByName", mock.Anything, customer.Name).Return(&customer, nil).Once()
+
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	_, err = cachedGtw.GetCustomerByEmail(ctx, customer.Email)
+	assert.NoError(t, err)
+	_, err = cachedGtw.GetCustomerByName(ctx, customer.Name)
+	assert.NoError(t, err)
+
+	// entries outlive their freshness by the stale window
+	assert.Equal(t, aCacheTTL.ID+aCacheTTL.Stale, redisServer.TTL("customer-id:"+test.CustomerID))
+	assert.Equal(t, aCacheTTL.Email+aCacheTTL.Stale, redisServer.TTL("customer-email:"+customer.Email))
+	assert.Equal(t, aCacheTTL.Name+aCacheTTL.Stale, redisServer.TTL("customer-name:"+customer.Name))
+
+	// expired entries go back to the DB
+	redisServer.FastForward(aCacheTTL.ID + aCacheTTL.Stale)
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once()
+	_, err = cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_ListInvalidatedByMutations(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	filter := entity.CustomerFilter{Limit: 2, SortBy: "id", SortOrder: "asc"}
+	newCustomer := entity.Customer{Name: "Maria", Surname: "Silva", Email: "maria@mock.com"}
+
+	gtw.On("GetCustomerList", mock.Anything, filter).Return(test.ALastCustomerPage, nil).Once()
+	gtw.On("CreateCustomer", ctx, newCustomer).Return(&test.CustomerID, nil).Once()
+
+	got, err := cachedGtw.GetCustomerList(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, test.ALastCustomerPage, got)
+
+	// served from cache
+	got, err = cachedGtw.GetCustomerList(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, test.ALastCustomerPage, got)
+
+	_, err = cachedGtw.CreateCustomer(ctx, newCustomer)
+	assert.NoError(t, err)
+
+	gtw.On("GetCustomerList", mock.Anything, filter).Return(test.ACustomerPage, nil).Once()
+	got, err = cachedGtw.GetCustomerList(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, test.ACustomerPage, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_DisabledReadPaths(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, redisServer := newCachedGateway(t, cache.CachedReadPaths{ByEmail: true})
+
+	customer := *test.ACustomer
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Twice()
+
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	_, err = cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+
+	assert.False(t, redisServer.Exists("customer-id:"+test.CustomerID))
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_NegativeCaching(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, redisServer := newCachedGateway(t, allReadPaths)
+
+	unknownEmail := "nobody@mock.com"
+	newCustomer := entity.Customer{Name: "Nobody", Surname: "Else", Email: unknownEmail}
+	gtw.On("GetCustomerByEmail", mock.Anything, unknownEmail).Return(nil, entity.ErrCustomerNotFound).Once()
+	gtw.On("CreateCustomer", ctx, newCustomer).Return(&test.CustomerID, nil).Once()
+
+	for i := 0; i < 3; i++ {
+		got, err := cachedGtw.GetCustomerByEmail(ctx, unknownEmail)
+		assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+		assert.Nil(t, got)
+	}
+	assert.Equal(t, aCacheTTL.Negative, redisServer.TTL("customer-email:"+unknownEmail))
+
+	// creating the customer drops the negative entry
+	_, err := cachedGtw.CreateCustomer(ctx, newCustomer)
+	assert.NoError(t, err)
+
+	gtw.On("GetCustomerByEmail", mock.Anything, unknownEmail).Return(&newCustomer, nil).Once()
+	got, err := cachedGtw.GetCustomerByEmail(ctx, unknownEmail)
+	assert.NoError(t, err)
+	assert.Equal(t, &newCustomer, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_CoalescesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).WaitUntil(time.After(100 * time.Millisecond))
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+			assert.NoError(t, err)
+			assert.Equal(t, &customer, got)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	gtw.AssertNumberOfCalls(t, "GetCustomerByID", 1)
+}
+
+func Test_CachedCustomerGtw_StaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	ttl := aCacheTTL
+	ttl.ID = time.Millisecond
+	cachedGtw, gtw, customerCache, _ := newCachedGatewayWithTTL(t, allReadPaths, ttl)
+
+	oldCustomer := *test.ACustomer
+	updatedCustomer := oldCustomer
+	updatedCustomer.Surname = "Smith"
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&oldCustomer, nil).Once()
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	// every concurrent read is served the stale value right away while a single load refreshes it
+	release := make(chan time.Time)
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&updatedCustomer, nil).Once().WaitUntil(release)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+			assert.NoError(t, err)
+			assert.Equal(t, &oldCustomer, got)
+		}()
+	}
+	wg.Wait()
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		cached, err := customerCache.ReadCacheByID(ctx, test.CustomerID)
+		return err == nil && cached.Customer.Surname == updatedCustomer.Surname
+	}, time.Second, 5*time.Millisecond)
+	gtw.AssertNumberOfCalls(t, "GetCustomerByID", 2)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_TombstoneBlocksConcurrentFill(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, redisServer := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	loaded := make(chan struct{})
+	release := make(chan struct{})
+	// a read misses and loads the row, the customer is deleted before it fills the cache
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once().
+		Run(func(mock.Arguments) { close(loaded); <-release })
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once()
+	gtw.On("DeleteCustomerByID", ctx, test.CustomerID, (*int64)(nil)).Return(nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+		assert.NoError(t, err)
+		assert.Equal(t, &customer, got)
+	}()
+	<-loaded
+	assert.NoError(t, cachedGtw.DeleteCustomerByID(ctx, test.CustomerID, nil))
+	close(release)
+	<-done
+
+	cached, err := redisServer.Get("customer-id:" + test.CustomerID)
+	assert.NoError(t, err)
+	assert.Equal(t, "evicted", cached)
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(nil, entity.ErrCustomerNotFound).Once()
+	got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+	assert.Nil(t, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_RevalidationKeepsNewerEntry(t *testing.T) {
+	ctx := context.Background()
+	ttl := aCacheTTL
+	ttl.ID = time.Millisecond
+	cachedGtw, gtw, customerCache, _ := newCachedGatewayWithTTL(t, allReadPaths, ttl)
+
+	customer := *test.ACustomer
+	customer.Version = 1
+	updatedCustomer := customer
+	updatedCustomer.Surname = "Smith"
+	updatedCustomer.Version = 2
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once()
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	// the revalidation loads the old row, an update refreshes the entry before it writes it back
+	loaded := make(chan struct{})
+	release := make(chan struct{})
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once().
+		Run(func(mock.Arguments) { close(loaded); <-release })
+	got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	assert.Equal(t, &customer, got)
+	<-loaded
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once()
+	gtw.On("UpdateCustomer", ctx, updatedCustomer, (*int64)(nil)).Return(nil).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&updatedCustomer, nil).Once()
+	assert.NoError(t, cachedGtw.UpdateCustomer(ctx, updatedCustomer, nil))
+	close(release)
+
+	// the revalidated entry is dropped, it no longer replaces the entry it was loaded for
+	assert.Never(t, func() bool {
+		cached, err := customerCache.ReadCacheByID(ctx, test.CustomerID)
+		return err != nil || cached.Customer.Version != updatedCustomer.Version
+	}, 100*time.Millisecond, 5*time.Millisecond)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_ReadsAfterPatch(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	oldCustomer := *test.ACustomer
+	patchedCustomer := oldCustomer
+	patchedCustomer.Birthdate = entity.NewDate(1991, time.April, 8)
+	birthdate := patchedCustomer.Birthdate
+	patch := entity.CustomerPatch{ID: test.CustomerID, Birthdate: &birthdate}
+	version := oldCustomer.Version
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&oldCustomer, nil).Twice()
+	gtw.On("PatchCustomer", ctx, patch, &version).Return(nil).Once()
+
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&patchedCustomer, nil)
+	assert.NoError(t, cachedGtw.PatchCustomer(ctx, patch, &version))
+
+	got, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	assert.Equal(t, &patchedCustomer, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_ReadsAfterRestore(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	gtw.On("GetCustomerByEmail", mock.Anything, customer.Email).Return(nil, entity.ErrCustomerNotFound).Once()
+	gtw.On("RestoreCustomerByID", ctx, test.CustomerID).Return(nil).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Once()
+
+	// the deleted customer is cached as not found
+	_, err := cachedGtw.GetCustomerByEmail(ctx, customer.Email)
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+
+	assert.NoError(t, cachedGtw.RestoreCustomerByID(ctx, test.CustomerID))
+
+	got, err := cachedGtw.GetCustomerByEmail(ctx, customer.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, &customer, got)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_ReadsAfterErase(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	erasure := entity.CustomerErasure{CustomerID: test.CustomerID, Actor: "dpo@mock.com"}
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Twice()
+	gtw.On("EraseCustomerByID", ctx, erasure).Return(nil).Once()
+
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, cachedGtw.EraseCustomerByID(ctx, erasure))
+
+	gtw.On("GetCustomerByName", mock.Anything, customer.Name).Return(nil, entity.ErrCustomerNotFound).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(nil, entity.ErrCustomerNotFound).Once()
+
+	_, err = cachedGtw.GetCustomerByName(ctx, customer.Name)
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+	_, err = cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_ReadsAfterAddressChange(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, _ := newCachedGateway(t, allReadPaths)
+
+	customer := *test.ACustomer
+	withAddress := *test.ACustomer
+	withAddress.Addresses = []*entity.Address{test.AnAddress}
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&customer, nil).Twice()
+	gtw.On("CreateCustomerAddress", ctx, *test.AnAddress).Return(&test.AddressID, nil).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&withAddress, nil).Once()
+
+	_, err := cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+
+	id, err := cachedGtw.CreateCustomerAddress(ctx, *test.AnAddress)
+	assert.NoError(t, err)
+	assert.Equal(t, &test.AddressID, id)
+
+	// every cached representation of the customer has the new address
+	got, err := cachedGtw.GetCustomerByEmail(ctx, customer.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, withAddress.Addresses, got.Addresses)
+	got, err = cachedGtw.GetCustomerByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	assert.Equal(t, withAddress.Addresses, got.Addresses)
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_ReadsByDocumentAfterCPFChange(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, redisServer := newCachedGateway(t, allReadPaths)
+
+	oldCPF, newCPF := "52998224725", "11144477735"
+	oldCustomer := *test.ACustomer
+	oldCustomer.CPF = &oldCPF
+	updatedCustomer := oldCustomer
+	updatedCustomer.CPF = &newCPF
+
+	gtw.On("GetCustomerByDocument", mock.Anything, oldCPF).Return(&oldCustomer, nil).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&oldCustomer, nil).Once()
+	gtw.On("UpdateCustomer", ctx, updatedCustomer, (*int64)(nil)).Return(nil).Once()
+	gtw.On("GetCustomerByID", mock.Anything, test.CustomerID).Return(&updatedCustomer, nil).Once()
+
+	_, err := cachedGtw.GetCustomerByDocument(ctx, oldCPF)
+	assert.NoError(t, err)
+	assert.NoError(t, cachedGtw.UpdateCustomer(ctx, updatedCustomer, nil))
+
+	// the new CPF is served from the refreshed cache and the old one is looked up again
+	got, err := cachedGtw.GetCustomerByDocument(ctx, newCPF)
+	assert.NoError(t, err)
+	assert.Equal(t, &updatedCustomer, got)
+	gtw.On("GetCustomerByDocument", mock.Anything, oldCPF).Return(nil, entity.ErrCustomerNotFound).Once()
+	_, err = cachedGtw.GetCustomerByDocument(ctx, oldCPF)
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+
+	// the keys hold a hash of the CPF, never the CPF itself
+	for _, key := range redisServer.Keys() {
+		assert.NotContains(t, key, oldCPF)
+		assert.NotContains(t, key, newCPF)
+	}
+	gtw.AssertExpectations(t)
+}
+
+func Test_CachedCustomerGtw_ImportEvictsOncePerBatch(t *testing.T) {
+	ctx := context.Background()
+	cachedGtw, gtw, _, redisServer := newCachedGateway(t, allReadPaths)
+
+	importedID, rejectedID := "importedID", "rejectedID"
+	imported := &entity.Customer{ID: &importedID, Name: "Ana", Email: "ana@mock.com"}
+	rejected := &entity.Customer{ID: &rejectedID, Name: "Bia", Email: "bia@mock.com"}
+
+	// the lookup before the import leaves a negative entry behind
+	gtw.On("GetCustomerByEmail", mock.Anything, imported.Email).Return(nil, entity.ErrCustomerNotFound).Once()
+	_, err := cachedGtw.GetCustomerByEmail(ctx, imported.Email)
+	assert.ErrorIs(t, err, entity.ErrCustomerNotFound)
+
+	gtw.On("ImportCustomers", ctx, []*entity.Customer{imported, rejected}, false).
+		Return([]error{nil, entity.ErrCustomerConflict}, nil).Once()
+	errs, err := cachedGtw.ImportCustomers(ctx, []*entity.Customer{imported, rejected}, false)
+	assert.NoError(t, err)
+	assert.Len(t, errs, 2)
+
+	email, err := redisServer.Get("customer-email:" + imported.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, "evicted", email)
+	assert.False(t, redisServer.Exists("customer-email:"+rejected.Email))
+	generation, err := redisServer.Get("customer-list-gen")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", generation)
+	gtw.AssertExpectations(t)
+}
@@ -0,0 +1,305 @@
+package cache
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"cmd/customer-service/internal/metrics"
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const invalidationChannel = "customer-cache-invalidation"
+
+type LocalCacheConfig struct {
+	Size int
+	TTL  time.Duration
+}
+
+type invalidationMessage struct {
+	Instance string   `json:"instance"`
+	Keys     []string `json:"keys"`
+}
+
+// invalidationPublisher broadcasts the invalidations, the breaker implements it so they aren't
+// sent while Redis is down
+type invalidationPublisher interface {
+	Publish(ctx context.Context, channel string, message interface{}) error
+}
+
+type redisPublisher struct {
+	client *redis.Client
+}
+
+func (p redisPublisher) Publish(ctx context.Context, channel string, message interface{}) error {
+	return p.client.Publish(ctx, channel, message).Err()
+}
+
+type localEntry struct {
+	key       string
+	cached    *CachedCustomer
+	expiresAt time.Time
+}
+
+// localCustomerCache is an in-process LRU tier in front of another CustomerCache.
+// Mutations are broadcast over Redis pub/sub so every replica drops its local copy;
+// the local TTL bounds staleness for messages lost while a replica was disconnected.
+// List pages aren't kept locally, they depend on the generation counter stored in Redis.
+type localCustomerCache struct {
+	logger    slog.Logger
+	metrics   *metrics.CustomerMetrics
+	client    *redis.Client
+	publisher invalidationPublisher
+	next      CustomerCache
+	config    LocalCacheConfig
+	documents DocumentHasher
+	instance  string
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// removals counts invalidations, so a read racing one doesn't store what it read before it
+	removals uint64
+}
+
+// NewLocalCustomerCache wraps a CustomerCache with a bounded in-memory tier and starts
+// listening for invalidations published by other replicas. When c is the breaker the
+// invalidations are published through it.
+func NewLocalCustomerCache(l slog.Logger, m *metrics.CustomerMetrics, client *redis.Client, c CustomerCache, cfg LocalCacheConfig, h DocumentHasher) CustomerCache {
+	instance := make([]byte, 8)
+	rand.Read(instance)
+	publisher, ok := c.(invalidationPublisher)
+	if !ok {
+		publisher = redisPublisher{client: client}
+	}
+
+	lc := &localCustomerCache{
+		logger:    *l.With("layer", "customer-local-cache"),
+		metrics:   m,
+		client:    client,
+		publisher: publisher,
+		next:      c,
+		config:    cfg,
+		documents: h,
+		instance:  hex.EncodeToString(instance),
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+	}
+	go lc.subscribe(context.Background())
+
+	return lc
+}
+
+func GetLocalCacheConfig() (*LocalCacheConfig, error) {
+	size, err := strconv.Atoi(os.Getenv("CACHE_LOCAL_SIZE"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_LOCAL_SIZE from .env: %s", err)
+	}
+
+	ttl, err := time.ParseDuration(os.Getenv("CACHE_LOCAL_TTL"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get CACHE_LOCAL_TTL from .env: %s", err)
+	}
+
+	return &LocalCacheConfig{
+		Size: size,
+		TTL:  ttl,
+	}, nil
+}
+
+func (c *localCustomerCache) ReadCacheByID(ctx context.Context, customerID string) (*CachedCustomer, error) {
+	return c.read(ctx, "id", "id:"+customerID, func() (*CachedCustomer, error) {
+		return c.next.ReadCacheByID(ctx, customerID)
+	})
+}
+
+func (c *localCustomerCache) WriteCacheByID(ctx context.Context, customerID string, customer *entity.Customer, stale *CachedCustomer) error {
+	c.remove("id:" + customerID)
+	return c.next.WriteCacheByID(ctx, customerID, customer, stale)
+}
+
+func (c *localCustomerCache) ReadCacheByEmail(ctx context.Context, customerEmail string) (*CachedCustomer, error) {
+	return c.read(ctx, "email", "email:"+customerEmail, func() (*CachedCustomer, error) {
+		return c.next.ReadCacheByEmail(ctx, customerEmail)
+	})
+}
+
+func (c *localCustomerCache) WriteCacheByEmail(ctx context.Context, customerEmail string, customer *entity.Customer, stale *CachedCustomer) error {
+	c.remove("email:" + customerEmail)
+	return c.next.WriteCacheByEmail(ctx, customerEmail, customer, stale)
+}
+
+func (c *localCustomerCache) ReadCacheByDocument(ctx context.Context, cpf string) (*CachedCustomer, error) {
+	return c.read(ctx, "document", "document:"+c.documents.Hash(cpf), func() (*CachedCustomer, error) {
+		return c.next.ReadCacheByDocument(ctx, cpf)
+	})
+}
+
+func (c *localCustomerCache) WriteCacheByDocument(ctx context.Context, cpf string, customer *entity.Customer, stale *CachedCustomer) error {
+	c.remove("document:" + c.documents.Hash(cpf))
+	return c.next.WriteCacheByDocument(ctx, cpf, customer, stale)
+}
+
+func (c *localCustomerCache) ReadCacheByName(ctx context.Context, customerName string) (*CachedCustomer, error) {
+	return c.read(ctx, "name", "name:"+customerName, func() (*CachedCustomer, error) {
+		return c.next.ReadCacheByName(ctx, customerName)
+	})
+}
+
+func (c *localCustomerCache) WriteCacheByName(ctx context.Context, customerName string, customer *entity.Customer, stale *CachedCustomer) error {
+	c.remove("name:" + customerName)
+	return c.next.WriteCacheByName(ctx, customerName, customer, stale)
+}
+
+func (c *localCustomerCache) ReadCacheList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error) {
+	return c.next.ReadCacheList(ctx, filter)
+}
+
+func (c *localCustomerCache) WriteCacheList(ctx context.Context, filter entity.CustomerFilter, page entity.CustomerPage) error {
+	return c.next.WriteCacheList(ctx, filter, page)
+}
+
+func (c *localCustomerCache) RefreshCache(ctx context.Context, customer entity.Customer) error {
+	err := c.next.RefreshCache(ctx, customer)
+	c.invalidate(ctx, customer)
+	return err
+}
+
+func (c *localCustomerCache) EvictCache(ctx context.Context, customer entity.Customer) error {
+	err := c.next.EvictCache(ctx, customer)
+	c.invalidate(ctx, customer)
+	return err
+}
+
+func (c *localCustomerCache) EvictCacheBatch(ctx context.Context, customers []entity.Customer) error {
+	err := c.next.EvictCacheBatch(ctx, customers)
+	c.invalidate(ctx, customers...)
+	return err
+}
+
+func (c *localCustomerCache) EvictCacheList(ctx context.Context) error {
+	return c.next.EvictCacheList(ctx)
+}
+
+func (c *localCustomerCache) read(ctx context.Context, family string, key string, readNext func() (*CachedCustomer, error)) (*CachedCustomer, error) {
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*localEntry)
+		if time.Now().Before(entry.expiresAt) {
+			c.lru.MoveToFront(element)
+			c.mu.Unlock()
+			c.metrics.IncCacheRequest("local", family, "hit")
+			c.logger.Debug("Got customer local cache", "family", family, "traceID", ctx.Value("traceID"))
+			return entry.cached, nil
+		}
+		c.lru.Remove(element)
+		delete(c.entries, key)
+	}
+	removals := c.removals
+	c.mu.Unlock()
+	c.metrics.IncCacheRequest("local", family, "miss")
+
+	cached, err := readNext()
+	if err != nil {
+		return nil, err
+	}
+	c.add(key, cached, removals)
+
+	return cached, nil
+}
+
+func (c *localCustomerCache) add(key string, cached *CachedCustomer, removals uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if removals != c.removals {
+		return
+	}
+
+	entry := &localEntry{key: key, cached: cached, expiresAt: time.Now().Add(c.config.TTL)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.config.Size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*localEntry).key)
+	}
+}
+
+func (c *localCustomerCache) remove(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.removals++
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.lru.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// invalidate drops the customers' local entries and tells the other replicas to do the same
+func (c *localCustomerCache) invalidate(ctx context.Context, customers ...entity.Customer) {
+	keys := make([]string, 0, 4*len(customers))
+	for _, customer := range customers {
+		keys = append(keys, "email:"+customer.Email, "name:"+customer.Name)
+		if customer.ID != nil {
+			keys = append(keys, "id:"+*customer.ID)
+		}
+		if customer.CPF != nil {
+			keys = append(keys, "document:"+c.documents.Hash(*customer.CPF))
+		}
+	}
+	c.remove(keys...)
+
+	data, err := json.Marshal(invalidationMessage{Instance: c.instance, Keys: keys})
+	if err != nil {
+		c.logger.Error("Failed to marshal customer cache invalidation", "error", err, "traceID", ctx.Value("traceID"))
+		return
+	}
+
+	err = c.publisher.Publish(ctx, invalidationChannel, data)
+	if errors.Is(err, ErrCacheUnavailable) {
+		c.logger.Debug("Skipped customer cache invalidation, cache is unavailable", "traceID", ctx.Value("traceID"))
+		return
+	}
+	if err != nil {
+		c.logger.Error("Failed to publish customer cache invalidation", "error", err, "traceID", ctx.Value("traceID"))
+	}
+}
+
+func (c *localCustomerCache) subscribe(ctx context.Context) {
+	sub := c.client.Subscribe(ctx, invalidationChannel)
+	defer sub.Close()
+
+	for msg := range sub.Channel() {
+		var invalidation invalidationMessage
+		err := json.Unmarshal([]byte(msg.Payload), &invalidation)
+		if err != nil {
+			c.logger.Error("Failed to unMarshal customer cache invalidation", "error", err)
+			continue
+		}
+		if invalidation.Instance == c.instance {
+			continue
+		}
+
+		c.logger.Debug("Dropping invalidated customer local cache", "keys", invalidation.Keys, "instance", invalidation.Instance)
+		c.remove(invalidation.Keys...)
+	}
+}
@@ -0,0 +1,163 @@
+package cache_test
+
+import (
+	"cmd/customer-service/internal/metrics"
+	"cmd/customer-service/internal/resources/cache"
+	"cmd/customer-service/test"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLocalCaches(t *testing.T, size int, replicas int) ([]cache.CustomerCache, *metrics.CustomerMetrics, *miniredis.Miniredis) {
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+	m := metrics.NewCustomerMetrics(logger, prometheus.NewRegistry())
+
+	redisCache := cache.NewCustomerCache(logger, m, client, aCacheTTL, aDocumentHasher)
+	caches := make([]cache.CustomerCache, replicas)
+	for i := range caches {
+		caches[i] = cache.NewLocalCustomerCache(logger, m, client, redisCache, cache.LocalCacheConfig{Size: size, TTL: time.Minute}, aDocumentHasher)
+	}
+	assert.Eventually(t, func() bool {
+		return redisServer.PubSubNumSub("customer-cache-invalidation")["customer-cache-invalidation"] == replicas
+	}, time.Second, 5*time.Millisecond)
+
+	return caches, m, redisServer
+}
+
+func Test_LocalCustomerCache_ServesFromMemory(t *testing.T) {
+	ctx := context.Background()
+	caches, m, redisServer := newLocalCaches(t, 10, 1)
+	localCache := caches[0]
+
+	customer := *test.ACustomer
+	assert.NoError(t, localCache.WriteCacheByID(ctx, test.CustomerID, &customer, nil))
+
+	for i := 0; i < 3; i++ {
+		cached, err := localCache.ReadCacheByID(ctx, test.CustomerID)
+		assert.NoError(t, err)
+		assert.Equal(t, &customer, cached.Customer)
+	}
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.CacheRequests.WithLabelValues("of-customer-service", "local", "id", "miss")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.CacheRequests.WithLabelValues("of-customer-service", "local", "id", "hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.CacheRequests.WithLabelValues("of-customer-service", "redis", "id", "hit")))
+
+	// the local copy doesn't need Redis anymore
+	redisServer.FlushAll()
+	_, err := localCache.ReadCacheByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+}
+
+func Test_LocalCustomerCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	caches, _, redisServer := newLocalCaches(t, 2, 1)
+	localCache := caches[0]
+
+	customer := *test.ACustomer
+	for _, id := range []string{"1", "2", "3"} {
+		assert.NoError(t, localCache.WriteCacheByID(ctx, id, &customer, nil))
+		_, err := localCache.ReadCacheByID(ctx, id)
+		assert.NoError(t, err)
+	}
+
+	redisServer.FlushAll()
+	_, err := localCache.ReadCacheByID(ctx, "1")
+	assert.ErrorIs(t, err, redis.Nil)
+	for _, id := range []string{"2", "3"} {
+		_, err := localCache.ReadCacheByID(ctx, id)
+		assert.NoError(t, err)
+	}
+}
+
+func Test_LocalCustomerCache_InvalidatesOtherReplicas(t *testing.T) {
+	ctx := context.Background()
+	caches, _, _ := newLocalCaches(t, 10, 2)
+	replicaA, replicaB := caches[0], caches[1]
+
+	customer := *test.ACustomer
+	assert.NoError(t, replicaA.WriteCacheByID(ctx, test.CustomerID, &customer, nil))
+	_, err := replicaA.ReadCacheByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, replicaB.EvictCache(ctx, customer))
+
+	assert.Eventually(t, func() bool {
+		_, err := replicaA.ReadCacheByID(ctx, test.CustomerID)
+		return err == redis.Nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+// publishCounter counts the PUBLISH commands a client sends, including the failed ones
+type publishCounter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (p *publishCounter) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (p *publishCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "publish" {
+			p.mu.Lock()
+			p.count++
+			p.mu.Unlock()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (p *publishCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func (p *publishCounter) Count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.count
+}
+
+func Test_LocalCustomerCache_SkipsInvalidationWhileBreakerIsOpen(t *testing.T) {
+	ctx := context.Background()
+	redisServer := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: redisServer.Addr(), MaxRetries: -1})
+	publishes := &publishCounter{}
+	client.AddHook(publishes)
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+	m := metrics.NewCustomerMetrics(logger, prometheus.NewRegistry())
+
+	breaker := cache.NewBreakerCustomerCache(logger, client, cache.NewCustomerCache(logger, m, client, aCacheTTL, aDocumentHasher), aBreakerConfig)
+	localCache := cache.NewLocalCustomerCache(logger, m, client, breaker, cache.LocalCacheConfig{Size: 10, TTL: time.Minute}, aDocumentHasher)
+
+	customer := *test.ACustomer
+	assert.NoError(t, localCache.WriteCacheByID(ctx, test.CustomerID, &customer, nil))
+	_, err := localCache.ReadCacheByID(ctx, test.CustomerID)
+	assert.NoError(t, err)
+	assert.NoError(t, localCache.EvictCache(ctx, customer))
+	assert.Equal(t, 1, publishes.Count())
+
+	redisServer.Close()
+	for i := 0; i < aBreakerConfig.Threshold; i++ {
+		_, err := breaker.ReadCacheByID(ctx, "unknown")
+		assert.Error(t, err)
+	}
+	assert.ErrorIs(t, breaker.Check(ctx), cache.ErrCacheUnavailable)
+
+	// the mutation doesn't wait on Redis, the local entry is dropped all the same
+	assert.ErrorIs(t, localCache.EvictCache(ctx, customer), cache.ErrCacheUnavailable)
+	assert.Equal(t, 1, publishes.Count())
+	_, err = localCache.ReadCacheByID(ctx, test.CustomerID)
+	assert.ErrorIs(t, err, cache.ErrCacheUnavailable)
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// CreateCustomerAddress makes the address the default one when asked to, or when it's the first of the customer
+func (g *customerGateway) CreateCustomerAddress(ctx context.Context, address entity.Address) (*string, error) {
+	g.logger.Debug("Inserting customer address into db", "customerID", address.CustomerID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "CreateCustomerAddress", "")
+
+	id := ulid.Make().String()
+	err := g.changeCustomerDetails(ctx, address.CustomerID, func(tx *sql.Tx) error {
+		if !address.IsDefault {
+			err := tx.QueryRowContext(ctx, "SELECT NOT EXISTS (SELECT 1 FROM customer_addresses WHERE customer_id = $1);", address.CustomerID).Scan(&address.IsDefault)
+			if err != nil {
+				return err
+			}
+		}
+		if address.IsDefault {
+			err := clearDefaultAddress(ctx, tx, address.CustomerID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `INSERT INTO customer_addresses (address_id, customer_id, type, street, number, complement, district, city, state, cep, is_default, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'NOW()');`,
+			id,
+			address.CustomerID,
+			address.Type,
+			address.Street,
+			address.Number,
+			address.Complement,
+			address.District,
+			address.City,
+			address.State,
+			address.CEP,
+			address.IsDefault)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+// UpdateCustomerAddress moves the default flag to the address when asked to, it's never just unset
+func (g *customerGateway) UpdateCustomerAddress(ctx context.Context, address entity.Address) error {
+	g.logger.Debug("Updating customer address on db", "ID", address.ID, "customerID", address.CustomerID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "UpdateCustomerAddress", "")
+
+	return g.changeCustomerDetails(ctx, address.CustomerID, func(tx *sql.Tx) error {
+		if address.IsDefault {
+			err := clearDefaultAddress(ctx, tx, address.CustomerID)
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, `UPDATE customer_addresses SET type = $1, street = $2, number = $3, complement = $4, district = $5, city = $6, state = $7, cep = $8,
+			is_default = is_default OR $9, updated_at = 'NOW()' WHERE address_id = $10 AND customer_id = $11;`,
+			address.Type,
+			address.Street,
+			address.Number,
+			address.Complement,
+			address.District,
+			address.City,
+			address.State,
+			address.CEP,
+			address.IsDefault,
+			address.ID,
+			address.CustomerID)
+		if err != nil {
+			return err
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return fmt.Errorf("%w with ID=%s", entity.ErrAddressNotFound, *address.ID)
+		}
+		return nil
+	})
+}
+
+// DeleteCustomerAddress hands the default flag over to the oldest address left
+func (g *customerGateway) DeleteCustomerAddress(ctx context.Context, customerID string, addressID string) error {
+	g.logger.Debug("Deleting customer address on db", "ID", addressID, "customerID", customerID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "DeleteCustomerAddress", "")
+
+	return g.changeCustomerDetails(ctx, customerID, func(tx *sql.Tx) error {
+		var wasDefault bool
+		err := tx.QueryRowContext(ctx, "DELETE FROM customer_addresses WHERE address_id = $1 AND customer_id = $2 RETURNING is_default;", addressID, customerID).Scan(&wasDefault)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w with ID=%s", entity.ErrAddressNotFound, addressID)
+		}
+		if err != nil || !wasDefault {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE customer_addresses SET is_default = true, updated_at = 'NOW()'
+			WHERE address_id = (SELECT address_id FROM customer_addresses WHERE customer_id = $1 ORDER BY created_at, address_id LIMIT 1);`, customerID)
+		return err
+	})
+}
+
+func clearDefaultAddress(ctx context.Context, tx *sql.Tx, customerID string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE customer_addresses SET is_default = false, updated_at = 'NOW()' WHERE customer_id = $1 AND is_default;", customerID)
+	return err
+}
+
+// getCustomerAddresses loads the addresses of a customer, the default one first
+func (g *customerGateway) getCustomerAddresses(ctx context.Context, customerID string) ([]*entity.Address, error) {
+	start := time.Now()
+	rows, err := g.db.QueryContext(ctx, `SELECT address_id, customer_id, type, street, number, complement, district, city, state, cep, is_default, created_at, updated_at
+		FROM customer_addresses WHERE customer_id = $1 ORDER BY is_default DESC, created_at, address_id;`, customerID)
+	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetCustomerAddresses", "")
+	if err != nil {
+		g.logger.Error("Failed to get customer addresses from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	addresses := make([]*entity.Address, 0)
+	for rows.Next() {
+		address := &entity.Address{}
+		err = rows.Scan(&address.ID, &address.CustomerID, &address.Type, &address.Street, &address.Number, &address.Complement, &address.District,
+			&address.City, &address.State, &address.CEP, &address.IsDefault, &address.CreatedAt, &address.UpdatedAt)
+		if err != nil {
+			g.logger.Error("Error scaning address row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating address rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return addresses, nil
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+var customerSortColumns = map[string]string{
+	"id":         "customer_id",
+	"name":       "name",
+	"surname":    "surname",
+	"email":      "email",
+	"created_at": "created_at",
+}
+
+// birthdayCursorSort marks the cursors of the upcoming birthdays, they can't be used on the list
+const birthdayCursorSort = "next_birthday"
+
+type customerCursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Value     string `json:"v"`
+	ID        string `json:"id"`
+}
+
+func encodeCustomerCursor(c customerCursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCustomerCursor(cursor string, sortBy string, sortOrder string) (*customerCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, entity.InvalidField("cursor", "is malformed")
+	}
+
+	var c customerCursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, entity.InvalidField("cursor", "is malformed")
+	}
+
+	if c.SortBy != sortBy || c.SortOrder != sortOrder {
+		return nil, entity.InvalidField("cursor", fmt.Sprintf("was issued for sort_by=%s sort_order=%s", c.SortBy, c.SortOrder))
+	}
+
+	return &c, nil
+}
+
+// customerSearchCursor is the score and ID of the last result of a page, the results are ordered by both
+type customerSearchCursor struct {
+	Query string  `json:"q"`
+	Score float64 `json:"sc"`
+	ID    string  `json:"id"`
+}
+
+func encodeCustomerSearchCursor(c customerSearchCursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCustomerSearchCursor(cursor string, query string) (*customerSearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, entity.InvalidField("cursor", "is malformed")
+	}
+
+	var c customerSearchCursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, entity.InvalidField("cursor", "is malformed")
+	}
+
+	if c.Query != query {
+		return nil, entity.InvalidField("cursor", fmt.Sprintf("was issued for q=%s", c.Query))
+	}
+	if c.ID == "" {
+		return nil, entity.InvalidField("cursor", "is malformed")
+	}
+
+	return &c, nil
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"log/slog"
@@ -14,6 +15,16 @@ import (
 	"github.com/oklog/ulid/v2"
 )
 
+// customerSearchDocument must stay identical to the customers_search_trgm_idx expression, or the index isn't used
+const customerSearchDocument = "immutable_unaccent(lower(name || ' ' || surname || ' ' || email))"
+
+// customerEraseQuery anonymizes every PII column, the email keeps the ID so it stays unique.
+// The addresses and phones are deleted in the same transaction.
+// An erased customer is also deleted, so it's hidden from reads like any deleted one.
+const customerEraseQuery = `UPDATE customers SET name = 'Erased', surname = 'Erased', email = customer_id || '@erased.invalid', birthdate = NULL, cpf = NULL,
+	deleted_at = COALESCE(deleted_at, 'NOW()'), erased_at = 'NOW()', updated_at = 'NOW()', version = version + 1
+	WHERE customer_id = $1 AND erased_at IS NULL;`
+
 type customerGateway struct {
 	logger  slog.Logger
 	metrics *metrics.CustomerMetrics
@@ -28,110 +39,285 @@ func NewCustomerGateway(l slog.Logger, m *metrics.CustomerMetrics, db *sql.DB) g
 	}
 }
 
-func (g *customerGateway) GetCustomerList(ctx context.Context) ([]*entity.Customer, error) {
-	g.logger.Debug("Getting all customers from db", "traceID", ctx.Value("traceID"))
-	query := "SELECT customer_ID, name, surname, email, birthdate, created_at, updated_at FROM customers;"
+func (g *customerGateway) GetCustomerList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error) {
+	g.logger.Debug("Getting customer list from db", "filter", filter, "traceID", ctx.Value("traceID"))
+	query, args, err := buildCustomerListQuery(filter)
+	if err != nil {
+		g.logger.Error("Failed to build customer list query", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
 	start := time.Now()
 
-	rows, err := g.db.Query(query)
+	rows, err := g.db.QueryContext(ctx, query, args...)
 	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetCustomerList", "")
 	if err != nil {
 		g.logger.Error("Failed to get customers from db", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	defer rows.Close()
-	customers := make([]*entity.Customer, 0)
+	customers := make([]*entity.Customer, 0, filter.Limit+1)
 	for rows.Next() {
 		customer := &entity.Customer{}
-		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CreatedAt, &customer.UpdatedAt)
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version)
 		if err != nil {
 			g.logger.Error("Error scaning row", "error", err, "traceID", ctx.Value("traceID"))
 			return nil, err
 		}
 		customers = append(customers, customer)
 	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating customer rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
 
-	g.logger.Info("Found customer list on DB", "size", len(customers))
-	return customers, nil
+	page := &entity.CustomerPage{Customers: customers}
+	if len(customers) > filter.Limit {
+		page.Customers = customers[:filter.Limit]
+		nextCursor, err := encodeCustomerCursor(cursorFromCustomer(page.Customers[filter.Limit-1], filter))
+		if err != nil {
+			g.logger.Error("Failed to encode next cursor", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		page.NextCursor = &nextCursor
+	}
+
+	g.logger.Info("Found customer list on DB", "size", len(page.Customers))
+	return page, nil
 }
 
 func (g *customerGateway) GetCustomerByID(ctx context.Context, customerID string) (*entity.Customer, error) {
 	g.logger.Debug("Getting customer by ID from db", "ID", customerID, "traceID", ctx.Value("traceID"))
-	query := "SELECT customer_id, name, surname, email, birthdate, created_at, updated_at FROM customers WHERE customer_id = $1;"
+	query := "SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE customer_id = $1 AND deleted_at IS NULL;"
 	start := time.Now()
 
 	rows, err := g.db.Query(query, customerID)
 	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetCustomerByID", "")
 	if err != nil {
 		g.logger.Error("Failed to get customer by ID from db", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	defer rows.Close()
 	for rows.Next() {
 		customer := entity.Customer{}
-		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CreatedAt, &customer.UpdatedAt)
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version)
 		if err != nil {
 			g.logger.Error("Error scaning product row", "error", err)
 			return nil, err
 		}
-		return &customer, nil
+		rows.Close()
+		return g.withDetails(ctx, &customer)
 	}
 
-	return nil, fmt.Errorf("No customer found with ID=%s", customerID)
+	return nil, fmt.Errorf("%w with ID=%s", entity.ErrCustomerNotFound, customerID)
 }
 
 func (g *customerGateway) GetCustomerByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error) {
 	g.logger.Debug("Getting customer by email from db", "email", customerEmail, "traceID", ctx.Value("traceID"))
-	query := "SELECT customer_id, name, surname, email, birthdate, created_at, updated_at FROM customers WHERE email = $1;"
+	query := "SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE email = $1 AND deleted_at IS NULL;"
 	start := time.Now()
 
 	rows, err := g.db.Query(query, customerEmail)
 	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetCustomerByEmail", "")
 	if err != nil {
 		g.logger.Error("Failed to get customer by email from db", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	defer rows.Close()
 	for rows.Next() {
 		customer := entity.Customer{}
-		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CreatedAt, &customer.UpdatedAt)
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version)
 		if err != nil {
 			g.logger.Error("Error scaning row", "error", err)
 			return nil, err
 		}
-		return &customer, nil
+		rows.Close()
+		return g.withDetails(ctx, &customer)
 	}
 
-	return nil, fmt.Errorf("No customer found with email=%s", customerEmail)
+	return nil, fmt.Errorf("%w with email=%s", entity.ErrCustomerNotFound, customerEmail)
+}
+
+// GetCustomerByDocument only logs the masked CPF
+func (g *customerGateway) GetCustomerByDocument(ctx context.Context, cpf string) (*entity.Customer, error) {
+	g.logger.Debug("Getting customer by document from db", "cpf", entity.MaskCPF(cpf), "traceID", ctx.Value("traceID"))
+	query := "SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE cpf = $1 AND deleted_at IS NULL;"
+	start := time.Now()
+
+	rows, err := g.db.Query(query, cpf)
+	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetCustomerByDocument", "")
+	if err != nil {
+		g.logger.Error("Failed to get customer by document from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		customer := entity.Customer{}
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version)
+		if err != nil {
+			g.logger.Error("Error scaning row", "error", err)
+			return nil, err
+		}
+		rows.Close()
+		return g.withDetails(ctx, &customer)
+	}
+
+	return nil, fmt.Errorf("%w with cpf=%s", entity.ErrCustomerNotFound, entity.MaskCPF(cpf))
+}
+
+func (g *customerGateway) GetCustomerByPhone(ctx context.Context, number string) (*entity.Customer, error) {
+	g.logger.Debug("Getting customer by phone from db", "number", number, "traceID", ctx.Value("traceID"))
+	query := `SELECT c.customer_id, c.name, c.surname, c.email, c.birthdate, c.cpf, c.created_at, c.updated_at, c.version
+		FROM customers c JOIN customer_phones p ON p.customer_id = c.customer_id WHERE p.number = $1 AND c.deleted_at IS NULL;`
+	start := time.Now()
+
+	rows, err := g.db.Query(query, number)
+	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetCustomerByPhone", "")
+	if err != nil {
+		g.logger.Error("Failed to get customer by phone from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		customer := entity.Customer{}
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version)
+		if err != nil {
+			g.logger.Error("Error scaning row", "error", err)
+			return nil, err
+		}
+		rows.Close()
+		return g.withDetails(ctx, &customer)
+	}
+
+	return nil, fmt.Errorf("%w with phone=%s", entity.ErrCustomerNotFound, number)
 }
 
 func (g *customerGateway) GetCustomerByName(ctx context.Context, customerName string) (*entity.Customer, error) {
 	g.logger.Debug("Getting customer by name from db", "name", customerName, "traceID", ctx.Value("traceID"))
-	query := "SELECT customer_id, name, surname, email, birthdate, created_at, updated_at FROM customers WHERE name = $1 LIMIT 1;"
+	query := "SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE name = $1 AND deleted_at IS NULL LIMIT 1;"
 	start := time.Now()
 
 	rows, err := g.db.Query(query, customerName)
 	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetCustomerByName", "")
 	if err != nil {
 		g.logger.Error("Failed to get customer by name from db", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	defer rows.Close()
 	for rows.Next() {
 		customer := entity.Customer{}
-		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CreatedAt, &customer.UpdatedAt)
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version)
 		if err != nil {
 			g.logger.Error("Error scaning row", "error", err)
 			return nil, err
 		}
-		return &customer, nil
+		rows.Close()
+		return g.withDetails(ctx, &customer)
+	}
+
+	return nil, fmt.Errorf("%w with name=%s", entity.ErrCustomerNotFound, customerName)
+}
+
+func (g *customerGateway) SearchCustomers(ctx context.Context, search entity.CustomerSearch) (*entity.CustomerSearchPage, error) {
+	g.logger.Debug("Searching customers on db", "search", search, "traceID", ctx.Value("traceID"))
+	query, args, err := buildCustomerSearchQuery(search)
+	if err != nil {
+		g.logger.Error("Failed to build customer search query", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+	start := time.Now()
+
+	rows, err := g.db.QueryContext(ctx, query, args...)
+	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "SearchCustomers", "")
+	if err != nil {
+		g.logger.Error("Failed to search customers on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	customers := make([]*entity.ScoredCustomer, 0, search.Limit+1)
+	for rows.Next() {
+		customer := &entity.ScoredCustomer{}
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version, &customer.Score)
+		if err != nil {
+			g.logger.Error("Error scaning row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating customer rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	page := &entity.CustomerSearchPage{Customers: customers}
+	if len(customers) > search.Limit {
+		page.Customers = customers[:search.Limit]
+		last := page.Customers[search.Limit-1]
+		nextCursor, err := encodeCustomerSearchCursor(customerSearchCursor{Query: search.Query, Score: last.Score, ID: *last.ID})
+		if err != nil {
+			g.logger.Error("Failed to encode next cursor", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		page.NextCursor = &nextCursor
 	}
 
-	return nil, fmt.Errorf("No customer found with name=%s", customerName)
+	g.logger.Info("Found customers on DB", "size", len(page.Customers))
+	return page, nil
+}
+
+// GetUpcomingBirthdays isn't backed by an index, it scans the active customers with a birthdate
+func (g *customerGateway) GetUpcomingBirthdays(ctx context.Context, filter entity.BirthdayFilter) (*entity.BirthdayPage, error) {
+	g.logger.Debug("Getting upcoming birthdays from db", "filter", filter, "traceID", ctx.Value("traceID"))
+	query, args, err := buildUpcomingBirthdaysQuery(filter)
+	if err != nil {
+		g.logger.Error("Failed to build upcoming birthdays query", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+	start := time.Now()
+
+	rows, err := g.db.QueryContext(ctx, query, args...)
+	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetUpcomingBirthdays", "")
+	if err != nil {
+		g.logger.Error("Failed to get upcoming birthdays from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	customers := make([]*entity.UpcomingBirthday, 0, filter.Limit+1)
+	for rows.Next() {
+		customer := &entity.UpcomingBirthday{}
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version, &customer.NextBirthday)
+		if err != nil {
+			g.logger.Error("Error scaning row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		customer.Turning = customer.NextBirthday.Year() - customer.Birthdate.Year()
+		customers = append(customers, customer)
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating customer rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	page := &entity.BirthdayPage{Customers: customers}
+	if len(customers) > filter.Limit {
+		page.Customers = customers[:filter.Limit]
+		last := page.Customers[filter.Limit-1]
+		nextCursor, err := encodeCustomerCursor(customerCursor{SortBy: birthdayCursorSort, SortOrder: "asc", Value: last.NextBirthday.String(), ID: *last.ID})
+		if err != nil {
+			g.logger.Error("Failed to encode next cursor", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		page.NextCursor = &nextCursor
+	}
+
+	return page, nil
 }
 
 func (g *customerGateway) CreateCustomer(ctx context.Context, customer entity.Customer) (*string, error) {
@@ -139,58 +325,416 @@ func (g *customerGateway) CreateCustomer(ctx context.Context, customer entity.Cu
 	start := time.Now()
 
 	id := ulid.Make().String()
-	_, err := g.db.Exec(`INSERT INTO customers (customer_id, name, surname, email, birthdate, created_at) VALUES ($1, $2, $3, $4, $5, 'NOW()');`,
+	_, err := g.db.Exec(`INSERT INTO customers (customer_id, name, surname, email, birthdate, cpf, created_at) VALUES ($1, $2, $3, $4, $5, $6, 'NOW()');`,
 		id,
 		customer.Name,
 		customer.Surname,
 		customer.Email,
-		customer.Birthdate)
+		customer.Birthdate,
+		customer.CPF)
 	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "CreateCustomer", "")
 	if err != nil {
 		g.logger.Error("Failed to insert customer into db", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return &id, nil
 }
 
-func (g *customerGateway) UpdateCustomer(ctx context.Context, customer entity.Customer) error {
+func (g *customerGateway) UpdateCustomer(ctx context.Context, customer entity.Customer, ifMatch *int64) error {
 	g.logger.Debug("Updating customer on db", "ID", customer.ID, "traceID", ctx.Value("traceID"))
 	start := time.Now()
 
-	result, err := g.db.Exec(`UPDATE customers SET name = $1, surname = $2, email = $3, updated_at = 'NOW()' WHERE customer_id = $4;`,
+	result, err := g.db.Exec(`UPDATE customers SET name = $1, surname = $2, email = $3, birthdate = $4, cpf = $5, updated_at = 'NOW()', version = version + 1 WHERE customer_id = $6 AND deleted_at IS NULL AND ($7::bigint IS NULL OR version = $7);`,
 		customer.Name,
 		customer.Surname,
 		customer.Email,
-		customer.ID)
+		customer.Birthdate,
+		customer.CPF,
+		customer.ID,
+		ifMatch)
 	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "UpdateCustomer", "")
 	if err != nil {
 		g.logger.Error("Failed to update customer on db", "error", err, "traceID", ctx.Value("traceID"))
-		return err
+		return translateError(err)
 	}
 
-	return validateIfRowWasAffected(result, *customer.ID)
+	return g.validateIfRowWasAffected(ctx, result, *customer.ID, ifMatch)
 }
 
-func (g *customerGateway) DeleteCustomerByID(ctx context.Context, customerID string) error {
+func (g *customerGateway) PatchCustomer(ctx context.Context, patch entity.CustomerPatch, ifMatch *int64) error {
+	g.logger.Debug("Patching customer on db", "ID", patch.ID, "traceID", ctx.Value("traceID"))
+	query, args := buildCustomerPatchQuery(patch, ifMatch)
+	start := time.Now()
+
+	result, err := g.db.ExecContext(ctx, query, args...)
+	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "PatchCustomer", "")
+	if err != nil {
+		g.logger.Error("Failed to patch customer on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	return g.validateIfRowWasAffected(ctx, result, patch.ID, ifMatch)
+}
+
+func (g *customerGateway) DeleteCustomerByID(ctx context.Context, customerID string, ifMatch *int64) error {
 	g.logger.Debug("Deleting customer on db", "ID", customerID, "traceID", ctx.Value("traceID"))
 	start := time.Now()
 
-	result, err := g.db.Exec(`DELETE FROM customers WHERE customer_id = $1;`, customerID)
+	result, err := g.db.Exec(`UPDATE customers SET deleted_at = 'NOW()', updated_at = 'NOW()', version = version + 1 WHERE customer_id = $1 AND deleted_at IS NULL AND ($2::bigint IS NULL OR version = $2);`, customerID, ifMatch)
 	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "DeleteCustomerByID", "")
 	if err != nil {
 		g.logger.Error("Failed to update customer on db", "error", err, "traceID", ctx.Value("traceID"))
-		return err
+		return translateError(err)
+	}
+
+	return g.validateIfRowWasAffected(ctx, result, customerID, ifMatch)
+}
+
+func (g *customerGateway) RestoreCustomerByID(ctx context.Context, customerID string) error {
+	g.logger.Debug("Restoring customer on db", "ID", customerID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+
+	// erased customers can't come back, their data is gone
+	result, err := g.db.ExecContext(ctx, `UPDATE customers SET deleted_at = NULL, updated_at = 'NOW()', version = version + 1 WHERE customer_id = $1 AND deleted_at IS NOT NULL AND erased_at IS NULL;`, customerID)
+	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "RestoreCustomerByID", "")
+	if err != nil {
+		g.logger.Error("Failed to restore customer on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w, no deleted customer with ID=%s", entity.ErrCustomerNotFound, customerID)
 	}
 
-	return validateIfRowWasAffected(result, customerID)
+	return nil
 }
 
-func validateIfRowWasAffected(result sql.Result, customerID string) error {
+// EraseCustomerByID overwrites the PII of a customer, active or deleted, and records who did it
+// in the same transaction so there's never an erase without its audit entry
+func (g *customerGateway) EraseCustomerByID(ctx context.Context, erasure entity.CustomerErasure) error {
+	g.logger.Debug("Erasing customer on db", "ID", erasure.CustomerID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "EraseCustomerByID", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin erase transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, customerEraseQuery, erasure.CustomerID)
+	if err != nil {
+		g.logger.Error("Failed to erase customer on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		return fmt.Errorf("Customer not found ID=%s", customerID)
+		return fmt.Errorf("%w, no customer to erase with ID=%s", entity.ErrCustomerNotFound, erasure.CustomerID)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM customer_addresses WHERE customer_id = $1;", erasure.CustomerID)
+	if err != nil {
+		g.logger.Error("Failed to erase customer addresses on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM customer_phones WHERE customer_id = $1;", erasure.CustomerID)
+	if err != nil {
+		g.logger.Error("Failed to erase customer phones on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	traceID, _ := ctx.Value("traceID").(string)
+	_, err = tx.ExecContext(ctx, `INSERT INTO customer_audit (audit_id, customer_id, action, actor, reason, trace_id, created_at) VALUES ($1, $2, 'erase', $3, $4, $5, 'NOW()');`,
+		ulid.Make().String(),
+		erasure.CustomerID,
+		erasure.Actor,
+		erasure.Reason,
+		traceID)
+	if err != nil {
+		g.logger.Error("Failed to write customer audit entry", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		g.logger.Error("Failed to commit erase transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
 	}
 
 	return nil
 }
+
+// withDetails completes a single customer read with its addresses and phones
+func (g *customerGateway) withDetails(ctx context.Context, customer *entity.Customer) (*entity.Customer, error) {
+	addresses, err := g.getCustomerAddresses(ctx, *customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	customer.Addresses = addresses
+
+	phones, err := g.getCustomerPhones(ctx, *customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	customer.Phones = phones
+
+	return customer, nil
+}
+
+// changeCustomerDetails runs change in a transaction that also bumps the customer version, its
+// addresses and phones are part of its representation. Locking the customer row serializes the
+// changes to the default address and the primary phone.
+func (g *customerGateway) changeCustomerDetails(ctx context.Context, customerID string, change func(tx *sql.Tx) error) error {
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin customer details transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE customers SET updated_at = 'NOW()', version = version + 1 WHERE customer_id = $1 AND deleted_at IS NULL;", customerID)
+	if err != nil {
+		g.logger.Error("Failed to lock customer", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w with ID=%s", entity.ErrCustomerNotFound, customerID)
+	}
+
+	err = change(tx)
+	if err != nil {
+		g.logger.Error("Failed to change customer details on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		g.logger.Error("Failed to commit customer details transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// validateIfRowWasAffected tells a missing customer apart from one whose version didn't match ifMatch
+func (g *customerGateway) validateIfRowWasAffected(ctx context.Context, result sql.Result, customerID string, ifMatch *int64) error {
+	rows, _ := result.RowsAffected()
+	if rows > 0 {
+		return nil
+	}
+	if ifMatch == nil {
+		return fmt.Errorf("%w with ID=%s", entity.ErrCustomerNotFound, customerID)
+	}
+
+	var exists bool
+	err := g.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM customers WHERE customer_id = $1 AND deleted_at IS NULL);", customerID).Scan(&exists)
+	if err != nil {
+		g.logger.Error("Failed to check customer version on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	if exists {
+		return fmt.Errorf("%w with ID=%s, expected version %d", entity.ErrCustomerVersionMismatch, customerID, *ifMatch)
+	}
+
+	return fmt.Errorf("%w with ID=%s", entity.ErrCustomerNotFound, customerID)
+}
+
+func buildCustomerListQuery(filter entity.CustomerFilter) (string, []interface{}, error) {
+	column, ok := customerSortColumns[filter.SortBy]
+	if !ok {
+		return "", nil, entity.InvalidField("sort_by", fmt.Sprintf("can't be %s", filter.SortBy))
+	}
+
+	comparator, direction := ">", "ASC"
+	if filter.SortOrder == "desc" {
+		comparator, direction = "<", "DESC"
+	}
+
+	conditions := []string{"deleted_at IS NULL"}
+	args := make([]interface{}, 0)
+	addCondition := func(condition string, values ...interface{}) {
+		placeholders := make([]interface{}, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf(condition, placeholders...))
+	}
+
+	// lower() on both sides, so the prefix can be looked up in the text_pattern_ops indexes
+	if filter.Name != "" {
+		addCondition("lower(name) LIKE lower(%s)", likePrefix(filter.Name))
+	}
+	if filter.Surname != "" {
+		addCondition("lower(surname) LIKE lower(%s)", likePrefix(filter.Surname))
+	}
+	if filter.EmailDomain != "" {
+		addCondition("lower(split_part(email, '@', 2)) = lower(%s)", filter.EmailDomain)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= %s", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("created_at <= %s", *filter.CreatedTo)
+	}
+	if filter.CreatedBefore != nil {
+		addCondition("created_at < %s", *filter.CreatedBefore)
+	}
+	// the age is counted in the DB so it follows its calendar day, like the birthday listing
+	if filter.MinAge != nil {
+		addCondition("birthdate <= CURRENT_DATE - make_interval(years => %s)", *filter.MinAge)
+	}
+	if filter.MaxAge != nil {
+		addCondition("birthdate > CURRENT_DATE - make_interval(years => %s)", *filter.MaxAge+1)
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCustomerCursor(filter.Cursor, filter.SortBy, filter.SortOrder)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if column == "customer_id" {
+			addCondition("customer_id "+comparator+" %s", cursor.ID)
+		} else if column == "created_at" {
+			createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+			if err != nil {
+				return "", nil, entity.InvalidField("cursor", "is malformed")
+			}
+			addCondition("(created_at, customer_id) "+comparator+" (%s, %s)", createdAt, cursor.ID)
+		} else {
+			addCondition("("+column+", customer_id) "+comparator+" (%s, %s)", cursor.Value, cursor.ID)
+		}
+	}
+
+	query := "SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE " + strings.Join(conditions, " AND ")
+
+	orderBy := fmt.Sprintf("%s %s", column, direction)
+	if column != "customer_id" {
+		orderBy += fmt.Sprintf(", customer_id %s", direction)
+	}
+
+	query += " ORDER BY " + orderBy
+	// One extra row tells whether there is a next page, the export has no limit and reads every row
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit+1)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	return query + ";", args, nil
+}
+
+// buildCustomerPatchQuery only sets the columns present in the patch
+func buildCustomerPatchQuery(patch entity.CustomerPatch, ifMatch *int64) (string, []interface{}) {
+	assignments := make([]string, 0)
+	args := make([]interface{}, 0)
+	set := func(column string, value *string) {
+		if value != nil {
+			args = append(args, *value)
+			assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+	}
+
+	set("name", patch.Name)
+	set("surname", patch.Surname)
+	set("email", patch.Email)
+	if patch.Birthdate != nil {
+		args = append(args, *patch.Birthdate)
+		assignments = append(assignments, fmt.Sprintf("birthdate = $%d", len(args)))
+	}
+	if patch.CPF != nil {
+		// an empty CPF is the patch removing it
+		args = append(args, *patch.CPF)
+		assignments = append(assignments, fmt.Sprintf("cpf = NULLIF($%d, '')", len(args)))
+	}
+	assignments = append(assignments, "updated_at = 'NOW()'", "version = version + 1")
+
+	args = append(args, patch.ID)
+	query := fmt.Sprintf("UPDATE customers SET %s WHERE customer_id = $%d AND deleted_at IS NULL", strings.Join(assignments, ", "), len(args))
+	if ifMatch != nil {
+		args = append(args, *ifMatch)
+		query += fmt.Sprintf(" AND version = $%d", len(args))
+	}
+	query += ";"
+
+	return query, args
+}
+
+// buildUpcomingBirthdaysQuery moves the birthdate to the current year, or to the next one when
+// it already passed. Adding years to February 29 lands on February 28 in common years.
+func buildUpcomingBirthdaysQuery(filter entity.BirthdayFilter) (string, []interface{}, error) {
+	args := []interface{}{filter.Days, filter.Limit + 1}
+	after := ""
+	if filter.Cursor != "" {
+		cursor, err := decodeCustomerCursor(filter.Cursor, birthdayCursorSort, "asc")
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, cursor.Value, cursor.ID)
+		after = " AND (b.next_birthday, customer_id) > ($3::date, $4)"
+	}
+
+	query := "SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version, b.next_birthday FROM customers," +
+		" LATERAL (SELECT (date_part('year', CURRENT_DATE) - date_part('year', birthdate))::int AS years) y," +
+		" LATERAL (SELECT (CASE WHEN birthdate + make_interval(years => y.years) < CURRENT_DATE" +
+		" THEN birthdate + make_interval(years => y.years + 1) ELSE birthdate + make_interval(years => y.years) END)::date AS next_birthday) b" +
+		" WHERE deleted_at IS NULL AND birthdate IS NOT NULL AND b.next_birthday < CURRENT_DATE + $1::int" + after +
+		" ORDER BY b.next_birthday, customer_id LIMIT $2;"
+
+	return query, args, nil
+}
+
+// buildCustomerSearchQuery ranks customers by how well the query matches a word sequence of their
+// name, surname and email, ignoring case and accents. <% is backed by the trigram index.
+// buildCustomerSearchQuery pages by keyset on (score, customer_id), both descending so they can be
+// compared as a row. The score is a real, the one of the cursor is cast back to it to match exactly.
+func buildCustomerSearchQuery(search entity.CustomerSearch) (string, []interface{}, error) {
+	// One extra row tells whether there is a next page
+	args := []interface{}{search.Query, search.Limit + 1}
+	after := ""
+	if search.Cursor != "" {
+		cursor, err := decodeCustomerSearchCursor(search.Cursor, search.Query)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, cursor.Score, cursor.ID)
+		after = fmt.Sprintf(" AND (word_similarity(q.term, %s), customer_id) < ($3::real, $4)", customerSearchDocument)
+	}
+
+	query := fmt.Sprintf("SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version, word_similarity(q.term, %[1]s) AS score"+
+		" FROM customers, (SELECT immutable_unaccent(lower($1)) AS term) q"+
+		" WHERE deleted_at IS NULL AND q.term <%% %[1]s%[2]s"+
+		" ORDER BY score DESC, customer_id DESC LIMIT $2;", customerSearchDocument, after)
+
+	return query, args, nil
+}
+
+func cursorFromCustomer(customer *entity.Customer, filter entity.CustomerFilter) customerCursor {
+	cursor := customerCursor{
+		SortBy:    filter.SortBy,
+		SortOrder: filter.SortOrder,
+		ID:        *customer.ID,
+	}
+
+	switch filter.SortBy {
+	case "name":
+		cursor.Value = customer.Name
+	case "surname":
+		cursor.Value = customer.Surname
+	case "email":
+		cursor.Value = customer.Email
+	case "created_at":
+		cursor.Value = customer.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return cursor
+}
+
+func likePrefix(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(value) + "%"
+}
@@ -5,9 +5,14 @@ import (
 	"cmd/customer-service/mocks"
 	"cmd/customer-service/test"
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgconn"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -15,26 +20,28 @@ var customerGtw = new(mocks.CustomerGateway)
 
 func Test_CustomerGtw_GetCustomerList(t *testing.T) {
 	type args struct {
-		ctx context.Context
+		ctx    context.Context
+		filter entity.CustomerFilter
 	}
 
 	scenarios := []struct {
 		name        string
 		args        args
-		want        []*entity.Customer
+		want        *entity.CustomerPage
 		expectedErr error
 	}{
-		{"success", args{context.Background()}, test.ACustomerArray, nil},
-		{"error", args{context.TODO()}, nil, errors.New("error")},
+		{"success", args{context.Background(), entity.CustomerFilter{Limit: 2}}, test.ACustomerPage, nil},
+		{"next page", args{context.Background(), entity.CustomerFilter{Limit: 2, Cursor: test.NextCursor}}, test.ALastCustomerPage, nil},
+		{"error", args{context.TODO(), entity.CustomerFilter{SortBy: "invalid"}}, nil, errors.New("error")},
 	}
 
 	for _, tt := range scenarios {
 		tt := tt
 
-		customerGtw.On("GetCustomerList", tt.args.ctx).Return(tt.want, tt.expectedErr)
+		customerGtw.On("GetCustomerList", tt.args.ctx, tt.args.filter).Return(tt.want, tt.expectedErr)
 
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := customerGtw.GetCustomerList(tt.args.ctx)
+			got, err := customerGtw.GetCustomerList(tt.args.ctx, tt.args.filter)
 
 			assert.ErrorIs(t, err, tt.expectedErr)
 			assert.Equal(t, tt.want, got)
@@ -114,6 +121,7 @@ func Test_CustomerGtw_UpdateCustomer(t *testing.T) {
 	type args struct {
 		ctx      context.Context
 		customer entity.Customer
+		ifMatch  *int64
 	}
 
 	aCustomerNotFound := &entity.Customer{
@@ -127,17 +135,18 @@ func Test_CustomerGtw_UpdateCustomer(t *testing.T) {
 		args        args
 		expectedErr error
 	}{
-		{"success", args{context.Background(), *test.ACustomer}, nil},
-		{"error", args{context.Background(), *aCustomerNotFound}, errors.New("not found")},
+		{"success", args{context.Background(), *test.ACustomer, nil}, nil},
+		{"error", args{context.Background(), *aCustomerNotFound, nil}, errors.New("not found")},
+		{"version mismatch", args{context.Background(), *test.ACustomer, &test.StaleVersion}, entity.ErrCustomerVersionMismatch},
 	}
 
 	for _, tt := range scenarios {
 		tt := tt
 
-		customerGtw.On("UpdateCustomer", tt.args.ctx, tt.args.customer).Return(tt.expectedErr)
+		customerGtw.On("UpdateCustomer", tt.args.ctx, tt.args.customer, tt.args.ifMatch).Return(tt.expectedErr)
 
 		t.Run(tt.name, func(t *testing.T) {
-			err := customerGtw.UpdateCustomer(tt.args.ctx, tt.args.customer)
+			err := customerGtw.UpdateCustomer(tt.args.ctx, tt.args.customer, tt.args.ifMatch)
 
 			assert.ErrorIs(t, err, tt.expectedErr)
 			customerGtw.AssertExpectations(t)
@@ -146,6 +155,37 @@ func Test_CustomerGtw_UpdateCustomer(t *testing.T) {
 }
 
 func Test_CustomerGtw_DeleteCustomerByID(t *testing.T) {
+	type args struct {
+		ctx        context.Context
+		customerID string
+		ifMatch    *int64
+	}
+
+	scenarios := []struct {
+		name        string
+		args        args
+		expectedErr error
+	}{
+		{"success", args{context.Background(), test.CustomerID, nil}, nil},
+		{"error", args{context.Background(), "errorID", nil}, errors.New("not found")},
+		{"version mismatch", args{context.Background(), test.CustomerID, &test.StaleVersion}, entity.ErrCustomerVersionMismatch},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		customerGtw.On("DeleteCustomerByID", tt.args.ctx, tt.args.customerID, tt.args.ifMatch).Return(tt.expectedErr)
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := customerGtw.DeleteCustomerByID(tt.args.ctx, tt.args.customerID, tt.args.ifMatch)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			customerGtw.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_CustomerGtw_RestoreCustomerByID(t *testing.T) {
 	type args struct {
 		ctx        context.Context
 		customerID string
@@ -157,19 +197,262 @@ func Test_CustomerGtw_DeleteCustomerByID(t *testing.T) {
 		expectedErr error
 	}{
 		{"success", args{context.Background(), test.CustomerID}, nil},
-		{"error", args{context.Background(), "errorID"}, errors.New("not found")},
+		{"not deleted", args{context.Background(), test.ErrorID}, entity.ErrCustomerNotFound},
 	}
 
 	for _, tt := range scenarios {
 		tt := tt
 
-		customerGtw.On("DeleteCustomerByID", tt.args.ctx, tt.args.customerID).Return(tt.expectedErr)
+		customerGtw.On("RestoreCustomerByID", tt.args.ctx, tt.args.customerID).Return(tt.expectedErr)
 
 		t.Run(tt.name, func(t *testing.T) {
-			err := customerGtw.DeleteCustomerByID(tt.args.ctx, tt.args.customerID)
+			err := customerGtw.RestoreCustomerByID(tt.args.ctx, tt.args.customerID)
 
 			assert.ErrorIs(t, err, tt.expectedErr)
 			customerGtw.AssertExpectations(t)
 		})
 	}
 }
+
+func Test_BuildCustomerListQuery(t *testing.T) {
+	createdFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nextDay := createdFrom.AddDate(0, 0, 1)
+	minAge, maxAge := 18, 30
+
+	scenarios := []struct {
+		name        string
+		filter      entity.CustomerFilter
+		wantQuery   string
+		wantArgs    []interface{}
+		expectedErr bool
+	}{
+		{
+			"first page sorted by id",
+			entity.CustomerFilter{Limit: 10, SortBy: "id", SortOrder: "asc"},
+			"SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE deleted_at IS NULL ORDER BY customer_id ASC LIMIT $1;",
+			[]interface{}{11},
+			false,
+		},
+		{
+			"filters and next page sorted by name desc",
+			entity.CustomerFilter{Limit: 5, Name: "Jo_", EmailDomain: "mock.com", CreatedFrom: &createdFrom, SortBy: "name", SortOrder: "desc",
+				Cursor: "eyJzIjoibmFtZSIsIm8iOiJkZXNjIiwidiI6IkpvaG4iLCJpZCI6ImN1c3RvbWVySUQifQ"},
+			"SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE deleted_at IS NULL AND lower(name) LIKE lower($1) AND lower(split_part(email, '@', 2)) = lower($2) AND created_at >= $3 AND (name, customer_id) < ($4, $5) ORDER BY name DESC, customer_id DESC LIMIT $6;",
+			[]interface{}{`Jo\_%`, "mock.com", createdFrom, "John", test.CustomerID, 6},
+			false,
+		},
+		{
+			"without limit, as exported",
+			entity.CustomerFilter{SortBy: "email", SortOrder: "desc"},
+			"SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE deleted_at IS NULL ORDER BY email DESC, customer_id DESC;",
+			[]interface{}{},
+			false,
+		},
+		{
+			"created on a date, the whole day included",
+			entity.CustomerFilter{Limit: 10, CreatedFrom: &createdFrom, CreatedBefore: &nextDay, SortBy: "id", SortOrder: "asc"},
+			"SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE deleted_at IS NULL AND created_at >= $1 AND created_at < $2 ORDER BY customer_id ASC LIMIT $3;",
+			[]interface{}{createdFrom, nextDay, 11},
+			false,
+		},
+		{
+			"age range",
+			entity.CustomerFilter{Limit: 10, MinAge: &minAge, MaxAge: &maxAge, SortBy: "id", SortOrder: "asc"},
+			"SELECT customer_id, name, surname, email, birthdate, cpf, created_at, updated_at, version FROM customers WHERE deleted_at IS NULL AND birthdate <= CURRENT_DATE - make_interval(years => $1) AND birthdate > CURRENT_DATE - make_interval(years => $2) ORDER BY customer_id ASC LIMIT $3;",
+			[]interface{}{18, 31, 11},
+			false,
+		},
+		{
+			"cursor issued for another sort",
+			entity.CustomerFilter{Limit: 5, SortBy: "email", SortOrder: "asc", Cursor: test.NextCursor},
+			"",
+			nil,
+			true,
+		},
+		{
+			"invalid sort field",
+			entity.CustomerFilter{Limit: 5, SortBy: "birthdate", SortOrder: "asc"},
+			"",
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := buildCustomerListQuery(tt.filter)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func Test_CustomerCursor_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 17, 13, 45, 12, 123456000, time.UTC)
+	customer := &entity.Customer{ID: &test.CustomerID, Name: "John", CreatedAt: createdAt}
+	filter := entity.CustomerFilter{SortBy: "created_at", SortOrder: "desc"}
+
+	encoded, err := encodeCustomerCursor(cursorFromCustomer(customer, filter))
+	assert.NoError(t, err)
+
+	decoded, err := decodeCustomerCursor(encoded, filter.SortBy, filter.SortOrder)
+	assert.NoError(t, err)
+	assert.Equal(t, test.CustomerID, decoded.ID)
+	assert.Equal(t, createdAt.Format(time.RFC3339Nano), decoded.Value)
+}
+
+func Test_BuildUpcomingBirthdaysQuery(t *testing.T) {
+	nextCursor, err := encodeCustomerCursor(customerCursor{SortBy: birthdayCursorSort, SortOrder: "asc", Value: "2024-05-17", ID: test.CustomerID})
+	assert.NoError(t, err)
+
+	scenarios := []struct {
+		name        string
+		filter      entity.BirthdayFilter
+		wantArgs    []interface{}
+		expectedErr bool
+	}{
+		{"first page", entity.BirthdayFilter{Days: 7, Limit: 50}, []interface{}{7, 51}, false},
+		{"next page", entity.BirthdayFilter{Days: 7, Limit: 50, Cursor: nextCursor}, []interface{}{7, 51, "2024-05-17", test.CustomerID}, false},
+		{"cursor issued for the list", entity.BirthdayFilter{Days: 7, Limit: 50, Cursor: test.NextCursor}, nil, true},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := buildUpcomingBirthdaysQuery(tt.filter)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			assert.Equal(t, tt.wantArgs, args)
+			if !tt.expectedErr {
+				assert.Equal(t, tt.filter.Cursor != "", strings.Contains(query, "(b.next_birthday, customer_id) > ($3::date, $4)"))
+			}
+		})
+	}
+}
+
+func Test_BuildCustomerImportQuery(t *testing.T) {
+	first, second := "01J0000000000000000000000A", "01J0000000000000000000000B"
+	cpf := "52998224725"
+	customers := []*entity.Customer{
+		{ID: &first, Name: "John", Surname: "Doe", Email: "john@example.com", Birthdate: entity.NewDate(1990, time.March, 7)},
+		{ID: &second, Name: "Jane", Surname: "Doe", Email: "jane@example.com", Birthdate: entity.NewDate(1991, time.May, 17), CPF: &cpf},
+	}
+
+	query, args := buildCustomerImportQuery(customers)
+
+	assert.Equal(t, "INSERT INTO customers (customer_id, name, surname, email, birthdate, cpf, created_at) VALUES"+
+		" ($1, $2, $3, $4, $5, $6, 'NOW()'), ($7, $8, $9, $10, $11, $12, 'NOW()') ON CONFLICT DO NOTHING RETURNING customer_id;", query)
+	assert.Equal(t, []interface{}{
+		first, "John", "Doe", "john@example.com", entity.NewDate(1990, time.March, 7), (*string)(nil),
+		second, "Jane", "Doe", "jane@example.com", entity.NewDate(1991, time.May, 17), &cpf,
+	}, args)
+}
+
+func Test_BuildCustomerSearchQuery(t *testing.T) {
+	nextCursor, err := encodeCustomerSearchCursor(customerSearchCursor{Query: "joao", Score: 0.625, ID: test.CustomerID})
+	assert.NoError(t, err)
+	withoutID, err := encodeCustomerSearchCursor(customerSearchCursor{Query: "joao", Score: 0.625})
+	assert.NoError(t, err)
+	after := "(word_similarity(q.term, " + customerSearchDocument + "), customer_id) < ($3::real, $4)"
+
+	scenarios := []struct {
+		name        string
+		search      entity.CustomerSearch
+		wantArgs    []interface{}
+		wantAfter   bool
+		expectedErr bool
+	}{
+		{"first page", entity.CustomerSearch{Query: "joao", Limit: 20}, []interface{}{"joao", 21}, false, false},
+		{"next page", entity.CustomerSearch{Query: "joao", Limit: 20, Cursor: nextCursor}, []interface{}{"joao", 21, 0.625, test.CustomerID}, true, false},
+		{"cursor issued for another query", entity.CustomerSearch{Query: "maria", Limit: 20, Cursor: nextCursor}, nil, false, true},
+		{"cursor without ID", entity.CustomerSearch{Query: "joao", Limit: 20, Cursor: withoutID}, nil, false, true},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := buildCustomerSearchQuery(tt.search)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			if !tt.expectedErr {
+				assert.Contains(t, query, "q.term <% "+customerSearchDocument)
+				assert.Contains(t, query, "ORDER BY score DESC, customer_id DESC LIMIT $2;")
+				assert.Equal(t, tt.wantAfter, strings.Contains(query, after))
+				assert.Equal(t, tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func Test_BuildCustomerPatchQuery(t *testing.T) {
+	email, birthdate := "john.doe@mock.com", entity.NewDate(1990, time.March, 7)
+
+	scenarios := []struct {
+		name      string
+		patch     entity.CustomerPatch
+		ifMatch   *int64
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			"single field",
+			entity.CustomerPatch{ID: test.CustomerID, Birthdate: &birthdate},
+			nil,
+			"UPDATE customers SET birthdate = $1, updated_at = 'NOW()', version = version + 1 WHERE customer_id = $2 AND deleted_at IS NULL;",
+			[]interface{}{birthdate, test.CustomerID},
+		},
+		{
+			"several fields with if-match",
+			entity.CustomerPatch{ID: test.CustomerID, Email: &email, Birthdate: &birthdate},
+			&test.StaleVersion,
+			"UPDATE customers SET email = $1, birthdate = $2, updated_at = 'NOW()', version = version + 1 WHERE customer_id = $3 AND deleted_at IS NULL AND version = $4;",
+			[]interface{}{email, birthdate, test.CustomerID, test.StaleVersion},
+		},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := buildCustomerPatchQuery(tt.patch, tt.ifMatch)
+
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func Test_TranslateError(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		err         error
+		expectedErr error
+		wantMessage string
+	}{
+		{"email in use", &pgconn.PgError{Code: "23505", ConstraintName: "customers_email_active_idx", Message: "duplicate key value"}, entity.ErrCustomerConflict, "customer conflicts with an existing one, email is already in use"},
+		{"unknown unique constraint", &pgconn.PgError{Code: "23505", ConstraintName: "other_idx", Message: "duplicate key value"}, entity.ErrCustomerConflict, "customer conflicts with an existing one"},
+		{"server shutting down", &pgconn.PgError{Code: "57P01", Message: "terminating connection"}, entity.ErrUnavailable, ""},
+		{"bad connection", driver.ErrBadConn, entity.ErrUnavailable, ""},
+		{"timeout", context.DeadlineExceeded, entity.ErrUnavailable, ""},
+		{"other", sql.ErrTxDone, sql.ErrTxDone, ""},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(tt.err)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, err.Error())
+			}
+		})
+	}
+}
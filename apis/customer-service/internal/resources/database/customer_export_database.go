@@ -0,0 +1,78 @@
+package database
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// exportFetchSize rows are fetched from the export cursor at a time, it bounds the memory an export holds
+const exportFetchSize = 1000
+
+// ExportCustomers walks the customers matching the filter through a server side cursor and hands them to
+// write one at a time. The limit and cursor of the filter are ignored, every matching customer is exported.
+func (g *customerGateway) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, write func(customer *entity.Customer) error) error {
+	g.logger.Debug("Exporting customers from db", "filter", filter, "traceID", ctx.Value("traceID"))
+	filter.Limit, filter.Cursor = 0, ""
+	query, args, err := buildCustomerListQuery(filter)
+	if err != nil {
+		g.logger.Error("Failed to build customer export query", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "ExportCustomers", "")
+
+	// a cursor only lives inside a transaction, which also gives the export a single snapshot
+	tx, err := g.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		g.logger.Error("Failed to begin export transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DECLARE customer_export NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		g.logger.Error("Failed to declare customer export cursor", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	for {
+		fetched, err := g.fetchCustomerExport(ctx, tx, write)
+		if err != nil {
+			return err
+		}
+		if fetched < exportFetchSize {
+			return nil
+		}
+	}
+}
+
+func (g *customerGateway) fetchCustomerExport(ctx context.Context, tx *sql.Tx, write func(customer *entity.Customer) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("FETCH %d FROM customer_export;", exportFetchSize))
+	if err != nil {
+		g.logger.Error("Failed to fetch customer export rows", "error", err, "traceID", ctx.Value("traceID"))
+		return 0, translateError(err)
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		customer := &entity.Customer{}
+		err = rows.Scan(&customer.ID, &customer.Name, &customer.Surname, &customer.Email, &customer.Birthdate, &customer.CPF, &customer.CreatedAt, &customer.UpdatedAt, &customer.Version)
+		if err != nil {
+			g.logger.Error("Error scaning row", "error", err, "traceID", ctx.Value("traceID"))
+			return 0, err
+		}
+		if err = write(customer); err != nil {
+			return 0, err
+		}
+		fetched++
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating customer export rows", "error", err, "traceID", ctx.Value("traceID"))
+		return 0, translateError(err)
+	}
+
+	return fetched, nil
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// customerImportColumns are bound for every imported customer, created_at is shared by the whole batch
+const customerImportColumns = 6
+
+// ImportCustomers inserts the customers in a single multi-row INSERT and transaction, which is rolled back on
+// dry runs. The customers that would break a uniqueness rule are skipped instead of failing the batch,
+// errs has the conflict of each skipped one at its index. The IDs of the others are set on them.
+func (g *customerGateway) ImportCustomers(ctx context.Context, customers []*entity.Customer, dryRun bool) ([]error, error) {
+	g.logger.Debug("Importing customers into db", "count", len(customers), "dryRun", dryRun, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "ImportCustomers", "")
+
+	for _, customer := range customers {
+		id := ulid.Make().String()
+		customer.ID = &id
+	}
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin import transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	inserted, err := g.insertImportedCustomers(ctx, tx, customers)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(customers))
+	if len(inserted) < len(customers) {
+		// the email is checked after the insert, so a customer repeated in the batch itself conflicts with its first row
+		takenEmails, err := g.getTakenEmails(ctx, tx, customers, inserted)
+		if err != nil {
+			return nil, err
+		}
+		for i, customer := range customers {
+			if inserted[*customer.ID] {
+				continue
+			}
+			customer.ID = nil
+			errs[i] = conflictError("customers_cpf_active_idx")
+			if takenEmails[customer.Email] {
+				errs[i] = conflictError("customers_email_active_idx")
+			}
+		}
+	}
+
+	if dryRun {
+		return errs, nil
+	}
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit import transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return errs, nil
+}
+
+// insertImportedCustomers closes its rows before returning, the transaction can't run anything else while they are open
+func (g *customerGateway) insertImportedCustomers(ctx context.Context, tx *sql.Tx, customers []*entity.Customer) (map[string]bool, error) {
+	query, args := buildCustomerImportQuery(customers)
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		g.logger.Error("Failed to import customers into db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	inserted := make(map[string]bool, len(customers))
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			g.logger.Error("Error scaning row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		inserted[id] = true
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating imported customer rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return inserted, nil
+}
+
+func (g *customerGateway) getTakenEmails(ctx context.Context, tx *sql.Tx, customers []*entity.Customer, inserted map[string]bool) (map[string]bool, error) {
+	emails := make([]string, 0, len(customers)-len(inserted))
+	for _, customer := range customers {
+		if !inserted[*customer.ID] {
+			emails = append(emails, customer.Email)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT email FROM customers WHERE deleted_at IS NULL AND email = ANY($1);", emails)
+	if err != nil {
+		g.logger.Error("Failed to get the emails in use", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool, len(emails))
+	for rows.Next() {
+		var email string
+		if err = rows.Scan(&email); err != nil {
+			g.logger.Error("Error scaning row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		taken[email] = true
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating email rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return taken, nil
+}
+
+// buildCustomerImportQuery skips the rows breaking any unique index, including the partial ones of the
+// active customers, and returns the IDs of the inserted ones
+func buildCustomerImportQuery(customers []*entity.Customer) (string, []interface{}) {
+	values := make([]string, len(customers))
+	args := make([]interface{}, 0, len(customers)*customerImportColumns)
+	for i, customer := range customers {
+		n := i * customerImportColumns
+		values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, 'NOW()')", n+1, n+2, n+3, n+4, n+5, n+6)
+		args = append(args, *customer.ID, customer.Name, customer.Surname, customer.Email, customer.Birthdate, customer.CPF)
+	}
+
+	query := "INSERT INTO customers (customer_id, name, surname, email, birthdate, cpf, created_at) VALUES " +
+		strings.Join(values, ", ") + " ON CONFLICT DO NOTHING RETURNING customer_id;"
+
+	return query, args
+}
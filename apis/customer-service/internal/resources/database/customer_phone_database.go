@@ -0,0 +1,129 @@
+package database
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// CreateCustomerPhone takes the primary flag from the current primary phone when asked to
+func (g *customerGateway) CreateCustomerPhone(ctx context.Context, phone entity.Phone) (*string, error) {
+	g.logger.Debug("Inserting customer phone into db", "customerID", phone.CustomerID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "CreateCustomerPhone", "")
+
+	id := ulid.Make().String()
+	err := g.changeCustomerDetails(ctx, phone.CustomerID, func(tx *sql.Tx) error {
+		if phone.IsPrimary {
+			err := clearPrimaryPhone(ctx, tx, phone.CustomerID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `INSERT INTO customer_phones (phone_id, customer_id, type, number, is_primary, created_at)
+			VALUES ($1, $2, $3, $4, $5, 'NOW()');`,
+			id,
+			phone.CustomerID,
+			phone.Type,
+			phone.Number,
+			phone.IsPrimary)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
+
+// UpdateCustomerPhone replaces a phone, unsetting its primary flag leaves the customer without one
+func (g *customerGateway) UpdateCustomerPhone(ctx context.Context, phone entity.Phone) error {
+	g.logger.Debug("Updating customer phone on db", "ID", phone.ID, "customerID", phone.CustomerID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "UpdateCustomerPhone", "")
+
+	return g.changeCustomerDetails(ctx, phone.CustomerID, func(tx *sql.Tx) error {
+		if phone.IsPrimary {
+			err := clearPrimaryPhone(ctx, tx, phone.CustomerID)
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, `UPDATE customer_phones SET type = $1, number = $2, is_primary = $3, updated_at = 'NOW()'
+			WHERE phone_id = $4 AND customer_id = $5;`,
+			phone.Type,
+			phone.Number,
+			phone.IsPrimary,
+			phone.ID,
+			phone.CustomerID)
+		if err != nil {
+			return err
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return fmt.Errorf("%w with ID=%s", entity.ErrPhoneNotFound, *phone.ID)
+		}
+		return nil
+	})
+}
+
+func (g *customerGateway) DeleteCustomerPhone(ctx context.Context, customerID string, phoneID string) error {
+	g.logger.Debug("Deleting customer phone on db", "ID", phoneID, "customerID", customerID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "DeleteCustomerPhone", "")
+
+	return g.changeCustomerDetails(ctx, customerID, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM customer_phones WHERE phone_id = $1 AND customer_id = $2;", phoneID, customerID)
+		if err != nil {
+			return err
+		}
+
+		rows, _ := result.RowsAffected()
+		if rows == 0 {
+			return fmt.Errorf("%w with ID=%s", entity.ErrPhoneNotFound, phoneID)
+		}
+		return nil
+	})
+}
+
+func clearPrimaryPhone(ctx context.Context, tx *sql.Tx, customerID string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE customer_phones SET is_primary = false, updated_at = 'NOW()' WHERE customer_id = $1 AND is_primary;", customerID)
+	return err
+}
+
+// getCustomerPhones loads the phones of a customer, the primary one first
+func (g *customerGateway) getCustomerPhones(ctx context.Context, customerID string) ([]*entity.Phone, error) {
+	start := time.Now()
+	rows, err := g.db.QueryContext(ctx, `SELECT phone_id, customer_id, type, number, is_primary, created_at, updated_at
+		FROM customer_phones WHERE customer_id = $1 ORDER BY is_primary DESC, created_at, phone_id;`, customerID)
+	g.metrics.MeasureExternalDuration(start, "database", "CustomerDB", "GetCustomerPhones", "")
+	if err != nil {
+		g.logger.Error("Failed to get customer phones from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	phones := make([]*entity.Phone, 0)
+	for rows.Next() {
+		phone := &entity.Phone{}
+		err = rows.Scan(&phone.ID, &phone.CustomerID, &phone.Type, &phone.Number, &phone.IsPrimary, &phone.CreatedAt, &phone.UpdatedAt)
+		if err != nil {
+			g.logger.Error("Error scaning phone row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		phones = append(phones, phone)
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating phone rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return phones, nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"cmd/customer-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgconn"
+)
+
+const uniqueViolation = "23505"
+
+// customerConstraintConflicts tells clients which rule a write broke, without the driver message
+var customerConstraintConflicts = map[string]string{
+	"customers_email_active_idx": "email is already in use",
+	"customers_cpf_active_idx":   "cpf is already in use",
+	"customer_pk":                "customer ID is already in use",
+	"customer_address_pk":        "address ID is already in use",
+	"customer_phone_pk":          "phone ID is already in use",
+	"customer_phones_number_idx": "phone number is already in use",
+}
+
+// conflictError tells which uniqueness rule was broken, when the constraint is a known one
+func conflictError(constraint string) error {
+	detail, ok := customerConstraintConflicts[constraint]
+	if !ok {
+		return entity.ErrCustomerConflict
+	}
+	return fmt.Errorf("%w, %s", entity.ErrCustomerConflict, detail)
+}
+
+// translateError turns driver errors into domain errors so their text never reaches clients.
+// Callers log the raw error before translating it.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == uniqueViolation:
+			return conflictError(pgErr.ConstraintName)
+		// connection exceptions, insufficient resources and server shutdowns
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "53"), strings.HasPrefix(pgErr.Code, "57P"):
+			return fmt.Errorf("%w: %w", entity.ErrUnavailable, err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || pgconn.Timeout(err) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", entity.ErrUnavailable, err)
+	}
+
+	return err
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	dbschema "cmd/customer-service/db-schema"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	scenarios := []struct {
+		name        string
+		fsys        fstest.MapFS
+		want        []Migration
+		expectedErr bool
+	}{
+		{
+			"sorted by version, not by name",
+			fstest.MapFS{
+				"10_add_b.up.sql":     file("up 10"),
+				"10_add_b.down.sql":   file("down 10"),
+				"2_add_a.up.sql":      file("up 2"),
+				"2_add_a.down.sql":    file("down 2"),
+				"schema.go":           file("package dbschema"),
+				"README.md":           file("docs"),
+				"1_create_x.up.sql":   file("up 1"),
+				"1_create_x.down.sql": file("down 1"),
+			},
+			[]Migration{
+				{Version: 1, Name: "create_x", Up: "up 1", Down: "down 1"},
+				{Version: 2, Name: "add_a", Up: "up 2", Down: "down 2"},
+				{Version: 10, Name: "add_b", Up: "up 10", Down: "down 10"},
+			},
+			false,
+		},
+		{"missing down", fstest.MapFS{"1_create_x.up.sql": file("up 1")}, nil, true},
+		{
+			"version used twice",
+			fstest.MapFS{
+				"1_create_x.up.sql":   file("up 1"),
+				"1_create_x.down.sql": file("down 1"),
+				"1_create_y.up.sql":   file("up 1"),
+				"1_create_y.down.sql": file("down 1"),
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadMigrations(tt.fsys)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_LoadMigrations_Embedded(t *testing.T) {
+	migrations, err := loadMigrations(dbschema.Migrations)
+
+	assert.NoError(t, err)
+	for i, migration := range migrations {
+		// versions are contiguous, a gap usually means a file was renamed by mistake
+		assert.Equal(t, i+1, migration.Version)
+	}
+}
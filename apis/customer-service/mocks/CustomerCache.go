@@ -3,9 +3,11 @@
 package mocks
 
 import (
-	entity "cmd/customer-service/internal/domain/entity"
+	cache "cmd/customer-service/internal/resources/cache"
 	context "context"
 
+	entity "cmd/customer-service/internal/domain/entity"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -14,24 +16,108 @@ type CustomerCache struct {
 	mock.Mock
 }
 
+// EvictCache provides a mock function with given fields: ctx, customer
+func (_m *CustomerCache) EvictCache(ctx context.Context, customer entity.Customer) error {
+	ret := _m.Called(ctx, customer)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvictCache")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Customer) error); ok {
+		r0 = rf(ctx, customer)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EvictCacheBatch provides a mock function with given fields: ctx, customers
+func (_m *CustomerCache) EvictCacheBatch(ctx context.Context, customers []entity.Customer) error {
+	ret := _m.Called(ctx, customers)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvictCacheBatch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.Customer) error); ok {
+		r0 = rf(ctx, customers)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EvictCacheList provides a mock function with given fields: ctx
+func (_m *CustomerCache) EvictCacheList(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvictCacheList")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReadCacheByDocument provides a mock function with given fields: ctx, cpf
+func (_m *CustomerCache) ReadCacheByDocument(ctx context.Context, cpf string) (*cache.CachedCustomer, error) {
+	ret := _m.Called(ctx, cpf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadCacheByDocument")
+	}
+
+	var r0 *cache.CachedCustomer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*cache.CachedCustomer, error)); ok {
+		return rf(ctx, cpf)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *cache.CachedCustomer); ok {
+		r0 = rf(ctx, cpf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*cache.CachedCustomer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, cpf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadCacheByEmail provides a mock function with given fields: ctx, customerEmail
-func (_m *CustomerCache) ReadCacheByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error) {
+func (_m *CustomerCache) ReadCacheByEmail(ctx context.Context, customerEmail string) (*cache.CachedCustomer, error) {
 	ret := _m.Called(ctx, customerEmail)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ReadCacheByEmail")
 	}
 
-	var r0 *entity.Customer
+	var r0 *cache.CachedCustomer
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Customer, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*cache.CachedCustomer, error)); ok {
 		return rf(ctx, customerEmail)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Customer); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) *cache.CachedCustomer); ok {
 		r0 = rf(ctx, customerEmail)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.Customer)
+			r0 = ret.Get(0).(*cache.CachedCustomer)
 		}
 	}
 
@@ -45,23 +131,23 @@ func (_m *CustomerCache) ReadCacheByEmail(ctx context.Context, customerEmail str
 }
 
 // ReadCacheByID provides a mock function with given fields: ctx, customerID
-func (_m *CustomerCache) ReadCacheByID(ctx context.Context, customerID string) (*entity.Customer, error) {
+func (_m *CustomerCache) ReadCacheByID(ctx context.Context, customerID string) (*cache.CachedCustomer, error) {
 	ret := _m.Called(ctx, customerID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ReadCacheByID")
 	}
 
-	var r0 *entity.Customer
+	var r0 *cache.CachedCustomer
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Customer, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*cache.CachedCustomer, error)); ok {
 		return rf(ctx, customerID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Customer); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) *cache.CachedCustomer); ok {
 		r0 = rf(ctx, customerID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.Customer)
+			r0 = ret.Get(0).(*cache.CachedCustomer)
 		}
 	}
 
@@ -75,23 +161,23 @@ func (_m *CustomerCache) ReadCacheByID(ctx context.Context, customerID string) (
 }
 
 // ReadCacheByName provides a mock function with given fields: ctx, customerName
-func (_m *CustomerCache) ReadCacheByName(ctx context.Context, customerName string) (*entity.Customer, error) {
+func (_m *CustomerCache) ReadCacheByName(ctx context.Context, customerName string) (*cache.CachedCustomer, error) {
 	ret := _m.Called(ctx, customerName)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ReadCacheByName")
 	}
 
-	var r0 *entity.Customer
+	var r0 *cache.CachedCustomer
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Customer, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*cache.CachedCustomer, error)); ok {
 		return rf(ctx, customerName)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Customer); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) *cache.CachedCustomer); ok {
 		r0 = rf(ctx, customerName)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.Customer)
+			r0 = ret.Get(0).(*cache.CachedCustomer)
 		}
 	}
 
@@ -104,12 +190,42 @@ func (_m *CustomerCache) ReadCacheByName(ctx context.Context, customerName strin
 	return r0, r1
 }
 
-// WriteCacheByEmail provides a mock function with given fields: ctx, customer
-func (_m *CustomerCache) WriteCacheByEmail(ctx context.Context, customer entity.Customer) error {
+// ReadCacheList provides a mock function with given fields: ctx, filter
+func (_m *CustomerCache) ReadCacheList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadCacheList")
+	}
+
+	var r0 *entity.CustomerPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerFilter) (*entity.CustomerPage, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerFilter) *entity.CustomerPage); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.CustomerPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.CustomerFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefreshCache provides a mock function with given fields: ctx, customer
+func (_m *CustomerCache) RefreshCache(ctx context.Context, customer entity.Customer) error {
 	ret := _m.Called(ctx, customer)
 
 	if len(ret) == 0 {
-		panic("no return value specified for WriteCacheByEmail")
+		panic("no return value specified for RefreshCache")
 	}
 
 	var r0 error
@@ -122,17 +238,53 @@ func (_m *CustomerCache) WriteCacheByEmail(ctx context.Context, customer entity.
 	return r0
 }
 
-// WriteCacheByID provides a mock function with given fields: ctx, customer
-func (_m *CustomerCache) WriteCacheByID(ctx context.Context, customer entity.Customer) error {
-	ret := _m.Called(ctx, customer)
+// WriteCacheByDocument provides a mock function with given fields: ctx, cpf, customer, stale
+func (_m *CustomerCache) WriteCacheByDocument(ctx context.Context, cpf string, customer *entity.Customer, stale *cache.CachedCustomer) error {
+	ret := _m.Called(ctx, cpf, customer, stale)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteCacheByDocument")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *entity.Customer, *cache.CachedCustomer) error); ok {
+		r0 = rf(ctx, cpf, customer, stale)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteCacheByEmail provides a mock function with given fields: ctx, customerEmail, customer, stale
+func (_m *CustomerCache) WriteCacheByEmail(ctx context.Context, customerEmail string, customer *entity.Customer, stale *cache.CachedCustomer) error {
+	ret := _m.Called(ctx, customerEmail, customer, stale)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteCacheByEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *entity.Customer, *cache.CachedCustomer) error); ok {
+		r0 = rf(ctx, customerEmail, customer, stale)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteCacheByID provides a mock function with given fields: ctx, customerID, customer, stale
+func (_m *CustomerCache) WriteCacheByID(ctx context.Context, customerID string, customer *entity.Customer, stale *cache.CachedCustomer) error {
+	ret := _m.Called(ctx, customerID, customer, stale)
 
 	if len(ret) == 0 {
 		panic("no return value specified for WriteCacheByID")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, entity.Customer) error); ok {
-		r0 = rf(ctx, customer)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *entity.Customer, *cache.CachedCustomer) error); ok {
+		r0 = rf(ctx, customerID, customer, stale)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -140,17 +292,35 @@ func (_m *CustomerCache) WriteCacheByID(ctx context.Context, customer entity.Cus
 	return r0
 }
 
-// WriteCacheByName provides a mock function with given fields: ctx, customer
-func (_m *CustomerCache) WriteCacheByName(ctx context.Context, customer entity.Customer) error {
-	ret := _m.Called(ctx, customer)
+// WriteCacheByName provides a mock function with given fields: ctx, customerName, customer, stale
+func (_m *CustomerCache) WriteCacheByName(ctx context.Context, customerName string, customer *entity.Customer, stale *cache.CachedCustomer) error {
+	ret := _m.Called(ctx, customerName, customer, stale)
 
 	if len(ret) == 0 {
 		panic("no return value specified for WriteCacheByName")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, entity.Customer) error); ok {
-		r0 = rf(ctx, customer)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *entity.Customer, *cache.CachedCustomer) error); ok {
+		r0 = rf(ctx, customerName, customer, stale)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteCacheList provides a mock function with given fields: ctx, filter, page
+func (_m *CustomerCache) WriteCacheList(ctx context.Context, filter entity.CustomerFilter, page entity.CustomerPage) error {
+	ret := _m.Called(ctx, filter, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteCacheList")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerFilter, entity.CustomerPage) error); ok {
+		r0 = rf(ctx, filter, page)
 	} else {
 		r0 = ret.Error(0)
 	}
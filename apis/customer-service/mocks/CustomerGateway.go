@@ -44,17 +44,113 @@ func (_m *CustomerGateway) CreateCustomer(ctx context.Context, customer entity.C
 	return r0, r1
 }
 
-// DeleteCustomerByID provides a mock function with given fields: ctx, customerID
-func (_m *CustomerGateway) DeleteCustomerByID(ctx context.Context, customerID string) error {
-	ret := _m.Called(ctx, customerID)
+// CreateCustomerAddress provides a mock function with given fields: ctx, address
+func (_m *CustomerGateway) CreateCustomerAddress(ctx context.Context, address entity.Address) (*string, error) {
+	ret := _m.Called(ctx, address)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCustomerAddress")
+	}
+
+	var r0 *string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Address) (*string, error)); ok {
+		return rf(ctx, address)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Address) *string); ok {
+		r0 = rf(ctx, address)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Address) error); ok {
+		r1 = rf(ctx, address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateCustomerPhone provides a mock function with given fields: ctx, phone
+func (_m *CustomerGateway) CreateCustomerPhone(ctx context.Context, phone entity.Phone) (*string, error) {
+	ret := _m.Called(ctx, phone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCustomerPhone")
+	}
+
+	var r0 *string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Phone) (*string, error)); ok {
+		return rf(ctx, phone)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Phone) *string); ok {
+		r0 = rf(ctx, phone)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.Phone) error); ok {
+		r1 = rf(ctx, phone)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCustomerAddress provides a mock function with given fields: ctx, customerID, addressID
+func (_m *CustomerGateway) DeleteCustomerAddress(ctx context.Context, customerID string, addressID string) error {
+	ret := _m.Called(ctx, customerID, addressID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCustomerAddress")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, customerID, addressID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteCustomerByID provides a mock function with given fields: ctx, customerID, ifMatch
+func (_m *CustomerGateway) DeleteCustomerByID(ctx context.Context, customerID string, ifMatch *int64) error {
+	ret := _m.Called(ctx, customerID, ifMatch)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteCustomerByID")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, customerID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *int64) error); ok {
+		r0 = rf(ctx, customerID, ifMatch)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteCustomerPhone provides a mock function with given fields: ctx, customerID, phoneID
+func (_m *CustomerGateway) DeleteCustomerPhone(ctx context.Context, customerID string, phoneID string) error {
+	ret := _m.Called(ctx, customerID, phoneID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCustomerPhone")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, customerID, phoneID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -62,6 +158,72 @@ func (_m *CustomerGateway) DeleteCustomerByID(ctx context.Context, customerID st
 	return r0
 }
 
+// EraseCustomerByID provides a mock function with given fields: ctx, erasure
+func (_m *CustomerGateway) EraseCustomerByID(ctx context.Context, erasure entity.CustomerErasure) error {
+	ret := _m.Called(ctx, erasure)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseCustomerByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerErasure) error); ok {
+		r0 = rf(ctx, erasure)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportCustomers provides a mock function with given fields: ctx, filter, write
+func (_m *CustomerGateway) ExportCustomers(ctx context.Context, filter entity.CustomerFilter, write func(*entity.Customer) error) error {
+	ret := _m.Called(ctx, filter, write)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportCustomers")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerFilter, func(*entity.Customer) error) error); ok {
+		r0 = rf(ctx, filter, write)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCustomerByDocument provides a mock function with given fields: ctx, cpf
+func (_m *CustomerGateway) GetCustomerByDocument(ctx context.Context, cpf string) (*entity.Customer, error) {
+	ret := _m.Called(ctx, cpf)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomerByDocument")
+	}
+
+	var r0 *entity.Customer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Customer, error)); ok {
+		return rf(ctx, cpf)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Customer); ok {
+		r0 = rf(ctx, cpf)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, cpf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCustomerByEmail provides a mock function with given fields: ctx, customerEmail
 func (_m *CustomerGateway) GetCustomerByEmail(ctx context.Context, customerEmail string) (*entity.Customer, error) {
 	ret := _m.Called(ctx, customerEmail)
@@ -152,29 +314,59 @@ func (_m *CustomerGateway) GetCustomerByName(ctx context.Context, customerName s
 	return r0, r1
 }
 
-// GetCustomerList provides a mock function with given fields: ctx
-func (_m *CustomerGateway) GetCustomerList(ctx context.Context) ([]*entity.Customer, error) {
-	ret := _m.Called(ctx)
+// GetCustomerByPhone provides a mock function with given fields: ctx, number
+func (_m *CustomerGateway) GetCustomerByPhone(ctx context.Context, number string) (*entity.Customer, error) {
+	ret := _m.Called(ctx, number)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCustomerByPhone")
+	}
+
+	var r0 *entity.Customer
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Customer, error)); ok {
+		return rf(ctx, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Customer); ok {
+		r0 = rf(ctx, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Customer)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCustomerList provides a mock function with given fields: ctx, filter
+func (_m *CustomerGateway) GetCustomerList(ctx context.Context, filter entity.CustomerFilter) (*entity.CustomerPage, error) {
+	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetCustomerList")
 	}
 
-	var r0 []*entity.Customer
+	var r0 *entity.CustomerPage
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]*entity.Customer, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerFilter) (*entity.CustomerPage, error)); ok {
+		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []*entity.Customer); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerFilter) *entity.CustomerPage); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*entity.Customer)
+			r0 = ret.Get(0).(*entity.CustomerPage)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, entity.CustomerFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -182,17 +374,179 @@ func (_m *CustomerGateway) GetCustomerList(ctx context.Context) ([]*entity.Custo
 	return r0, r1
 }
 
-// UpdateCustomer provides a mock function with given fields: ctx, customer
-func (_m *CustomerGateway) UpdateCustomer(ctx context.Context, customer entity.Customer) error {
-	ret := _m.Called(ctx, customer)
+// GetUpcomingBirthdays provides a mock function with given fields: ctx, filter
+func (_m *CustomerGateway) GetUpcomingBirthdays(ctx context.Context, filter entity.BirthdayFilter) (*entity.BirthdayPage, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUpcomingBirthdays")
+	}
+
+	var r0 *entity.BirthdayPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.BirthdayFilter) (*entity.BirthdayPage, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.BirthdayFilter) *entity.BirthdayPage); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.BirthdayPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.BirthdayFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportCustomers provides a mock function with given fields: ctx, customers, dryRun
+func (_m *CustomerGateway) ImportCustomers(ctx context.Context, customers []*entity.Customer, dryRun bool) ([]error, error) {
+	ret := _m.Called(ctx, customers, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportCustomers")
+	}
+
+	var r0 []error
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*entity.Customer, bool) ([]error, error)); ok {
+		return rf(ctx, customers, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*entity.Customer, bool) []error); ok {
+		r0 = rf(ctx, customers, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*entity.Customer, bool) error); ok {
+		r1 = rf(ctx, customers, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchCustomer provides a mock function with given fields: ctx, patch, ifMatch
+func (_m *CustomerGateway) PatchCustomer(ctx context.Context, patch entity.CustomerPatch, ifMatch *int64) error {
+	ret := _m.Called(ctx, patch, ifMatch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchCustomer")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerPatch, *int64) error); ok {
+		r0 = rf(ctx, patch, ifMatch)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreCustomerByID provides a mock function with given fields: ctx, customerID
+func (_m *CustomerGateway) RestoreCustomerByID(ctx context.Context, customerID string) error {
+	ret := _m.Called(ctx, customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreCustomerByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, customerID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchCustomers provides a mock function with given fields: ctx, search
+func (_m *CustomerGateway) SearchCustomers(ctx context.Context, search entity.CustomerSearch) (*entity.CustomerSearchPage, error) {
+	ret := _m.Called(ctx, search)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchCustomers")
+	}
+
+	var r0 *entity.CustomerSearchPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerSearch) (*entity.CustomerSearchPage, error)); ok {
+		return rf(ctx, search)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.CustomerSearch) *entity.CustomerSearchPage); ok {
+		r0 = rf(ctx, search)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.CustomerSearchPage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.CustomerSearch) error); ok {
+		r1 = rf(ctx, search)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCustomer provides a mock function with given fields: ctx, customer, ifMatch
+func (_m *CustomerGateway) UpdateCustomer(ctx context.Context, customer entity.Customer, ifMatch *int64) error {
+	ret := _m.Called(ctx, customer, ifMatch)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateCustomer")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, entity.Customer) error); ok {
-		r0 = rf(ctx, customer)
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Customer, *int64) error); ok {
+		r0 = rf(ctx, customer, ifMatch)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCustomerAddress provides a mock function with given fields: ctx, address
+func (_m *CustomerGateway) UpdateCustomerAddress(ctx context.Context, address entity.Address) error {
+	ret := _m.Called(ctx, address)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCustomerAddress")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Address) error); ok {
+		r0 = rf(ctx, address)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCustomerPhone provides a mock function with given fields: ctx, phone
+func (_m *CustomerGateway) UpdateCustomerPhone(ctx context.Context, phone entity.Phone) error {
+	ret := _m.Called(ctx, phone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCustomerPhone")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.Phone) error); ok {
+		r0 = rf(ctx, phone)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -18,11 +18,56 @@ func (_m *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request
 	_m.Called(w, r)
 }
 
+// CreateCustomerAddress provides a mock function with given fields: w, r
+func (_m *CustomerHandler) CreateCustomerAddress(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// CreateCustomerPhone provides a mock function with given fields: w, r
+func (_m *CustomerHandler) CreateCustomerPhone(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
 // DeleteCustomer provides a mock function with given fields: w, r
 func (_m *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
 }
 
+// DeleteCustomerAddress provides a mock function with given fields: w, r
+func (_m *CustomerHandler) DeleteCustomerAddress(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// DeleteCustomerPhone provides a mock function with given fields: w, r
+func (_m *CustomerHandler) DeleteCustomerPhone(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// EraseCustomer provides a mock function with given fields: w, r
+func (_m *CustomerHandler) EraseCustomer(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// ExportCustomers provides a mock function with given fields: w, r
+func (_m *CustomerHandler) ExportCustomers(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// GetCustomerAddressByID provides a mock function with given fields: w, r
+func (_m *CustomerHandler) GetCustomerAddressByID(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// GetCustomerAddresses provides a mock function with given fields: w, r
+func (_m *CustomerHandler) GetCustomerAddresses(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// GetCustomerByDocument provides a mock function with given fields: w, r
+func (_m *CustomerHandler) GetCustomerByDocument(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
 // GetCustomerByEmail provides a mock function with given fields: w, r
 func (_m *CustomerHandler) GetCustomerByEmail(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
@@ -38,28 +83,63 @@ func (_m *CustomerHandler) GetCustomerByName(w http.ResponseWriter, r *http.Requ
 	_m.Called(w, r)
 }
 
+// GetCustomerByPhone provides a mock function with given fields: w, r
+func (_m *CustomerHandler) GetCustomerByPhone(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// GetCustomerPhoneByID provides a mock function with given fields: w, r
+func (_m *CustomerHandler) GetCustomerPhoneByID(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// GetCustomerPhones provides a mock function with given fields: w, r
+func (_m *CustomerHandler) GetCustomerPhones(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
 // GetCustomers provides a mock function with given fields: w, r
 func (_m *CustomerHandler) GetCustomers(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
 }
 
-// UpdateCustomer provides a mock function with given fields: w, r
-func (_m *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+// GetUpcomingBirthdays provides a mock function with given fields: w, r
+func (_m *CustomerHandler) GetUpcomingBirthdays(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// ImportCustomers provides a mock function with given fields: w, r
+func (_m *CustomerHandler) ImportCustomers(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
 }
 
-// V2GetCustomerByEmail provides a mock function with given fields: w, r
-func (_m *CustomerHandler) V2GetCustomerByEmail(w http.ResponseWriter, r *http.Request) {
+// PatchCustomer provides a mock function with given fields: w, r
+func (_m *CustomerHandler) PatchCustomer(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// RestoreCustomer provides a mock function with given fields: w, r
+func (_m *CustomerHandler) RestoreCustomer(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// SearchCustomers provides a mock function with given fields: w, r
+func (_m *CustomerHandler) SearchCustomers(w http.ResponseWriter, r *http.Request) {
+	_m.Called(w, r)
+}
+
+// UpdateCustomer provides a mock function with given fields: w, r
+func (_m *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
 }
 
-// V2GetCustomerByID provides a mock function with given fields: w, r
-func (_m *CustomerHandler) V2GetCustomerByID(w http.ResponseWriter, r *http.Request) {
+// UpdateCustomerAddress provides a mock function with given fields: w, r
+func (_m *CustomerHandler) UpdateCustomerAddress(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
 }
 
-// V2GetCustomerByName provides a mock function with given fields: w, r
-func (_m *CustomerHandler) V2GetCustomerByName(w http.ResponseWriter, r *http.Request) {
+// UpdateCustomerPhone provides a mock function with given fields: w, r
+func (_m *CustomerHandler) UpdateCustomerPhone(w http.ResponseWriter, r *http.Request) {
 	_m.Called(w, r)
 }
 
@@ -2,16 +2,19 @@ package test
 
 import (
 	"cmd/customer-service/internal/domain/entity"
+	"time"
 )
 
 var CustomerID = "customerID"
 var ErrorID = "errorID"
+var StaleVersion int64 = 1
 
 var ACustomer = &entity.Customer{
-	ID:      &CustomerID,
-	Name:    "John",
-	Surname: "Doe",
-	Email:   "john.doe@example.com",
+	ID:        &CustomerID,
+	Name:      "John",
+	Surname:   "Doe",
+	Email:     "john.doe@example.com",
+	Birthdate: entity.NewDate(1990, time.March, 7),
 }
 var ACustomerArray = []*entity.Customer{
 	{
@@ -27,3 +30,35 @@ var ACustomerArray = []*entity.Customer{
 		Email:   "aurelio@mock.com",
 	},
 }
+
+var NextCursor = "eyJzIjoiaWQiLCJvIjoiYXNjIiwidiI6IiIsImlkIjoiY3VzdG9tZXJJRCJ9"
+var ACustomerPage = &entity.CustomerPage{
+	Customers:  ACustomerArray,
+	NextCursor: &NextCursor,
+}
+var ALastCustomerPage = &entity.CustomerPage{
+	Customers: ACustomerArray[:1],
+}
+
+var AddressID = "addressID"
+var AnAddress = &entity.Address{
+	ID:         &AddressID,
+	CustomerID: CustomerID,
+	Type:       entity.AddressTypeHome,
+	Street:     "Avenida Paulista",
+	Number:     "1578",
+	District:   "Bela Vista",
+	City:       "São Paulo",
+	State:      "SP",
+	CEP:        "01310-200",
+	IsDefault:  true,
+}
+
+var PhoneID = "phoneID"
+var APhone = &entity.Phone{
+	ID:         &PhoneID,
+	CustomerID: CustomerID,
+	Type:       entity.PhoneTypeMobile,
+	Number:     "+5511987654321",
+	IsPrimary:  true,
+}
@@ -44,6 +44,14 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(*logger, db.DB, os.Args[2:]); err != nil {
+			logger.Error("Error migrating DB", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Metrics
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(collectors.NewGoCollector())
@@ -53,7 +53,7 @@ func (h *orderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 
 	order, err := h.orderSvc.GetOrderByID(ctx, id)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v1/orders/{orderId}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/orders/{orderId}", now)
 		return
 	}
 
@@ -74,7 +74,7 @@ func (h *orderHandler) GetOrdersByCustomerID(w http.ResponseWriter, r *http.Requ
 
 	orders, err := h.orderSvc.GetOrdersByCustomerID(ctx, customerID)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v1/orders/customers/{customerId}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/orders/customers/{customerId}", now)
 		return
 	}
 
@@ -92,13 +92,13 @@ func (h *orderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	var order *entity.OrderRequest
 	err := json.NewDecoder(r.Body).Decode(&order)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "POST", "/v1/orders", now)
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/orders", now)
 		return
 	}
 
 	id, err := h.orderSvc.CreateOrder(ctx, order)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "POST", "/v1/orders", now)
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/orders", now)
 		return
 	}
 
@@ -120,7 +120,7 @@ func (h *orderHandler) DeleteOrderByID(w http.ResponseWriter, r *http.Request) {
 
 	err := h.orderSvc.DeleteOrderByID(ctx, id)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "DELETE", "/v1/orders/{orderId}", now)
+		h.buildErrorResponse(ctx, w, err, "DELETE", "/v1/orders/{orderId}", now)
 		return
 	}
 
@@ -140,13 +140,27 @@ func (h *orderHandler) getContext(r *http.Request) context.Context {
 	return ctx
 }
 
-func (h *orderHandler) buildErrorResponse(w http.ResponseWriter, error string, statusCode int, method string, uri string, start time.Time) {
-	h.metrics.MeasureDuration(start, method, uri, fmt.Sprint(statusCode))
-	h.metrics.IncReqByStatusCode(fmt.Sprint(statusCode))
+// buildBadRequestResponse answers a request that couldn't be parsed
+func (h *orderHandler) buildBadRequestResponse(ctx context.Context, w http.ResponseWriter, err error, method string, uri string, start time.Time) {
+	traceID, _ := ctx.Value("traceID").(string)
+	h.writeProblem(w, newProblem(http.StatusBadRequest, err.Error(), traceID), method, uri, start)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	h.buildResponse(w, fmt.Sprintf("Error on %s order: %s", method, error), start, map[string]interface{}{})
+func (h *orderHandler) buildErrorResponse(ctx context.Context, w http.ResponseWriter, err error, method string, uri string, start time.Time) {
+	traceID, _ := ctx.Value("traceID").(string)
+	p := errorProblem(err, traceID)
+	if p.Status >= http.StatusInternalServerError {
+		h.logger.Error(fmt.Sprintf("Error on %s order", method), "uri", uri, "error", err, "traceID", ctx.Value("traceID"))
+	}
+
+	h.writeProblem(w, p, method, uri, start)
+}
+
+func (h *orderHandler) writeProblem(w http.ResponseWriter, p *problem, method string, uri string, start time.Time) {
+	h.metrics.MeasureDuration(start, method, uri, fmt.Sprint(p.Status))
+	h.metrics.IncReqByStatusCode(fmt.Sprint(p.Status))
+
+	writeProblem(w, p)
 }
 
 func (h *orderHandler) buildResponse(w http.ResponseWriter, message string, start time.Time, data map[string]interface{}) {
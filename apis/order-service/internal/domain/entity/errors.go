@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrCustomerNotFound and ErrProductNotFound are returned when an order references something
+	// the other services don't have
+	ErrCustomerNotFound = errors.New("customer not found")
+	ErrProductNotFound  = errors.New("product not found")
+	// ErrUnavailable wraps the failures of a dependency that may succeed if retried later
+	ErrUnavailable = errors.New("service temporarily unavailable")
+)
+
+// FieldError describes why a single field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a request, not only the first one found
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + " " + field.Message
+	}
+	return "Invalid fields: " + strings.Join(messages, "; ")
+}
+
+// InvalidField builds the ValidationError of a single field
+func InvalidField(field string, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
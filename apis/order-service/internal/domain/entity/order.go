@@ -10,7 +10,9 @@ type OrderRequest struct {
 	Products      []OrderRequestProduct `json:"products"`
 }
 
+// OrderRequestProduct references a product by its SKU or by its name, the SKU doesn't change on renames
 type OrderRequestProduct struct {
+	SKU      string `json:"sku" db:"sku"`
 	Name     string `json:"name" db:"name"`
 	Quantity int64  `json:"quantity" db:"quantity"`
 }
@@ -7,4 +7,5 @@ import (
 
 type ProductGateway interface {
 	GetProductByName(ctx context.Context, productName *string) (*dto.Product, error)
+	GetProductBySKU(ctx context.Context, sku *string) (*dto.Product, error)
 }
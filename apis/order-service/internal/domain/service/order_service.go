@@ -5,7 +5,10 @@ import (
 	"cmd/order-service/internal/domain/gateway"
 	"cmd/order-service/internal/resources/client/dto"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/oklog/ulid/v2"
@@ -58,18 +61,28 @@ func (s *orderService) GetOrdersByCustomerID(ctx context.Context, customerID str
 
 func (s *orderService) CreateOrder(ctx context.Context, orderRequest *entity.OrderRequest) (*string, error) {
 	s.logger.Info("Getting customer and product info to build Order", "orderRequest", orderRequest, "traceID", ctx.Value("traceID"))
+	err := validateOrderRequest(orderRequest)
+	if err != nil {
+		return nil, err
+	}
 
 	customer, err := s.customerGtw.GetCustomerByEmail(ctx, &orderRequest.CustomerEmail)
+	if errors.Is(err, entity.ErrCustomerNotFound) {
+		return nil, entity.InvalidField("customer_email", "doesn't belong to any customer")
+	}
 	if err != nil {
 		s.logger.Error("Failed to get customer", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
 
 	products := make([]dto.Product, 0)
-	for _, productRequest := range orderRequest.Products {
-		product, err := s.productGtw.GetProductByName(ctx, &productRequest.Name)
+	for i, productRequest := range orderRequest.Products {
+		product, field, err := s.getRequestedProduct(ctx, productRequest)
+		if errors.Is(err, entity.ErrProductNotFound) {
+			return nil, entity.InvalidField(fmt.Sprintf("products[%d].%s", i, field), "doesn't belong to any product")
+		}
 		if err != nil {
-			s.logger.Error("Failed to get product", "productName", productRequest.Name, "error", err, "traceID", ctx.Value("traceID"))
+			s.logger.Error("Failed to get product", "sku", productRequest.SKU, "productName", productRequest.Name, "error", err, "traceID", ctx.Value("traceID"))
 			return nil, err
 		}
 		s.logger.Debug("Inserting product on list", "traceID", ctx.Value("traceID"))
@@ -105,3 +118,44 @@ func (s *orderService) DeleteOrderByID(ctx context.Context, orderID string) erro
 
 	return nil
 }
+
+// getRequestedProduct looks a product up by the reference the request has, it returns the field of that reference
+func (s *orderService) getRequestedProduct(ctx context.Context, productRequest entity.OrderRequestProduct) (*dto.Product, string, error) {
+	if productRequest.SKU != "" {
+		product, err := s.productGtw.GetProductBySKU(ctx, &productRequest.SKU)
+		return product, "sku", err
+	}
+
+	product, err := s.productGtw.GetProductByName(ctx, &productRequest.Name)
+	return product, "name", err
+}
+
+// validateOrderRequest checks the references of an order before they are looked up
+func validateOrderRequest(orderRequest *entity.OrderRequest) error {
+	if orderRequest == nil {
+		return entity.InvalidField("body", "is required")
+	}
+
+	fields := make([]entity.FieldError, 0)
+	if strings.TrimSpace(orderRequest.CustomerEmail) == "" {
+		fields = append(fields, entity.FieldError{Field: "customer_email", Message: "is required"})
+	}
+	if len(orderRequest.Products) == 0 {
+		fields = append(fields, entity.FieldError{Field: "products", Message: "must have at least one product"})
+	}
+	for i, product := range orderRequest.Products {
+		hasSKU := strings.TrimSpace(product.SKU) != ""
+		hasName := strings.TrimSpace(product.Name) != ""
+		switch {
+		case !hasSKU && !hasName:
+			fields = append(fields, entity.FieldError{Field: fmt.Sprintf("products[%d]", i), Message: "must have a sku or a name"})
+		case hasSKU && hasName:
+			fields = append(fields, entity.FieldError{Field: fmt.Sprintf("products[%d]", i), Message: "must have either a sku or a name, not both"})
+		}
+	}
+
+	if len(fields) > 0 {
+		return &entity.ValidationError{Fields: fields}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"cmd/order-service/internal/domain/entity"
 	"cmd/order-service/internal/domain/gateway"
 	"cmd/order-service/internal/metrics"
 	"cmd/order-service/internal/resources/client/dto"
@@ -16,28 +17,35 @@ import (
 type customerGateway struct {
 	logger  slog.Logger
 	metrics *metrics.OrderMetrics
+	etags   *etagCache
 }
 
 func NewCustomerGateway(l slog.Logger, m *metrics.OrderMetrics) gateway.CustomerGateway {
 	return &customerGateway{
 		logger:  *l.With("layer", "customer-client"),
 		metrics: m,
+		etags:   newETagCache(),
 	}
 }
 
 func (g *customerGateway) GetCustomerByEmail(ctx context.Context, customerEmail *string) (*dto.Customer, error) {
 	g.logger.Info("Calling customer-service to get getCustomerByEmail", "customerEmail", customerEmail, "traceID", ctx.Value("traceID"))
-	url := fmt.Sprintf("http://of-customer-service:8001/v2/customers/email/%s", *customerEmail)
+	url := fmt.Sprintf("http://of-customer-service:8001/v1/customers/email/%s", *customerEmail)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	cached := g.etags.prepare(req)
 	now := time.Now()
 
-	res, err := http.Get(url)
-	g.metrics.MeasureExternalDuration(now, "customer-service", "GET", "/v2/customers/email/{email}", "")
+	res, err := http.DefaultClient.Do(req)
+	g.metrics.MeasureExternalDuration(now, "customer-service", "GET", "/v1/customers/email/{email}", "")
 	if err != nil {
 		g.logger.Error("Customer-service request failed", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnavailable, err)
 	}
 
-	body, err := g.getBodyFromResponse(ctx, res)
+	body, err := g.getBodyFromResponse(ctx, res, cached)
 	if err != nil {
 		return nil, err
 	}
@@ -54,18 +62,25 @@ func (g *customerGateway) GetCustomerByEmail(ctx context.Context, customerEmail
 	return &responseDTO.Data.Customer, nil
 }
 
-func (g *customerGateway) getBodyFromResponse(ctx context.Context, res *http.Response) ([]byte, error) {
+// getBodyFromResponse reuses the cached body when the customer didn't change since it was fetched
+func (g *customerGateway) getBodyFromResponse(ctx context.Context, res *http.Response, cached *etagEntry) ([]byte, error) {
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		res.Body.Close()
+		g.logger.Debug("Customer not modified, using cached body", "etag", cached.etag, "traceID", ctx.Value("traceID"))
+		return cached.body, nil
+	}
+	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
 		g.logger.Error("Request status code is not OK", "statusCode", res.StatusCode, "traceID", ctx.Value("traceID"))
-		return nil, fmt.Errorf("Got statusCode %d from customer request", res.StatusCode)
+		return nil, statusCodeError("customer-service", res.StatusCode, entity.ErrCustomerNotFound)
 	}
-	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		g.logger.Error("Failed to read customer response body", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
+	g.etags.store(res, body)
 
 	return body, nil
 }
@@ -1,6 +1,7 @@
 package dto
 
-type GetProductByNameResponseDTO struct {
+// GetProductResponseDTO is the body of the product lookups, by name or by SKU
+type GetProductResponseDTO struct {
 	Message     string     `json:"message"`
 	Timestamp   string     `json:"timestamp"`
 	ElapsedTime string     `json:"elapsed_time"`
@@ -13,6 +14,7 @@ type ProductDTO struct {
 
 type Product struct {
 	ID          *string `json:"product_id" db:"product_id"`
+	SKU         string  `json:"sku" db:"sku"`
 	Name        string  `json:"name" db:"name"`
 	Description string  `json:"description" db:"description"`
 	Price       float64 `json:"price" db:"price"`
@@ -0,0 +1,19 @@
+package client
+
+import (
+	"cmd/order-service/internal/domain/entity"
+	"fmt"
+	"net/http"
+)
+
+// statusCodeError maps the status code of a failed call. A 404 means the referenced resource
+// doesn't exist, while throttling and server errors may go away if retried later.
+func statusCodeError(service string, statusCode int, notFound error) error {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return notFound
+	case statusCode == http.StatusTooManyRequests, statusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: got statusCode %d from %s", entity.ErrUnavailable, statusCode, service)
+	}
+	return fmt.Errorf("Got statusCode %d from %s", statusCode, service)
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+)
+
+// maxETagEntries bounds the bodies kept for revalidation
+const maxETagEntries = 1000
+
+type etagEntry struct {
+	etag string
+	body []byte
+}
+
+// etagCache keeps the last body received for each URL with its ETag, so the next request
+// can send If-None-Match and reuse the body when the service answers 304
+type etagCache struct {
+	mu      sync.Mutex
+	entries map[string]etagEntry
+}
+
+func newETagCache() *etagCache {
+	return &etagCache{entries: make(map[string]etagEntry)}
+}
+
+// prepare adds If-None-Match to req when a body for its URL is cached
+func (c *etagCache) prepare(req *http.Request) *etagEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[req.URL.String()]
+	if !ok {
+		return nil
+	}
+	req.Header.Set("If-None-Match", entry.etag)
+	return &entry
+}
+
+func (c *etagCache) store(res *http.Response, body []byte) {
+	etag := res.Header.Get("ETag")
+	if etag == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	url := res.Request.URL.String()
+	if _, ok := c.entries[url]; !ok && len(c.entries) >= maxETagEntries {
+		// any entry will do, it only costs a full response next time
+		for key := range c.entries {
+			delete(c.entries, key)
+			break
+		}
+	}
+	c.entries[url] = etagEntry{etag: etag, body: body}
+}
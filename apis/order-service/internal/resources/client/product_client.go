@@ -1,6 +1,7 @@
 package client
 
 import (
+	"cmd/order-service/internal/domain/entity"
 	"cmd/order-service/internal/domain/gateway"
 	"cmd/order-service/internal/metrics"
 	"cmd/order-service/internal/resources/client/dto"
@@ -10,40 +11,59 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	neturl "net/url"
 	"time"
 )
 
 type productGateway struct {
 	logger  slog.Logger
 	metrics *metrics.OrderMetrics
+	etags   *etagCache
 }
 
 func NewProductGateway(l slog.Logger, m *metrics.OrderMetrics) gateway.ProductGateway {
 	return &productGateway{
 		logger:  *l.With("layer", "product-client"),
 		metrics: m,
+		etags:   newETagCache(),
 	}
 }
 
 func (g *productGateway) GetProductByName(ctx context.Context, productName *string) (*dto.Product, error) {
 	g.logger.Info("Calling product-service to get on getProductByName", "productName", productName, "traceID", ctx.Value("traceID"))
 	url := fmt.Sprintf("http://of-product-service:8002/v1/products/name/%s", *productName)
+	return g.getProduct(ctx, url, "/v1/products/name/{name}")
+}
+
+func (g *productGateway) GetProductBySKU(ctx context.Context, sku *string) (*dto.Product, error) {
+	g.logger.Info("Calling product-service to get on getProductBySKU", "sku", sku, "traceID", ctx.Value("traceID"))
+	url := fmt.Sprintf("http://of-product-service:8002/v1/products/sku/%s", neturl.PathEscape(*sku))
+	return g.getProduct(ctx, url, "/v1/products/sku/{sku}")
+}
+
+// getProduct calls one of the product lookups, uri is the route measured
+func (g *productGateway) getProduct(ctx context.Context, url string, uri string) (*dto.Product, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	cached := g.etags.prepare(req)
 	start := time.Now()
 
-	res, err := http.Get(url)
-	g.metrics.MeasureExternalDuration(start, "product-service", "GET", "/v1/products/name/{name}", "")
+	res, err := http.DefaultClient.Do(req)
+	g.metrics.MeasureExternalDuration(start, "product-service", "GET", uri, "")
 	if err != nil {
 		g.logger.Error("Product-service request failed", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", entity.ErrUnavailable, err)
 	}
 
-	body, err := g.getBodyFromResponse(ctx, res)
+	body, err := g.getBodyFromResponse(ctx, res, cached)
 	if err != nil {
 		return nil, err
 	}
 	g.logger.Debug("Product-service body data", "body", string(body), "traceID", ctx.Value("traceID"))
 
-	var responseDTO dto.GetProductByNameResponseDTO
+	var responseDTO dto.GetProductResponseDTO
 	err = json.Unmarshal(body, &responseDTO)
 	if err != nil {
 		g.logger.Error("Failed to unmarshal product response body", "error", err, "traceID", ctx.Value("traceID"))
@@ -54,18 +74,25 @@ func (g *productGateway) GetProductByName(ctx context.Context, productName *stri
 	return &responseDTO.Data.Product, nil
 }
 
-func (g *productGateway) getBodyFromResponse(ctx context.Context, res *http.Response) ([]byte, error) {
+// getBodyFromResponse reuses the cached body when the product didn't change since it was fetched
+func (g *productGateway) getBodyFromResponse(ctx context.Context, res *http.Response, cached *etagEntry) ([]byte, error) {
+	if res.StatusCode == http.StatusNotModified && cached != nil {
+		res.Body.Close()
+		g.logger.Debug("Product not modified, using cached body", "etag", cached.etag, "traceID", ctx.Value("traceID"))
+		return cached.body, nil
+	}
+	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
 		g.logger.Error("Request status code is not OK", "statusCode", res.StatusCode, "traceID", ctx.Value("traceID"))
-		return nil, fmt.Errorf("Got statusCode %d from product request", res.StatusCode)
+		return nil, statusCodeError("product-service", res.StatusCode, entity.ErrProductNotFound)
 	}
-	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		g.logger.Error("Failed to read product response body", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
+	g.etags.store(res, body)
 
 	return body, nil
 }
@@ -0,0 +1,19 @@
+package database
+
+import (
+	"cmd/order-service/internal/domain/entity"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// translateError turns driver errors into domain errors so their text never reaches clients.
+// Callers log the raw error before translating it.
+func translateError(err error) error {
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) || errors.Is(err, mongo.ErrClientDisconnected) {
+		return fmt.Errorf("%w: %w", entity.ErrUnavailable, err)
+	}
+
+	return err
+}
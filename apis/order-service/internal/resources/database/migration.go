@@ -0,0 +1,229 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	migrationCollection     = "schema_migrations"
+	migrationLockCollection = "schema_migrations_lock"
+	migrationLockID         = "migration"
+	// a lock older than this was left by a replica that died while migrating, so it can be taken over
+	migrationLockTTL   = 15 * time.Minute
+	migrationLockRetry = time.Second
+)
+
+// Migration changes the order database through the driver, a standalone MongoDB has no transactions,
+// so Up and Down must be safe to run again after a failure
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+type Migrator struct {
+	logger     slog.Logger
+	db         *mongo.Database
+	migrations []Migration
+	owner      string
+}
+
+func NewMigrator(l slog.Logger, db *mongo.Client) (*Migrator, error) {
+	migrations, err := sortMigrations(orderMigrations)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+
+	return &Migrator{
+		logger:     *l.With("layer", "migration"),
+		db:         db.Database("order-service"),
+		migrations: migrations,
+		owner:      fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}, nil
+}
+
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("Migration version %d is used by %s and %s", sorted[i].Version, sorted[i-1].Name, sorted[i].Name)
+		}
+	}
+
+	return sorted, nil
+}
+
+// Up applies every pending migration in order and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(done map[int]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			m.logger.Info("Applying migration", "version", migration.Version, "name", migration.Name)
+			if err := migration.Up(ctx, m.db); err != nil {
+				return fmt.Errorf("Failed to apply migration %d_%s: %s", migration.Version, migration.Name, err)
+			}
+			record := appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}
+			if _, err := m.db.Collection(migrationCollection).InsertOne(ctx, record); err != nil {
+				return fmt.Errorf("Failed to record migration %d_%s: %s", migration.Version, migration.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns how many were reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(done map[int]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			m.logger.Info("Reverting migration", "version", migration.Version, "name", migration.Name)
+			if err := migration.Down(ctx, m.db); err != nil {
+				return fmt.Errorf("Failed to revert migration %d_%s: %s", migration.Version, migration.Name, err)
+			}
+			if _, err := m.db.Collection(migrationCollection).DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+				return fmt.Errorf("Failed to unrecord migration %d_%s: %s", migration.Version, migration.Name, err)
+			}
+			reverted++
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration and when it was applied, nil when it's pending
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	done, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := done[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// withLock holds the lock document while fn runs and hands it the applied versions read after the lock was taken
+func (m *Migrator) withLock(ctx context.Context, fn func(done map[int]time.Time) error) error {
+	if err := m.lock(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		// the context may be done already, the lock must be released anyway
+		_, err := m.db.Collection(migrationLockCollection).DeleteOne(context.Background(), bson.M{"_id": migrationLockID, "owner": m.owner})
+		if err != nil {
+			m.logger.Error("Failed to release the migration lock", "error", err)
+		}
+	}()
+
+	done, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return fn(done)
+}
+
+// lock inserts the lock document, the unique _id makes concurrent replicas wait until it's deleted
+func (m *Migrator) lock(ctx context.Context) error {
+	locks := m.db.Collection(migrationLockCollection)
+	m.logger.Debug("Waiting for the migration lock", "owner", m.owner)
+	for {
+		_, err := locks.InsertOne(ctx, bson.M{"_id": migrationLockID, "owner": m.owner, "locked_at": time.Now().UTC()})
+		if err == nil {
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("Failed to take the migration lock: %s", err)
+		}
+
+		stale := bson.M{"_id": migrationLockID, "locked_at": bson.M{"$lt": time.Now().UTC().Add(-migrationLockTTL)}}
+		if result, err := locks.DeleteOne(ctx, stale); err == nil && result.DeletedCount > 0 {
+			m.logger.Warn("Took over a stale migration lock")
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Failed to take the migration lock: %s", ctx.Err())
+		case <-time.After(migrationLockRetry):
+		}
+	}
+}
+
+func (m *Migrator) appliedMigrations(ctx context.Context) (map[int]time.Time, error) {
+	cursor, err := m.db.Collection(migrationCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the applied migrations: %s", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []appliedMigration
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("Failed to decode the applied migrations: %s", err)
+	}
+
+	done := make(map[int]time.Time, len(records))
+	for _, record := range records {
+		done[record.Version] = record.AppliedAt
+	}
+
+	return done, nil
+}
+
+// dropIndexes ignores the indexes that are already gone, so a Down can run again
+func dropIndexes(ctx context.Context, collection *mongo.Collection, names ...string) error {
+	for _, name := range names {
+		_, err := collection.Indexes().DropOne(ctx, name)
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && (cmdErr.Name == "IndexNotFound" || cmdErr.Name == "NamespaceNotFound") {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
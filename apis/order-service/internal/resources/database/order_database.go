@@ -5,6 +5,7 @@ import (
 	"cmd/order-service/internal/domain/gateway"
 	"cmd/order-service/internal/metrics"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -37,13 +38,13 @@ func (g *orderGateway) GetOrderByID(ctx context.Context, orderID *string) (*enti
 
 	err := collection.FindOne(ctx, filter).Decode(&order)
 	g.metrics.MeasureExternalDuration(start, "database", "OrderDB", "GetOrderByID", "")
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		g.logger.Info("Order not found by ID", "ID", orderID, "traceID", ctx.Value("traceID"))
+		return nil, fmt.Errorf("%w with ID=%s", entity.ErrOrderNotFound, *orderID)
+	}
 	if err != nil {
-		if err != mongo.ErrNoDocuments {
-			g.logger.Error("Order not found by ID", "error", err, "traceID", ctx.Value("traceID"))
-			return nil, err
-		}
 		g.logger.Error("Failed to find order by ID in DB", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return &order, nil
@@ -61,13 +62,13 @@ func (g *orderGateway) GetOrdersByCustomerID(ctx context.Context, customerID *st
 	g.metrics.MeasureExternalDuration(start, "database", "OrderDB", "GetOrdersByCustomerID", "")
 	if err != nil {
 		g.logger.Error("Failed to find orders list by customerID in DB", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	defer cursor.Close(ctx)
 	if err := cursor.All(ctx, &orders); err != nil {
 		g.logger.Error("Failed decode orders list", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return orders, nil
@@ -82,7 +83,7 @@ func (g *orderGateway) CreateOrder(ctx context.Context, order *entity.Order) (*s
 	g.metrics.MeasureExternalDuration(start, "database", "OrderDB", "CreateOrder", "")
 	if err != nil {
 		g.logger.Error("Failed to insert order into DB", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	g.logger.Info("Sucessfull insert of order into DB", "insertedID", insertResult.InsertedID, "traceID", ctx.Value("traceID"))
@@ -100,12 +101,12 @@ func (g *orderGateway) DeleteOrderByID(ctx context.Context, orderID *string) err
 	g.metrics.MeasureExternalDuration(start, "database", "OrderDB", "DeleteOrderByID", "")
 	if err != nil {
 		g.logger.Error("Failed to find order by ID in DB", "error", err, "traceID", ctx.Value("traceID"))
-		return err
+		return translateError(err)
 	}
 
 	if deletedResult.DeletedCount == 0 {
-		g.logger.Error("Order not found by ID", "error", err, "traceID", ctx.Value("traceID"))
-		return fmt.Errorf("Order not found with ID=%s", *orderID)
+		g.logger.Info("Order not found by ID", "ID", orderID, "traceID", ctx.Value("traceID"))
+		return fmt.Errorf("%w with ID=%s", entity.ErrOrderNotFound, *orderID)
 	}
 
 	return nil
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// orderMigrations are applied by `order-service migrate` in the order of their versions.
+// Never change an applied migration, add a new one instead.
+var orderMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_order_indexes",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// orders are read by their ID and listed by the ID of their customer
+			_, err := db.Collection("order").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetName("order_id_idx").SetUnique(true)},
+				{Keys: bson.D{{Key: "customer.id", Value: 1}}, Options: options.Index().SetName("order_customer_id_idx")},
+			})
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("order"), "order_id_idx", "order_customer_id_idx")
+		},
+	},
+}
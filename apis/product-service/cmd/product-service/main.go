@@ -6,6 +6,7 @@ import (
 	"cmd/product-service/internal/metrics"
 	"cmd/product-service/internal/pyroscope"
 	"cmd/product-service/internal/resources/database"
+	"context"
 	"fmt"
 	"log"
 	"log/slog"
@@ -43,16 +44,43 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(*logger, db.DB, os.Args[2:]); err != nil {
+			logger.Error("Error migrating DB", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Metrics
 	reg := prometheus.NewRegistry()
 	reg.MustRegister(collectors.NewGoCollector())
 	prometheusHandler := promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg})
 	metrics := metrics.NewProductMetrics(*logger, reg)
 
+	reservationCfg, err := service.GetReservationConfig()
+	if err != nil {
+		logger.Error("Error getting reservation config", "error", err)
+		return
+	}
+
 	productGtw := database.NewProductGateway(*logger, metrics, db.DB)
-	productSvc := service.NewProductService(*logger, productGtw)
+	productSvc := service.NewProductService(*logger, productGtw, reservationCfg.TTL)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		if err := runReconcile(productSvc, os.Args[2:]); err != nil {
+			logger.Error("Error reconciling inventory", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	productHandler := api.NewProductHandler(*logger, metrics, productSvc)
 
+	reaperCtx, stopReaper := context.WithCancel(context.Background())
+	defer stopReaper()
+	go runReservationReaper(reaperCtx, *logger, metrics, productSvc, reservationCfg.ReaperInterval)
+
 	r := createRouter(prometheusHandler, productHandler)
 	logger.Debug("Starting prodduct-service", "port", os.Getenv("APP_PORT"))
 	go http.ListenAndServe(fmt.Sprintf(":%s", os.Getenv("APP_PORT")), r)
@@ -69,11 +97,26 @@ func createRouter(prometheusHandler http.Handler, productHandler api.ProductHand
 
 	r.HandleFunc("/metrics", prometheusHandler.ServeHTTP).Methods("GET")
 	r.HandleFunc("/v1/products", productHandler.GetProducts).Methods("GET")
+	r.HandleFunc("/v1/products/export", productHandler.ExportProducts).Methods("GET")
 	r.HandleFunc("/v1/products/name/{name}", productHandler.GetProductByName).Methods("GET")
+	r.HandleFunc("/v1/products/sku/{sku}", productHandler.GetProductBySKU).Methods("GET")
+	r.HandleFunc("/v1/products/reservations/{id}", productHandler.GetReservation).Methods("GET")
 	r.HandleFunc("/v1/products/{id}", productHandler.GetProductByID).Methods("GET")
+	r.HandleFunc("/v1/products/{id}/movements", productHandler.GetProductMovements).Methods("GET")
+	r.HandleFunc("/v1/products/{id}/categories", productHandler.GetProductCategories).Methods("GET")
+	r.HandleFunc("/v1/categories", productHandler.GetCategories).Methods("GET")
+	r.HandleFunc("/v1/categories/{slug}/products", productHandler.GetCategoryProducts).Methods("GET")
 	r.HandleFunc("/v1/products", productHandler.CreateProduct).Methods("POST")
+	r.HandleFunc("/v1/products/reservations", productHandler.CreateReservation).Methods("POST")
+	r.HandleFunc("/v1/products/reservations/{id}/commit", productHandler.CommitReservation).Methods("POST")
+	r.HandleFunc("/v1/products/reservations/{id}/release", productHandler.ReleaseReservation).Methods("POST")
+	r.HandleFunc("/v1/products/{id}/stock-adjustments", productHandler.AdjustStock).Methods("POST")
+	r.HandleFunc("/v1/categories", productHandler.CreateCategory).Methods("POST")
 	r.HandleFunc("/v1/products/{id}", productHandler.UpdateProduct).Methods("PUT")
+	r.HandleFunc("/v1/products/{id}/categories", productHandler.SetProductCategories).Methods("PUT")
+	r.HandleFunc("/v1/products/{id}", productHandler.PatchProduct).Methods("PATCH")
 	r.HandleFunc("/v1/products/{id}", productHandler.DeleteProduct).Methods("DELETE")
+	r.HandleFunc("/v1/categories/{slug}", productHandler.DeleteCategory).Methods("DELETE")
 
 	r.PathPrefix("/products/doc/").Handler(httpSwagger.Handler(
 		httpSwagger.URL("/swagger.yml"),
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"cmd/product-service/internal/domain/service"
+	"cmd/product-service/internal/metrics"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// reaperActor is recorded on the movements of the reservations the reaper expires
+const reaperActor = "reservation-reaper"
+
+// runReservationReaper gives the stock of expired reservations back every interval until ctx is done,
+// and refreshes the active reservations gauge. Every replica runs one, they skip the rows another one holds.
+func runReservationReaper(ctx context.Context, logger slog.Logger, m *metrics.ProductMetrics, productSvc service.ProductService, interval time.Duration) {
+	logger = *logger.With("layer", "reservation-reaper")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		tickCtx := context.WithValue(ctx, "traceID", ulid.Make().String())
+		tickCtx = context.WithValue(tickCtx, "actor", reaperActor)
+		_, active, err := productSvc.ExpireReservations(tickCtx)
+		if err != nil {
+			logger.Error("Failed to reap reservations, retrying on the next tick", "error", err, "traceID", tickCtx.Value("traceID"))
+			continue
+		}
+		m.SetActiveReservations(active)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"cmd/product-service/internal/domain/service"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/oklog/ulid/v2"
+)
+
+const reconcileUsage = "usage: product-service reconcile [--fix]"
+
+// reconcileActor is recorded on the adjustments appended by `reconcile --fix`
+const reconcileActor = "reconcile"
+
+// runReconcile handles `product-service reconcile`, it lists the products whose quantity drifted from the
+// inventory ledger and fails when there is any. With --fix the drift is recorded as adjustments instead.
+func runReconcile(productSvc service.ProductService, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx = context.WithValue(ctx, "traceID", ulid.Make().String())
+	ctx = context.WithValue(ctx, "actor", reconcileActor)
+
+	fix := false
+	switch {
+	case len(args) == 1 && args[0] == "--fix":
+		fix = true
+	case len(args) > 0:
+		return errors.New(reconcileUsage)
+	}
+
+	drifts, err := productSvc.ReconcileInventory(ctx, fix)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PRODUCT ID\tNAME\tQUANTITY\tLEDGER\tDRIFT")
+	for _, drift := range drifts {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%+d\n", drift.ProductID, drift.Name, drift.Quantity, drift.LedgerQuantity, drift.Quantity-drift.LedgerQuantity)
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	if len(drifts) > 0 && !fix {
+		return fmt.Errorf("%d products drifted from the inventory ledger, run with --fix to record the drift as adjustments", len(drifts))
+	}
+	return nil
+}
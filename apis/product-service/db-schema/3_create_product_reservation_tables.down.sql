@@ -0,0 +1,12 @@
+-- stock still held goes back to the products before the reservations are dropped
+UPDATE products p SET quantity = p.quantity + held.quantity, updated_at = 'NOW()', version = p.version + 1
+FROM (
+    SELECT i.product_id, SUM(i.quantity) AS quantity
+    FROM product_reservation_items i JOIN product_reservations r ON r.reservation_id = i.reservation_id
+    WHERE r.status = 'held'
+    GROUP BY i.product_id
+) held
+WHERE p.product_id = held.product_id;
+
+DROP TABLE IF EXISTS product_reservation_items;
+DROP TABLE IF EXISTS product_reservations;
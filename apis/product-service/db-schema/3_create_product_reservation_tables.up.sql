@@ -0,0 +1,22 @@
+-- a reservation holds stock for a while: the quantity of its products is taken from products.quantity when
+-- it's held and given back when it's released or expires, a committed one keeps it for good
+CREATE TABLE IF NOT EXISTS product_reservations (
+    reservation_id CHAR(26) PRIMARY KEY,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('held', 'committed', 'released', 'expired')),
+    expires_at TIMESTAMP NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NULL
+);
+
+-- products aren't referenced, deleting a product must not depend on the reservations it was in
+CREATE TABLE IF NOT EXISTS product_reservation_items (
+    reservation_id CHAR(26) NOT NULL REFERENCES product_reservations (reservation_id) ON DELETE CASCADE,
+    product_id CHAR(26) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+
+    CONSTRAINT product_reservation_items_pkey PRIMARY KEY (reservation_id, product_id)
+);
+
+-- the reaper looks for the held reservations past their expiry
+CREATE INDEX IF NOT EXISTS product_reservations_held_expires_at_idx ON product_reservations (expires_at) WHERE status = 'held';
@@ -0,0 +1,22 @@
+-- every change of products.quantity appends a movement, so the stock of a product is the sum of its movements.
+-- Rows are never updated nor deleted, the movements of a deleted product stay as its history.
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    movement_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    product_id CHAR(26) NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('restock', 'sale', 'reservation', 'adjustment', 'return')),
+    -- signed, negative when stock left
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    reservation_id CHAR(26) NULL,
+    actor VARCHAR(100) NOT NULL,
+    trace_id VARCHAR(100) NULL,
+
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS inventory_movements_product_id_idx ON inventory_movements (product_id, movement_id);
+
+-- the stock products already had opens their ledger
+INSERT INTO inventory_movements (product_id, reason, quantity, actor, created_at)
+SELECT p.product_id, 'adjustment', p.quantity, 'migration', NOW()
+FROM products p
+WHERE p.quantity <> 0 AND NOT EXISTS (SELECT 1 FROM inventory_movements m WHERE m.product_id = p.product_id);
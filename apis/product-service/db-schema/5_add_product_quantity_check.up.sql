@@ -0,0 +1,16 @@
+-- the stock can't go negative, whatever the path that changes it. Stock that went negative before the check
+-- is brought back to zero first, with the adjustment that keeps its ledger adding up to the quantity.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'products_quantity_check') THEN
+        INSERT INTO inventory_movements (product_id, reason, quantity, actor, created_at)
+        SELECT product_id, 'adjustment', -quantity, 'migration', NOW()
+        FROM products
+        WHERE quantity < 0;
+
+        UPDATE products SET quantity = 0, updated_at = NOW(), version = version + 1 WHERE quantity < 0;
+
+        ALTER TABLE products ADD CONSTRAINT products_quantity_check CHECK (quantity >= 0);
+    END IF;
+END;
+$$;
@@ -0,0 +1,7 @@
+-- the SKU is the stable reference of a product, unlike its name it never changes.
+-- Products created before it get one derived from their ID.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(32);
+UPDATE products SET sku = 'SKU-' || product_id WHERE sku IS NULL;
+ALTER TABLE products ALTER COLUMN sku SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS products_sku_key ON products (sku);
@@ -0,0 +1,22 @@
+-- categories form a tree through parent_id, a category with subcategories can't be deleted
+CREATE TABLE IF NOT EXISTS categories (
+    category_id CHAR(26) PRIMARY KEY,
+    parent_id CHAR(26) NULL REFERENCES categories (category_id),
+    slug VARCHAR(100) UNIQUE NOT NULL,
+    name VARCHAR(200) NOT NULL,
+
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NULL
+);
+
+-- a product may be in many categories, the assignments go away with the product or the category
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id CHAR(26) NOT NULL REFERENCES products (product_id) ON DELETE CASCADE,
+    category_id CHAR(26) NOT NULL REFERENCES categories (category_id) ON DELETE CASCADE,
+
+    CONSTRAINT product_categories_pkey PRIMARY KEY (product_id, category_id)
+);
+
+-- the subcategories of a category and the products of a category are looked up by category
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
+CREATE INDEX IF NOT EXISTS product_categories_category_id_idx ON product_categories (category_id, product_id);
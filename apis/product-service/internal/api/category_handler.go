@@ -0,0 +1,149 @@
+package api
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *productHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET category tree request", "traceID", ctx.Value("traceID"))
+
+	categories, err := h.productSvc.GetCategoryTree(ctx)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/categories", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/categories", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Category tree", now, map[string]interface{}{"categories": categories})
+}
+
+func (h *productHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST category request", "traceID", ctx.Value("traceID"))
+
+	var category entity.Category
+	err := decodeJSON(r, &category)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/categories", now)
+		return
+	}
+
+	created, err := h.productSvc.CreateCategory(ctx, category)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/categories", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/categories", "201")
+	h.metrics.IncReqByStatusCode("201")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	h.buildResponse(w, "Category created", now, map[string]interface{}{"category": created})
+}
+
+func (h *productHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("DELETE category request", "traceID", ctx.Value("traceID"))
+
+	slug := mux.Vars(r)["slug"]
+
+	err := h.productSvc.DeleteCategory(ctx, slug)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "DELETE", "/v1/categories/{slug}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "DELETE", "/v1/categories/{slug}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Category deleted", now, map[string]interface{}{})
+}
+
+func (h *productHandler) GetCategoryProducts(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET category products request", "traceID", ctx.Value("traceID"))
+
+	query := r.URL.Query()
+	filter := entity.CategoryProductFilter{Slug: mux.Vars(r)["slug"], Cursor: query.Get("cursor")}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			h.buildBadRequestResponse(ctx, w, entity.InvalidField("limit", "must be an integer"), "GET", "/v1/categories/{slug}/products", now)
+			return
+		}
+		filter.Limit = value
+	}
+
+	productPage, err := h.productSvc.GetCategoryProducts(ctx, filter)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/categories/{slug}/products", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/categories/{slug}/products", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Products of category: %s", filter.Slug), now, map[string]interface{}{
+		"page_size":    len(productPage.Products),
+		"next_cursor":  productPage.NextCursor,
+		"page_content": productPage.Products,
+	})
+}
+
+func (h *productHandler) GetProductCategories(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET product categories request", "traceID", ctx.Value("traceID"))
+
+	id := mux.Vars(r)["id"]
+
+	categories, err := h.productSvc.GetProductCategories(ctx, id)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/products/{productId}/categories", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/products/{productId}/categories", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Categories of product: %s", id), now, map[string]interface{}{"categories": categories})
+}
+
+func (h *productHandler) SetProductCategories(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("PUT product categories request", "traceID", ctx.Value("traceID"))
+
+	var assignment entity.ProductCategories
+	err := decodeJSON(r, &assignment)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PUT", "/v1/products/{productId}/categories", now)
+		return
+	}
+	assignment.ProductID = mux.Vars(r)["id"]
+
+	categories, err := h.productSvc.SetProductCategories(ctx, assignment)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "PUT", "/v1/products/{productId}/categories", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "PUT", "/v1/products/{productId}/categories", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Categories of product set: %s", assignment.ProductID), now, map[string]interface{}{"categories": categories})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// formatETag builds the strong entity tag of a resource version. The ID is part of the tag
+// because the same resource is served under several URIs, and the versions of two resources
+// can be equal.
+func formatETag(id string, version int64) string {
+	return fmt.Sprintf("\"%s-%d\"", id, version)
+}
+
+// parseIfMatch reads the version expected by a conditional write on the resource with the
+// given ID. A missing header or "*" means the write doesn't depend on the current version,
+// a tag of another resource can never match.
+func parseIfMatch(r *http.Request, id string) (*int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	tag := strings.Trim(header, "\"")
+	sep := strings.LastIndex(tag, "-")
+	if sep < 0 || !strings.HasPrefix(header, "\"") || !strings.HasSuffix(header, "\"") {
+		return nil, fmt.Errorf("Invalid If-Match=%s", header)
+	}
+
+	version, err := strconv.ParseInt(tag[sep+1:], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid If-Match=%s", header)
+	}
+	if tag[:sep] != id {
+		return nil, fmt.Errorf("%w with ID=%s, If-Match=%s", entity.ErrProductVersionMismatch, id, header)
+	}
+
+	return &version, nil
+}
+
+// matchesIfNoneMatch tells whether the client already has the current version, comparing
+// every listed tag weakly as RFC 9110 asks for If-None-Match
+func matchesIfNoneMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	csvContentType    = "text/csv"
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// negotiateExportType picks the format of an export from the Accept header, in the order the client listed
+// them. NDJSON is the default, when there is no header or it accepts anything.
+func negotiateExportType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return ndjsonContentType, true
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case csvContentType, "text/*":
+			return csvContentType, true
+		case ndjsonContentType, "application/ndjson", "application/*", "*/*":
+			return ndjsonContentType, true
+		}
+	}
+	return "", false
+}
+
+// exportStream writes the rows of an export as they come. The status and headers are only sent with the
+// first row, so an export that fails before it still gets a problem response.
+type exportStream struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	header      []string
+	csv         *csv.Writer
+	json        *json.Encoder
+	started     bool
+}
+
+func newExportStream(w http.ResponseWriter, contentType string, name string, header []string) *exportStream {
+	extension := ".ndjson"
+	if contentType == csvContentType {
+		extension = ".csv"
+	}
+
+	return &exportStream{
+		w:           w,
+		contentType: contentType,
+		filename:    name + extension,
+		header:      header,
+	}
+}
+
+func (e *exportStream) start() error {
+	e.started = true
+	e.w.Header().Set("Content-Type", e.contentType)
+	e.w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": e.filename}))
+	e.w.WriteHeader(http.StatusOK)
+
+	if e.contentType != csvContentType {
+		e.json = json.NewEncoder(e.w)
+		return nil
+	}
+	e.csv = csv.NewWriter(e.w)
+	return e.csv.Write(e.header)
+}
+
+// write takes the record of the row lazily, it's only built for CSV
+func (e *exportStream) write(value interface{}, record func() []string) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	if e.csv != nil {
+		return e.csv.Write(record())
+	}
+	return e.json.Encode(value)
+}
+
+// finish sends the headers of an empty export, a CSV one still has its header row
+func (e *exportStream) finish() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *productHandler) GetProductMovements(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET product movements request", "traceID", ctx.Value("traceID"))
+
+	query := r.URL.Query()
+	filter := entity.MovementFilter{ProductID: mux.Vars(r)["id"], Cursor: query.Get("cursor")}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			h.buildBadRequestResponse(ctx, w, entity.InvalidField("limit", "must be an integer"), "GET", "/v1/products/{productId}/movements", now)
+			return
+		}
+		filter.Limit = value
+	}
+
+	movementPage, err := h.productSvc.GetProductMovements(ctx, filter)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/products/{productId}/movements", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/products/{productId}/movements", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Product movements", now, map[string]interface{}{
+		"page_size":    len(movementPage.Movements),
+		"next_cursor":  movementPage.NextCursor,
+		"page_content": movementPage.Movements,
+	})
+}
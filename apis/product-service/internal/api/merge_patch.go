@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// decodeMergePatch reads a JSON Merge Patch (RFC 7396) body, keeping which members were sent
+// so absent fields can be told apart from the ones set to null
+func decodeMergePatch(r *http.Request) (map[string]json.RawMessage, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != mergePatchContentType && mediaType != "application/json") {
+		return nil, fmt.Errorf("Content-Type must be %s", mergePatchContentType)
+	}
+
+	var patch map[string]json.RawMessage
+	err = json.NewDecoder(r.Body).Decode(&patch)
+	if err != nil || patch == nil {
+		return nil, fmt.Errorf("Merge patch must be a JSON object")
+	}
+
+	return patch, nil
+}
+
+// patchValue decodes a patched member into target, null is only accepted for nullable fields
+func patchValue(value json.RawMessage, nullable bool, target interface{}) error {
+	if string(value) == "null" {
+		if !nullable {
+			return fmt.Errorf("can't be removed")
+		}
+		return nil
+	}
+
+	err := json.Unmarshal(value, target)
+	if err != nil {
+		return fmt.Errorf("has an invalid type")
+	}
+
+	return nil
+}
+
+// sortedKeys keeps the field errors of a patch in a stable order
+func sortedKeys(fields map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var productExportHeader = []string{"product_id", "sku", "name", "description", "price", "quantity", "created_at", "updated_at", "version"}
+
+// ExportProducts streams the products, optionally filtered by category, as NDJSON or CSV in the format
+// of the Accept header
+func (h *productHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET products export request", "traceID", ctx.Value("traceID"))
+
+	contentType, ok := negotiateExportType(r.Header.Get("Accept"))
+	if !ok {
+		traceID, _ := ctx.Value("traceID").(string)
+		h.writeProblem(w, newProblem(http.StatusNotAcceptable, "Accept must be application/x-ndjson or text/csv", traceID), "GET", "/v1/products/export", now)
+		return
+	}
+
+	// the same category filter as the list, ?category=a&category=b
+	filter := entity.ProductFilter{Categories: r.URL.Query()["category"]}
+	stream := newExportStream(w, contentType, "products", productExportHeader)
+	err := h.productSvc.ExportProducts(ctx, filter, func(product *entity.Product) error {
+		return stream.write(product, func() []string { return productExportRecord(product) })
+	})
+	if err == nil {
+		err = stream.finish()
+	}
+	if err != nil && !stream.started {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/products/export", now)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Error on GET products export", "error", err, "traceID", ctx.Value("traceID"))
+		h.metrics.MeasureDuration(now, "GET", "/v1/products/export", "500")
+		h.metrics.IncReqByStatusCode("500")
+		// the status was sent already, aborting the response is the only way to tell the client it's truncated
+		panic(http.ErrAbortHandler)
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/products/export", "200")
+	h.metrics.IncReqByStatusCode("200")
+}
+
+func productExportRecord(product *entity.Product) []string {
+	record := []string{*product.ID, product.SKU, product.Name, product.Description, strconv.FormatFloat(product.Price, 'f', 2, 64),
+		strconv.FormatInt(product.Quantity, 10), product.CreatedAt.Format(time.RFC3339), "", strconv.FormatInt(product.Version, 10)}
+	if product.UpdatedAt != nil {
+		record[7] = product.UpdatedAt.Format(time.RFC3339)
+	}
+	return record
+}
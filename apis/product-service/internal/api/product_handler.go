@@ -6,6 +6,7 @@ import (
 	"cmd/product-service/internal/metrics"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -17,11 +18,26 @@ import (
 
 type ProductHandler interface {
 	GetProducts(w http.ResponseWriter, r *http.Request)
+	ExportProducts(w http.ResponseWriter, r *http.Request)
 	GetProductByID(w http.ResponseWriter, r *http.Request)
 	GetProductByName(w http.ResponseWriter, r *http.Request)
+	GetProductBySKU(w http.ResponseWriter, r *http.Request)
 	CreateProduct(w http.ResponseWriter, r *http.Request)
 	UpdateProduct(w http.ResponseWriter, r *http.Request)
+	PatchProduct(w http.ResponseWriter, r *http.Request)
 	DeleteProduct(w http.ResponseWriter, r *http.Request)
+	AdjustStock(w http.ResponseWriter, r *http.Request)
+	CreateReservation(w http.ResponseWriter, r *http.Request)
+	GetReservation(w http.ResponseWriter, r *http.Request)
+	CommitReservation(w http.ResponseWriter, r *http.Request)
+	ReleaseReservation(w http.ResponseWriter, r *http.Request)
+	GetProductMovements(w http.ResponseWriter, r *http.Request)
+	GetCategories(w http.ResponseWriter, r *http.Request)
+	CreateCategory(w http.ResponseWriter, r *http.Request)
+	DeleteCategory(w http.ResponseWriter, r *http.Request)
+	GetCategoryProducts(w http.ResponseWriter, r *http.Request)
+	GetProductCategories(w http.ResponseWriter, r *http.Request)
+	SetProductCategories(w http.ResponseWriter, r *http.Request)
 }
 
 type productHandler struct {
@@ -50,9 +66,11 @@ func (h *productHandler) GetProducts(w http.ResponseWriter, r *http.Request) {
 	ctx := h.getContext(r)
 	h.logger.Debug("GET all products request", "traceID", ctx.Value("traceID"))
 
-	products, err := h.productSvc.GetProductList(ctx)
+	// a product of any of the categories is listed, ?category=a&category=b
+	filter := entity.ProductFilter{Categories: r.URL.Query()["category"]}
+	products, err := h.productSvc.GetProductList(ctx, filter)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "GET", "/v1/products", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/products", now)
 		return
 	}
 
@@ -72,7 +90,10 @@ func (h *productHandler) GetProductByID(w http.ResponseWriter, r *http.Request)
 
 	product, err := h.productSvc.GetProductByID(ctx, id)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v1/products/{productId}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/products/{productId}", now)
+		return
+	}
+	if h.notModified(w, r, product, "/v1/products/{productId}", now) {
 		return
 	}
 
@@ -92,7 +113,10 @@ func (h *productHandler) GetProductByName(w http.ResponseWriter, r *http.Request
 
 	product, err := h.productSvc.GetProductByName(ctx, name)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "GET", "/v1/products/name/{name}", now)
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/products/name/{name}", now)
+		return
+	}
+	if h.notModified(w, r, product, "/v1/products/name/{name}", now) {
 		return
 	}
 
@@ -102,21 +126,44 @@ func (h *productHandler) GetProductByName(w http.ResponseWriter, r *http.Request
 	h.buildResponse(w, fmt.Sprintf("Product by Name: %s", name), now, map[string]interface{}{"product": product})
 }
 
+func (h *productHandler) GetProductBySKU(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET product by SKU request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	sku := vars["sku"]
+
+	product, err := h.productSvc.GetProductBySKU(ctx, sku)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/products/sku/{sku}", now)
+		return
+	}
+	if h.notModified(w, r, product, "/v1/products/sku/{sku}", now) {
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/products/sku/{sku}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Product by SKU: %s", sku), now, map[string]interface{}{"product": product})
+}
+
 func (h *productHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	ctx := h.getContext(r)
 	h.logger.Debug("POST product request", "traceID", ctx.Value("traceID"))
 
 	var product entity.Product
-	err := json.NewDecoder(r.Body).Decode(&product)
+	err := decodeJSON(r, &product)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "POST", "/v1/products", now)
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/products", now)
 		return
 	}
 
 	id, err := h.productSvc.CreateProduct(ctx, product)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "POST", "/v1/products", now)
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/products", now)
 		return
 	}
 
@@ -137,25 +184,72 @@ func (h *productHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 	var product entity.Product
 
-	err := json.NewDecoder(r.Body).Decode(&product)
+	err := decodeJSON(r, &product)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusBadRequest, "PUT", "/v1/products/{productId}", now)
+		h.buildBadRequestResponse(ctx, w, err, "PUT", "/v1/products/{productId}", now)
 		return
 	}
 	product.ID = &id
 
-	err = h.productSvc.UpdateProduct(ctx, product)
+	ifMatch, err := parseIfMatch(r, id)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PUT", "/v1/products/{productId}", now)
+		return
+	}
+
+	updated, err := h.productSvc.UpdateProduct(ctx, product, ifMatch)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "PUT", "/v1/products/{productId}", now)
+		h.buildErrorResponse(ctx, w, err, "PUT", "/v1/products/{productId}", now)
 		return
 	}
 
 	h.metrics.MeasureDuration(now, "PUT", "/v1/products/{productId}", "200")
 	h.metrics.IncReqByStatusCode("200")
 
+	w.Header().Set("ETag", formatETag(*updated.ID, updated.Version))
 	h.buildResponse(w, "Product updated", now, map[string]interface{}{"id": id})
 }
 
+func (h *productHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("PATCH product by ID request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	fields, err := decodeMergePatch(r)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PATCH", "/v1/products/{productId}", now)
+		return
+	}
+
+	patch, err := parseProductPatch(id, fields)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PATCH", "/v1/products/{productId}", now)
+		return
+	}
+
+	ifMatch, err := parseIfMatch(r, id)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "PATCH", "/v1/products/{productId}", now)
+		return
+	}
+
+	product, err := h.productSvc.PatchProduct(ctx, *patch, ifMatch)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "PATCH", "/v1/products/{productId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "PATCH", "/v1/products/{productId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	w.Header().Set("ETag", formatETag(*product.ID, product.Version))
+
+	h.buildResponse(w, "Product patched", now, map[string]interface{}{"product": product})
+}
+
 func (h *productHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	ctx := h.getContext(r)
@@ -164,9 +258,15 @@ func (h *productHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.productSvc.DeleteProductByID(ctx, id)
+	ifMatch, err := parseIfMatch(r, id)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "DELETE", "/v1/products/{productId}", now)
+		return
+	}
+
+	err = h.productSvc.DeleteProductByID(ctx, id, ifMatch)
 	if err != nil {
-		h.buildErrorResponse(w, err.Error(), http.StatusNotFound, "DELETE", "/v1/products/{productId}", now)
+		h.buildErrorResponse(ctx, w, err, "DELETE", "/v1/products/{productId}", now)
 		return
 	}
 
@@ -176,6 +276,87 @@ func (h *productHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	h.buildResponse(w, "Product deleted", now, map[string]interface{}{})
 }
 
+func (h *productHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST stock adjustment request", "traceID", ctx.Value("traceID"))
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var adjustment entity.StockAdjustment
+	err := decodeJSON(r, &adjustment)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/products/{productId}/stock-adjustments", now)
+		return
+	}
+	adjustment.ProductID = id
+
+	product, err := h.productSvc.AdjustStock(ctx, adjustment)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/products/{productId}/stock-adjustments", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/products/{productId}/stock-adjustments", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	w.Header().Set("ETag", formatETag(*product.ID, product.Version))
+
+	h.buildResponse(w, "Product stock adjusted", now, map[string]interface{}{"product": product})
+}
+
+func parseProductPatch(id string, fields map[string]json.RawMessage) (*entity.ProductPatch, error) {
+	patch := &entity.ProductPatch{ID: id}
+	invalidFields := make([]entity.FieldError, 0)
+	for _, field := range sortedKeys(fields) {
+		value := fields[field]
+		var err error
+		switch field {
+		case "name":
+			patch.Name = new(string)
+			err = patchValue(value, false, patch.Name)
+		case "description":
+			// removing the description clears it
+			patch.Description = new(string)
+			err = patchValue(value, true, patch.Description)
+		case "price":
+			patch.Price = new(float64)
+			err = patchValue(value, false, patch.Price)
+		case "quantity":
+			patch.Quantity = new(int64)
+			err = patchValue(value, false, patch.Quantity)
+		case "product_id", "sku", "created_at", "updated_at", "version":
+			err = fmt.Errorf("is read-only")
+		default:
+			err = fmt.Errorf("is unknown")
+		}
+		if err != nil {
+			invalidFields = append(invalidFields, entity.FieldError{Field: field, Message: err.Error()})
+		}
+	}
+
+	if len(invalidFields) > 0 {
+		return nil, &entity.ValidationError{Fields: invalidFields}
+	}
+	return patch, nil
+}
+
+// notModified sets the product ETag and answers 304 when the client already has that version
+func (h *productHandler) notModified(w http.ResponseWriter, r *http.Request, product *entity.Product, uri string, start time.Time) bool {
+	etag := formatETag(*product.ID, product.Version)
+	w.Header().Set("ETag", etag)
+	if !matchesIfNoneMatch(r, etag) {
+		return false
+	}
+
+	h.metrics.MeasureDuration(start, "GET", uri, "304")
+	h.metrics.IncReqByStatusCode("304")
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
 func (h *productHandler) getContext(r *http.Request) context.Context {
 	traceID := r.Header.Get("X-Trace-ID")
 	if traceID == "" {
@@ -183,16 +364,39 @@ func (h *productHandler) getContext(r *http.Request) context.Context {
 	}
 
 	ctx := context.WithValue(r.Context(), "traceID", traceID)
+	// recorded on the inventory movements of the request
+	ctx = context.WithValue(ctx, "actor", r.Header.Get("X-Actor"))
 	return ctx
 }
 
-func (h *productHandler) buildErrorResponse(w http.ResponseWriter, error string, statusCode int, method string, uri string, start time.Time) {
-	h.metrics.MeasureDuration(start, method, uri, fmt.Sprint(statusCode))
-	h.metrics.IncReqByStatusCode(fmt.Sprint(statusCode))
+// buildBadRequestResponse answers a request that couldn't be parsed, a parsed one with invalid
+// fields gets the same 422 the validation of the service answers
+func (h *productHandler) buildBadRequestResponse(ctx context.Context, w http.ResponseWriter, err error, method string, uri string, start time.Time) {
+	var validationErr *entity.ValidationError
+	if errors.As(err, &validationErr) || errors.Is(err, entity.ErrProductVersionMismatch) {
+		h.buildErrorResponse(ctx, w, err, method, uri, start)
+		return
+	}
+
+	traceID, _ := ctx.Value("traceID").(string)
+	h.writeProblem(w, newProblem(http.StatusBadRequest, err.Error(), traceID), method, uri, start)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	h.buildResponse(w, fmt.Sprintf("Error on %s product: %s", method, error), start, map[string]interface{}{})
+func (h *productHandler) buildErrorResponse(ctx context.Context, w http.ResponseWriter, err error, method string, uri string, start time.Time) {
+	traceID, _ := ctx.Value("traceID").(string)
+	p := errorProblem(err, traceID)
+	if p.Status >= http.StatusInternalServerError {
+		h.logger.Error(fmt.Sprintf("Error on %s product", method), "uri", uri, "error", err, "traceID", ctx.Value("traceID"))
+	}
+
+	h.writeProblem(w, p, method, uri, start)
+}
+
+func (h *productHandler) writeProblem(w http.ResponseWriter, p *problem, method string, uri string, start time.Time) {
+	h.metrics.MeasureDuration(start, method, uri, fmt.Sprint(p.Status))
+	h.metrics.IncReqByStatusCode(fmt.Sprint(p.Status))
+
+	writeProblem(w, p)
 }
 
 func (h *productHandler) buildResponse(w http.ResponseWriter, message string, start time.Time, data map[string]interface{}) {
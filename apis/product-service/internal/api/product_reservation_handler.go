@@ -0,0 +1,93 @@
+package api
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *productHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST reservation request", "traceID", ctx.Value("traceID"))
+
+	var reservation entity.Reservation
+	err := decodeJSON(r, &reservation)
+	if err != nil {
+		h.buildBadRequestResponse(ctx, w, err, "POST", "/v1/products/reservations", now)
+		return
+	}
+
+	created, err := h.productSvc.CreateReservation(ctx, reservation)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/products/reservations", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/products/reservations", "201")
+	h.metrics.IncReqByStatusCode("201")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	h.buildResponse(w, "Reservation created", now, map[string]interface{}{"reservation": created})
+}
+
+func (h *productHandler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("GET reservation by ID request", "traceID", ctx.Value("traceID"))
+
+	id := mux.Vars(r)["id"]
+
+	reservation, err := h.productSvc.GetReservationByID(ctx, id)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "GET", "/v1/products/reservations/{reservationId}", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "GET", "/v1/products/reservations/{reservationId}", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, fmt.Sprintf("Reservation by ID: %s", id), now, map[string]interface{}{"reservation": reservation})
+}
+
+func (h *productHandler) CommitReservation(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST reservation commit request", "traceID", ctx.Value("traceID"))
+
+	id := mux.Vars(r)["id"]
+
+	reservation, err := h.productSvc.CommitReservation(ctx, id)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/products/reservations/{reservationId}/commit", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/products/reservations/{reservationId}/commit", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Reservation committed", now, map[string]interface{}{"reservation": reservation})
+}
+
+func (h *productHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	ctx := h.getContext(r)
+	h.logger.Debug("POST reservation release request", "traceID", ctx.Value("traceID"))
+
+	id := mux.Vars(r)["id"]
+
+	reservation, err := h.productSvc.ReleaseReservation(ctx, id)
+	if err != nil {
+		h.buildErrorResponse(ctx, w, err, "POST", "/v1/products/reservations/{reservationId}/release", now)
+		return
+	}
+
+	h.metrics.MeasureDuration(now, "POST", "/v1/products/reservations/{reservationId}/release", "200")
+	h.metrics.IncReqByStatusCode("200")
+
+	h.buildResponse(w, "Reservation released", now, map[string]interface{}{"reservation": reservation})
+}
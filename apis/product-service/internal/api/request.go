@@ -0,0 +1,32 @@
+package api
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// decodeJSON decodes a request body into v, rejecting the fields v doesn't have. Unknown
+// and mistyped fields are reported as field errors, like the ones of the validation.
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &entity.ValidationError{Fields: []entity.FieldError{{Field: strings.Trim(field, "\""), Message: "is unknown"}}}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &entity.ValidationError{Fields: []entity.FieldError{{Field: typeErr.Field, Message: fmt.Sprintf("must be a %s", typeErr.Type)}}}
+	}
+
+	return fmt.Errorf("Body must be a JSON object: %s", err)
+}
@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// Category is a node of the category tree, Parent is the slug of its parent category, nil on the roots.
+// Children is only filled when the whole tree is read.
+type Category struct {
+	ID     *string `json:"category_id"`
+	Slug   string  `json:"slug"`
+	Name   string  `json:"name"`
+	Parent *string `json:"parent"`
+
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt *time.Time  `json:"updated_at"`
+	Children  []*Category `json:"children,omitempty"`
+}
+
+// ProductCategories is the set of categories of a product, by slug
+type ProductCategories struct {
+	ProductID string   `json:"-"`
+	Slugs     []string `json:"categories"`
+}
+
+// ProductFilter narrows the product list to the products of any of the categories, or of their subcategories
+type ProductFilter struct {
+	Categories []string
+}
+
+// CategoryProductFilter pages the products of a category and of its subcategories, by product ID
+type CategoryProductFilter struct {
+	Slug   string
+	Limit  int
+	Cursor string
+}
+
+type ProductPage struct {
+	Products   []*Product
+	NextCursor *string
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	// ErrProductVersionMismatch is returned when a conditional mutation targets an outdated version
+	ErrProductVersionMismatch = errors.New("product version does not match")
+	// ErrProductConflict is returned when a write would break a uniqueness rule, like a taken name
+	ErrProductConflict     = errors.New("product conflicts with an existing one")
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrInsufficientStock is returned when a product doesn't have the quantity a reservation asks for
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrReservationClosed is returned when a reservation that is no longer held is committed or released
+	ErrReservationClosed = errors.New("reservation is no longer held")
+	ErrCategoryNotFound  = errors.New("category not found")
+	// ErrCategoryConflict is returned when a category write would break the tree, like a taken slug
+	ErrCategoryConflict = errors.New("category conflicts with the existing ones")
+	// ErrUnavailable wraps the failures of a dependency that may succeed if retried later
+	ErrUnavailable = errors.New("service temporarily unavailable")
+)
+
+// FieldError describes why a single field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of a request, not only the first one found
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + " " + field.Message
+	}
+	return "Invalid fields: " + strings.Join(messages, "; ")
+}
+
+// InvalidField builds the ValidationError of a single field
+func InvalidField(field string, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: message}}}
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// Reasons of an inventory movement
+const (
+	MovementRestock     = "restock"
+	MovementSale        = "sale"
+	MovementReservation = "reservation"
+	MovementAdjustment  = "adjustment"
+	MovementReturn      = "return"
+)
+
+// InventoryMovement is an entry of the append-only stock ledger. Quantity is signed, negative when stock
+// left, so the quantity of a product is the sum of its movements.
+type InventoryMovement struct {
+	ID            int64   `json:"movement_id"`
+	ProductID     string  `json:"product_id"`
+	Reason        string  `json:"reason"`
+	Quantity      int64   `json:"quantity"`
+	ReservationID *string `json:"reservation_id,omitempty"`
+	Actor         string  `json:"actor"`
+	TraceID       *string `json:"trace_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MovementFilter pages the movements of a product, newest first
+type MovementFilter struct {
+	ProductID string
+	Limit     int
+	Cursor    string
+}
+
+type MovementPage struct {
+	Movements  []*InventoryMovement
+	NextCursor *string
+}
+
+// InventoryDrift is a product whose quantity doesn't match the sum of its movements
+type InventoryDrift struct {
+	ProductID      string
+	Name           string
+	Quantity       int64
+	LedgerQuantity int64
+}
+
+// StockAdjustment changes the quantity of a product by Delta, which is signed, Reason goes to the ledger
+type StockAdjustment struct {
+	ProductID string `json:"-"`
+	Delta     int64  `json:"delta"`
+	Reason    string `json:"reason"`
+}
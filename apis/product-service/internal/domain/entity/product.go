@@ -4,6 +4,7 @@ import "time"
 
 type Product struct {
 	ID          *string `json:"product_id" db:"product_id"`
+	SKU         string  `json:"sku" db:"sku"`
 	Name        string  `json:"name" db:"name"`
 	Description string  `json:"description" db:"description"`
 	Price       float64 `json:"price" db:"price"`
@@ -11,4 +12,18 @@ type Product struct {
 
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at" db:"updated_at"`
+	Version   int64      `json:"version" db:"version"`
+}
+
+// ProductPatch holds the fields sent in a merge patch, nil fields are left untouched
+type ProductPatch struct {
+	ID          string
+	Name        *string
+	Description *string
+	Price       *float64
+	Quantity    *int64
+}
+
+func (p ProductPatch) IsEmpty() bool {
+	return p.Name == nil && p.Description == nil && p.Price == nil && p.Quantity == nil
 }
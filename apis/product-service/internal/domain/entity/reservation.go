@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// Status of a reservation, only a held one can still be committed or released
+const (
+	ReservationHeld      = "held"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Reservation holds the stock of its products until it expires. Committing it keeps the stock
+// taken, releasing it or letting it expire gives the stock back.
+type Reservation struct {
+	ID        *string           `json:"reservation_id"`
+	Status    string            `json:"status"`
+	Items     []ReservationItem `json:"products"`
+	ExpiresAt time.Time         `json:"expires_at"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type ReservationItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int64  `json:"quantity"`
+}
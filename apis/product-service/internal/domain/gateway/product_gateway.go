@@ -3,13 +3,33 @@ package gateway
 import (
 	"cmd/product-service/internal/domain/entity"
 	"context"
+	"time"
 )
 
 type ProductGateway interface {
-	GetProductList(ctx context.Context) ([]*entity.Product, error)
+	GetProductList(ctx context.Context, filter entity.ProductFilter) ([]*entity.Product, error)
+	ExportProducts(ctx context.Context, filter entity.ProductFilter, write func(product *entity.Product) error) error
 	GetProductByID(ctx context.Context, productID string) (*entity.Product, error)
 	GetProductByName(ctx context.Context, productName string) (*entity.Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*entity.Product, error)
 	CreateProduct(ctx context.Context, product entity.Product) (*string, error)
-	UpdateProduct(ctx context.Context, product entity.Product) error
-	DeleteProductByID(ctx context.Context, productID string) error
+	UpdateProduct(ctx context.Context, product entity.Product, ifMatch *int64) error
+	PatchProduct(ctx context.Context, patch entity.ProductPatch, ifMatch *int64) error
+	DeleteProductByID(ctx context.Context, productID string, ifMatch *int64) error
+	AdjustStock(ctx context.Context, adjustment entity.StockAdjustment) (*entity.Product, error)
+	CreateReservation(ctx context.Context, items []entity.ReservationItem, ttl time.Duration) (*entity.Reservation, error)
+	GetReservationByID(ctx context.Context, reservationID string) (*entity.Reservation, error)
+	CommitReservation(ctx context.Context, reservationID string) (*entity.Reservation, error)
+	ReleaseReservation(ctx context.Context, reservationID string) (*entity.Reservation, error)
+	ExpireReservations(ctx context.Context, limit int) (int, error)
+	CountActiveReservations(ctx context.Context) (int, error)
+	GetProductMovements(ctx context.Context, filter entity.MovementFilter) (*entity.MovementPage, error)
+	GetInventoryDrift(ctx context.Context) ([]entity.InventoryDrift, error)
+	FixInventoryDrift(ctx context.Context, productID string) (*entity.InventoryDrift, error)
+	GetCategories(ctx context.Context) ([]*entity.Category, error)
+	CreateCategory(ctx context.Context, category entity.Category) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, slug string) error
+	GetProductCategories(ctx context.Context, productID string) ([]*entity.Category, error)
+	SetProductCategories(ctx context.Context, assignment entity.ProductCategories) ([]*entity.Category, error)
+	GetCategoryProducts(ctx context.Context, filter entity.CategoryProductFilter) (*entity.ProductPage, error)
 }
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	defaultProductPageLimit = 50
+	maxProductPageLimit     = 500
+)
+
+// GetCategoryTree reads every category and nests each one in its parent, the roots are returned
+func (s *productService) GetCategoryTree(ctx context.Context) ([]*entity.Category, error) {
+	s.logger.Info("Getting category tree", "traceID", ctx.Value("traceID"))
+	categories, err := s.productGtw.GetCategories(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get categories", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+func (s *productService) CreateCategory(ctx context.Context, category entity.Category) (*entity.Category, error) {
+	s.logger.Info("Creating new category", "data", category, "traceID", ctx.Value("traceID"))
+	category.Slug = normalizeSlug(category.Slug)
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Parent != nil {
+		parent := normalizeSlug(*category.Parent)
+		category.Parent = &parent
+	}
+	err := validateCategory(category)
+	if err != nil {
+		s.logger.Error("Invalid category", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	created, err := s.productGtw.CreateCategory(ctx, category)
+	if err != nil {
+		s.logger.Error("Failed to create category", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *productService) DeleteCategory(ctx context.Context, slug string) error {
+	s.logger.Info("Deleting category", "slug", slug, "traceID", ctx.Value("traceID"))
+	err := s.productGtw.DeleteCategory(ctx, normalizeSlug(slug))
+	if err != nil {
+		s.logger.Error("Failed to delete category", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
+
+func (s *productService) GetProductCategories(ctx context.Context, productID string) ([]*entity.Category, error) {
+	s.logger.Info("Getting product categories", "ID", productID, "traceID", ctx.Value("traceID"))
+	categories, err := s.productGtw.GetProductCategories(ctx, productID)
+	if err != nil {
+		s.logger.Error("Failed to get product categories", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// SetProductCategories replaces the categories of a product, an empty list takes it out of all of them
+func (s *productService) SetProductCategories(ctx context.Context, assignment entity.ProductCategories) ([]*entity.Category, error) {
+	s.logger.Info("Setting product categories", "data", assignment, "traceID", ctx.Value("traceID"))
+	for i, slug := range assignment.Slugs {
+		assignment.Slugs[i] = normalizeSlug(slug)
+	}
+	err := validateProductCategories(assignment)
+	if err != nil {
+		s.logger.Error("Invalid product categories", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	categories, err := s.productGtw.SetProductCategories(ctx, assignment)
+	if err != nil {
+		s.logger.Error("Failed to set product categories", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (s *productService) GetCategoryProducts(ctx context.Context, filter entity.CategoryProductFilter) (*entity.ProductPage, error) {
+	s.logger.Info("Getting category products", "filter", filter, "traceID", ctx.Value("traceID"))
+	filter.Slug = normalizeSlug(filter.Slug)
+	if filter.Limit == 0 {
+		filter.Limit = defaultProductPageLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxProductPageLimit {
+		return nil, entity.InvalidField("limit", fmt.Sprintf("must be between 1 and %d", maxProductPageLimit))
+	}
+
+	page, err := s.productGtw.GetCategoryProducts(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get category products", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// buildCategoryTree keeps the order of categories among siblings, a category whose parent isn't
+// in the list is taken as a root
+func buildCategoryTree(categories []*entity.Category) []*entity.Category {
+	bySlug := make(map[string]*entity.Category, len(categories))
+	for _, category := range categories {
+		category.Children = make([]*entity.Category, 0)
+		bySlug[category.Slug] = category
+	}
+
+	roots := make([]*entity.Category, 0)
+	for _, category := range categories {
+		if category.Parent != nil {
+			if parent, ok := bySlug[*category.Parent]; ok {
+				parent.Children = append(parent.Children, category)
+				continue
+			}
+		}
+		roots = append(roots, category)
+	}
+
+	return roots
+}
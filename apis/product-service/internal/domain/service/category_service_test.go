@@ -0,0 +1,36 @@
+package service
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BuildCategoryTree(t *testing.T) {
+	parent := func(slug string) *string { return &slug }
+	categories := []*entity.Category{
+		{Slug: "electronics"},
+		{Slug: "phones", Parent: parent("electronics")},
+		{Slug: "adapters", Parent: parent("phones")},
+		{Slug: "cables", Parent: parent("electronics")},
+		// its parent isn't in the list, so it's shown as a root instead of being lost
+		{Slug: "chargers", Parent: parent("accessories")},
+		{Slug: "books"},
+	}
+
+	roots := buildCategoryTree(categories)
+
+	slugs := func(categories []*entity.Category) []string {
+		result := make([]string, 0, len(categories))
+		for _, category := range categories {
+			result = append(result, category.Slug)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"electronics", "chargers", "books"}, slugs(roots))
+	assert.Equal(t, []string{"phones", "cables"}, slugs(roots[0].Children))
+	assert.Equal(t, []string{"adapters"}, slugs(roots[0].Children[0].Children))
+	assert.Empty(t, roots[1].Children)
+	assert.Empty(t, roots[2].Children)
+}
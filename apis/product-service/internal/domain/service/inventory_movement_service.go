@@ -0,0 +1,73 @@
+package service
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	defaultMovementLimit = 50
+	maxMovementLimit     = 500
+)
+
+func (s *productService) GetProductMovements(ctx context.Context, filter entity.MovementFilter) (*entity.MovementPage, error) {
+	s.logger.Info("Getting product movements", "filter", filter, "traceID", ctx.Value("traceID"))
+	err := normalizeMovementFilter(&filter)
+	if err != nil {
+		s.logger.Error("Invalid movement filter", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	page, err := s.productGtw.GetProductMovements(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to get product movements", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// ReconcileInventory lists the products whose quantity drifted from their ledger. With fix, an adjustment
+// is appended to the ledger of each one, the quantity of the product is taken as the truth.
+func (s *productService) ReconcileInventory(ctx context.Context, fix bool) ([]entity.InventoryDrift, error) {
+	s.logger.Info("Reconciling inventory", "fix", fix, "traceID", ctx.Value("traceID"))
+	drifts, err := s.productGtw.GetInventoryDrift(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get inventory drift", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+	if !fix {
+		return drifts, nil
+	}
+
+	// the drift is read again under lock, a product may have been fixed, changed or deleted since it was listed
+	fixed := make([]entity.InventoryDrift, 0, len(drifts))
+	for _, drift := range drifts {
+		current, err := s.productGtw.FixInventoryDrift(ctx, drift.ProductID)
+		if errors.Is(err, entity.ErrProductNotFound) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error("Failed to fix inventory drift", "ID", drift.ProductID, "error", err, "traceID", ctx.Value("traceID"))
+			return fixed, err
+		}
+		if current != nil {
+			fixed = append(fixed, *current)
+		}
+	}
+
+	return fixed, nil
+}
+
+func normalizeMovementFilter(filter *entity.MovementFilter) error {
+	if filter.Limit == 0 {
+		filter.Limit = defaultMovementLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxMovementLimit {
+		return entity.InvalidField("limit", fmt.Sprintf("must be between 1 and %d", maxMovementLimit))
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// expireBatchSize reservations are expired per transaction, so the reaper never locks many products at once
+const expireBatchSize = 100
+
+type ReservationConfig struct {
+	// TTL is how long a reservation holds its stock before the reaper gives it back
+	TTL time.Duration
+	// ReaperInterval is how often expired reservations are looked for
+	ReaperInterval time.Duration
+}
+
+func GetReservationConfig() (*ReservationConfig, error) {
+	ttl, err := time.ParseDuration(os.Getenv("RESERVATION_TTL"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get RESERVATION_TTL from .env: %s", err)
+	}
+
+	interval, err := time.ParseDuration(os.Getenv("RESERVATION_REAPER_INTERVAL"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get RESERVATION_REAPER_INTERVAL from .env: %s", err)
+	}
+
+	return &ReservationConfig{
+		TTL:            ttl,
+		ReaperInterval: interval,
+	}, nil
+}
+
+func (s *productService) CreateReservation(ctx context.Context, reservation entity.Reservation) (*entity.Reservation, error) {
+	s.logger.Info("Creating reservation", "data", reservation.Items, "traceID", ctx.Value("traceID"))
+	err := validateReservation(reservation)
+	if err != nil {
+		s.logger.Error("Invalid reservation", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	// sorted like the gateway locks the products, so the first product missing stock is the one reported
+	items := append([]entity.ReservationItem{}, reservation.Items...)
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+	created, err := s.productGtw.CreateReservation(ctx, items, s.reservationTTL)
+	if err != nil {
+		s.logger.Error("Failed to create reservation", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *productService) GetReservationByID(ctx context.Context, reservationID string) (*entity.Reservation, error) {
+	s.logger.Info("Getting reservation by ID", "ID", reservationID, "traceID", ctx.Value("traceID"))
+	reservation, err := s.productGtw.GetReservationByID(ctx, reservationID)
+	if err != nil {
+		s.logger.Error("Failed to get reservation by ID", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (s *productService) CommitReservation(ctx context.Context, reservationID string) (*entity.Reservation, error) {
+	s.logger.Info("Committing reservation", "ID", reservationID, "traceID", ctx.Value("traceID"))
+	reservation, err := s.productGtw.CommitReservation(ctx, reservationID)
+	if err != nil {
+		s.logger.Error("Failed to commit reservation", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (s *productService) ReleaseReservation(ctx context.Context, reservationID string) (*entity.Reservation, error) {
+	s.logger.Info("Releasing reservation", "ID", reservationID, "traceID", ctx.Value("traceID"))
+	reservation, err := s.productGtw.ReleaseReservation(ctx, reservationID)
+	if err != nil {
+		s.logger.Error("Failed to release reservation", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// ExpireReservations gives the stock of every expired reservation back, in batches of expireBatchSize,
+// and returns how many expired and how many are still held afterwards
+func (s *productService) ExpireReservations(ctx context.Context) (int, int, error) {
+	expired := 0
+	for {
+		count, err := s.productGtw.ExpireReservations(ctx, expireBatchSize)
+		if err != nil {
+			s.logger.Error("Failed to expire reservations", "error", err, "traceID", ctx.Value("traceID"))
+			return expired, 0, err
+		}
+		expired += count
+		if count < expireBatchSize {
+			break
+		}
+	}
+	if expired > 0 {
+		s.logger.Info("Expired reservations", "size", expired, "traceID", ctx.Value("traceID"))
+	}
+
+	active, err := s.productGtw.CountActiveReservations(ctx)
+	if err != nil {
+		s.logger.Error("Failed to count active reservations", "error", err, "traceID", ctx.Value("traceID"))
+		return expired, 0, err
+	}
+
+	return expired, active, nil
+}
@@ -4,33 +4,61 @@ import (
 	"cmd/product-service/internal/domain/entity"
 	"cmd/product-service/internal/domain/gateway"
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 )
 
 type ProductService interface {
-	GetProductList(ctx context.Context) ([]*entity.Product, error)
+	GetProductList(ctx context.Context, filter entity.ProductFilter) ([]*entity.Product, error)
+	ExportProducts(ctx context.Context, filter entity.ProductFilter, write func(product *entity.Product) error) error
 	GetProductByID(ctx context.Context, productID string) (*entity.Product, error)
 	GetProductByName(ctx context.Context, productName string) (*entity.Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*entity.Product, error)
 	CreateProduct(ctx context.Context, product entity.Product) (*string, error)
-	UpdateProduct(ctx context.Context, product entity.Product) error
-	DeleteProductByID(ctx context.Context, productID string) error
+	UpdateProduct(ctx context.Context, product entity.Product, ifMatch *int64) (*entity.Product, error)
+	PatchProduct(ctx context.Context, patch entity.ProductPatch, ifMatch *int64) (*entity.Product, error)
+	DeleteProductByID(ctx context.Context, productID string, ifMatch *int64) error
+	AdjustStock(ctx context.Context, adjustment entity.StockAdjustment) (*entity.Product, error)
+	CreateReservation(ctx context.Context, reservation entity.Reservation) (*entity.Reservation, error)
+	GetReservationByID(ctx context.Context, reservationID string) (*entity.Reservation, error)
+	CommitReservation(ctx context.Context, reservationID string) (*entity.Reservation, error)
+	ReleaseReservation(ctx context.Context, reservationID string) (*entity.Reservation, error)
+	ExpireReservations(ctx context.Context) (int, int, error)
+	GetProductMovements(ctx context.Context, filter entity.MovementFilter) (*entity.MovementPage, error)
+	ReconcileInventory(ctx context.Context, fix bool) ([]entity.InventoryDrift, error)
+	GetCategoryTree(ctx context.Context) ([]*entity.Category, error)
+	CreateCategory(ctx context.Context, category entity.Category) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, slug string) error
+	GetProductCategories(ctx context.Context, productID string) ([]*entity.Category, error)
+	SetProductCategories(ctx context.Context, assignment entity.ProductCategories) ([]*entity.Category, error)
+	GetCategoryProducts(ctx context.Context, filter entity.CategoryProductFilter) (*entity.ProductPage, error)
 }
 
 type productService struct {
-	logger     slog.Logger
-	productGtw gateway.ProductGateway
+	logger         slog.Logger
+	productGtw     gateway.ProductGateway
+	reservationTTL time.Duration
 }
 
-func NewProductService(l slog.Logger, g gateway.ProductGateway) ProductService {
+func NewProductService(l slog.Logger, g gateway.ProductGateway, reservationTTL time.Duration) ProductService {
 	return &productService{
-		logger:     *l.With("layer", "product-service"),
-		productGtw: g,
+		logger:         *l.With("layer", "product-service"),
+		productGtw:     g,
+		reservationTTL: reservationTTL,
 	}
 }
 
-func (s *productService) GetProductList(ctx context.Context) ([]*entity.Product, error) {
-	s.logger.Info("Getting all products", "traceID", ctx.Value("traceID"))
-	productList, err := s.productGtw.GetProductList(ctx)
+func (s *productService) GetProductList(ctx context.Context, filter entity.ProductFilter) ([]*entity.Product, error) {
+	s.logger.Info("Getting all products", "filter", filter, "traceID", ctx.Value("traceID"))
+	err := normalizeProductFilter(&filter)
+	if err != nil {
+		s.logger.Error("Invalid product filter", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	productList, err := s.productGtw.GetProductList(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +66,23 @@ func (s *productService) GetProductList(ctx context.Context) ([]*entity.Product,
 	return productList, nil
 }
 
+func (s *productService) ExportProducts(ctx context.Context, filter entity.ProductFilter, write func(product *entity.Product) error) error {
+	s.logger.Info("Exporting products", "filter", filter, "traceID", ctx.Value("traceID"))
+	err := normalizeProductFilter(&filter)
+	if err != nil {
+		s.logger.Error("Invalid product export filter", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	err = s.productGtw.ExportProducts(ctx, filter, write)
+	if err != nil {
+		s.logger.Error("Failed to export products", "error", err, "traceID", ctx.Value("traceID"))
+		return err
+	}
+
+	return nil
+}
+
 func (s *productService) GetProductByID(ctx context.Context, productID string) (*entity.Product, error) {
 	s.logger.Info("Getting product by ID", "ID", productID, "traceID", ctx.Value("traceID"))
 	product, err := s.productGtw.GetProductByID(ctx, productID)
@@ -60,8 +105,27 @@ func (s *productService) GetProductByName(ctx context.Context, productName strin
 	return product, nil
 }
 
+func (s *productService) GetProductBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	s.logger.Info("Getting product by SKU", "sku", sku, "traceID", ctx.Value("traceID"))
+	product, err := s.productGtw.GetProductBySKU(ctx, normalizeSKU(sku))
+	if err != nil {
+		s.logger.Error("Failed to get product by SKU", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return product, nil
+}
+
 func (s *productService) CreateProduct(ctx context.Context, product entity.Product) (*string, error) {
 	s.logger.Info("Creating new product", "data", product, "traceID", ctx.Value("traceID"))
+	product.Name = strings.TrimSpace(product.Name)
+	product.SKU = normalizeSKU(product.SKU)
+	err := validateProduct(product, true)
+	if err != nil {
+		s.logger.Error("Invalid product", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
 	id, err := s.productGtw.CreateProduct(ctx, product)
 	if err != nil {
 		s.logger.Error("Failed to create product", "error", err, "traceID", ctx.Value("traceID"))
@@ -71,20 +135,79 @@ func (s *productService) CreateProduct(ctx context.Context, product entity.Produ
 	return id, nil
 }
 
-func (s *productService) UpdateProduct(ctx context.Context, product entity.Product) error {
+func (s *productService) UpdateProduct(ctx context.Context, product entity.Product, ifMatch *int64) (*entity.Product, error) {
 	s.logger.Info("Updating product", "data", product)
-	err := s.productGtw.UpdateProduct(ctx, product)
+	product.Name = strings.TrimSpace(product.Name)
+	product.SKU = normalizeSKU(product.SKU)
+	err := validateProduct(product, false)
+	if err != nil {
+		s.logger.Error("Invalid product", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	// the SKU may be sent back as it is, but never changed
+	if product.SKU != "" {
+		current, err := s.productGtw.GetProductByID(ctx, *product.ID)
+		if err != nil {
+			s.logger.Error("Failed to get product to update", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		if current.SKU != product.SKU {
+			return nil, entity.InvalidField("sku", "is immutable")
+		}
+	}
+
+	err = s.productGtw.UpdateProduct(ctx, product, ifMatch)
 	if err != nil {
 		s.logger.Error("Failed to update product by ID", "error", err, "traceID", ctx.Value("traceID"))
-		return err
+		return nil, err
 	}
 
-	return nil
+	updated, err := s.productGtw.GetProductByID(ctx, *product.ID)
+	if err != nil {
+		s.logger.Error("Failed to get updated product", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return updated, nil
 }
 
-func (s *productService) DeleteProductByID(ctx context.Context, productID string) error {
+func (s *productService) PatchProduct(ctx context.Context, patch entity.ProductPatch, ifMatch *int64) (*entity.Product, error) {
+	s.logger.Info("Patching product", "data", patch, "traceID", ctx.Value("traceID"))
+	if patch.Name != nil {
+		name := strings.TrimSpace(*patch.Name)
+		patch.Name = &name
+	}
+	err := validateProductPatch(patch)
+	if err != nil {
+		s.logger.Error("Invalid product patch", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	// an empty merge patch changes nothing, it just returns the current product
+	if !patch.IsEmpty() {
+		err = s.productGtw.PatchProduct(ctx, patch, ifMatch)
+		if err != nil {
+			s.logger.Error("Failed to patch product by ID", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+	}
+
+	product, err := s.productGtw.GetProductByID(ctx, patch.ID)
+	if err != nil {
+		s.logger.Error("Failed to get patched product", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+	if patch.IsEmpty() && ifMatch != nil && product.Version != *ifMatch {
+		return nil, fmt.Errorf("%w with ID=%s, expected version %d", entity.ErrProductVersionMismatch, patch.ID, *ifMatch)
+	}
+
+	return product, nil
+}
+
+func (s *productService) DeleteProductByID(ctx context.Context, productID string, ifMatch *int64) error {
 	s.logger.Info("Deleting product by ID", "ID", productID)
-	err := s.productGtw.DeleteProductByID(ctx, productID)
+	err := s.productGtw.DeleteProductByID(ctx, productID, ifMatch)
 	if err != nil {
 		s.logger.Error("Failed to delete product by ID", "error", err, "traceID", ctx.Value("traceID"))
 		return err
@@ -92,3 +215,23 @@ func (s *productService) DeleteProductByID(ctx context.Context, productID string
 
 	return nil
 }
+
+func (s *productService) AdjustStock(ctx context.Context, adjustment entity.StockAdjustment) (*entity.Product, error) {
+	s.logger.Info("Adjusting product stock", "data", adjustment, "traceID", ctx.Value("traceID"))
+	if adjustment.Reason == "" {
+		adjustment.Reason = entity.MovementAdjustment
+	}
+	err := validateStockAdjustment(adjustment)
+	if err != nil {
+		s.logger.Error("Invalid stock adjustment", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	product, err := s.productGtw.AdjustStock(ctx, adjustment)
+	if err != nil {
+		s.logger.Error("Failed to adjust product stock", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, err
+	}
+
+	return product, nil
+}
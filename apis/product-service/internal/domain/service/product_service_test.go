@@ -0,0 +1,69 @@
+package service
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"cmd/product-service/internal/domain/gateway"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProductGateway keeps a single product, the methods it doesn't override aren't expected to be called
+type fakeProductGateway struct {
+	gateway.ProductGateway
+	product *entity.Product
+	reads   int
+	updated *entity.Product
+}
+
+func (g *fakeProductGateway) GetProductByID(ctx context.Context, productID string) (*entity.Product, error) {
+	g.reads++
+	product := *g.product
+	if g.updated != nil {
+		product = *g.updated
+		product.SKU = g.product.SKU
+	}
+	return &product, nil
+}
+
+func (g *fakeProductGateway) UpdateProduct(ctx context.Context, product entity.Product, ifMatch *int64) error {
+	g.updated = &product
+	return nil
+}
+
+func Test_ProductService_UpdateProduct_SKU(t *testing.T) {
+	productID := "01HZ7E8GR7SBPV9F96XRR5HCW1"
+	scenarios := []struct {
+		name          string
+		sku           string
+		invalidFields []string
+		reads         int
+	}{
+		{"same SKU", "SK515276", nil, 2},
+		{"same SKU once normalized", " sk515276 ", nil, 2},
+		{"SKU left out", "", nil, 1},
+		{"changed SKU", "SK999999", []string{"sku"}, 1},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			gtw := &fakeProductGateway{product: &entity.Product{ID: &productID, SKU: "SK515276", Name: "Adapter", Price: 9.9, Quantity: 3}}
+			s := NewProductService(*slog.New(slog.NewTextHandler(io.Discard, nil)), gtw, 0)
+
+			product, err := s.UpdateProduct(context.Background(), entity.Product{ID: &productID, SKU: tt.sku, Name: "Charger", Price: 19.9, Quantity: 3}, nil)
+			assert.Equal(t, tt.invalidFields, invalidFields(t, err))
+			assert.Equal(t, tt.reads, gtw.reads)
+			if tt.invalidFields != nil {
+				assert.Nil(t, product)
+				assert.Nil(t, gtw.updated)
+				return
+			}
+			assert.Equal(t, "Charger", product.Name)
+			assert.Equal(t, "SK515276", product.SKU)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the columns of the products table
+const (
+	maxNameLength = 200
+	maxPrice      = 99999999.99
+	maxQuantity   = math.MaxInt32
+)
+
+// skuPattern matches the normalized SKUs, like SK515276
+var skuPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9-]{1,31}$`)
+
+// normalizeSKU makes SKUs case insensitive, they are stored and looked up in upper case
+func normalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+// slugPattern matches the normalized slugs of categories, like phone-adapters
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+const (
+	maxSlugLength = 100
+	// maxProductCategories bounds the categories a product is in
+	maxProductCategories = 20
+)
+
+// normalizeSlug makes slugs case insensitive, they are stored and looked up in lower case
+func normalizeSlug(slug string) string {
+	return strings.ToLower(strings.TrimSpace(slug))
+}
+
+// maxReservationItems bounds the products locked by a single reservation
+const maxReservationItems = 100
+
+// adjustmentReasons are the movement reasons a client may give, reservations record their own
+var adjustmentReasons = []string{entity.MovementRestock, entity.MovementSale, entity.MovementAdjustment, entity.MovementReturn}
+
+// fieldErrors collects every invalid field before failing, so clients can fix them all at once
+type fieldErrors []entity.FieldError
+
+func (f *fieldErrors) add(field string, message string) {
+	*f = append(*f, entity.FieldError{Field: field, Message: message})
+}
+
+func (f fieldErrors) err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	return &entity.ValidationError{Fields: f}
+}
+
+// validateProduct checks a full product, as sent on create and update. The description is optional,
+// the SKU is only required on create since an update can't change it.
+func validateProduct(product entity.Product, create bool) error {
+	errs := fieldErrors{}
+	if create || product.SKU != "" {
+		checkSKU(&errs, product.SKU)
+	}
+	checkName(&errs, product.Name)
+	checkPrice(&errs, product.Price)
+	checkQuantity(&errs, product.Quantity)
+	return errs.err()
+}
+
+// validateProductPatch only checks the fields present in the patch
+func validateProductPatch(patch entity.ProductPatch) error {
+	errs := fieldErrors{}
+	if patch.Name != nil {
+		checkName(&errs, *patch.Name)
+	}
+	if patch.Price != nil {
+		checkPrice(&errs, *patch.Price)
+	}
+	if patch.Quantity != nil {
+		checkQuantity(&errs, *patch.Quantity)
+	}
+	return errs.err()
+}
+
+// validateReservation checks the products of a new reservation, a product can only be listed once
+func validateReservation(reservation entity.Reservation) error {
+	errs := fieldErrors{}
+	if len(reservation.Items) == 0 || len(reservation.Items) > maxReservationItems {
+		errs.add("products", fmt.Sprintf("must have between 1 and %d products", maxReservationItems))
+	}
+
+	seen := make(map[string]bool, len(reservation.Items))
+	for i, item := range reservation.Items {
+		field := fmt.Sprintf("products[%d]", i)
+		switch {
+		case strings.TrimSpace(item.ProductID) == "":
+			errs.add(field+".product_id", "is required")
+		case seen[item.ProductID]:
+			errs.add(field+".product_id", "is repeated")
+		}
+		seen[item.ProductID] = true
+		if item.Quantity < 1 || item.Quantity > maxQuantity {
+			errs.add(field+".quantity", fmt.Sprintf("must be between 1 and %d", maxQuantity))
+		}
+	}
+	return errs.err()
+}
+
+// validateStockAdjustment checks a relative change of stock, the stock it leads to is checked by the update
+func validateStockAdjustment(adjustment entity.StockAdjustment) error {
+	errs := fieldErrors{}
+	if adjustment.Delta == 0 || adjustment.Delta < -maxQuantity || adjustment.Delta > maxQuantity {
+		errs.add("delta", fmt.Sprintf("must be between -%d and %d and not 0", maxQuantity, maxQuantity))
+	}
+	if !slices.Contains(adjustmentReasons, adjustment.Reason) {
+		errs.add("reason", fmt.Sprintf("must be one of %s", strings.Join(adjustmentReasons, ", ")))
+	}
+	return errs.err()
+}
+
+// validateCategory checks a new category, the parent is optional and only needs to be a slug here
+func validateCategory(category entity.Category) error {
+	errs := fieldErrors{}
+	checkSlug(&errs, "slug", category.Slug)
+	checkName(&errs, category.Name)
+	if category.Parent != nil {
+		checkSlug(&errs, "parent", *category.Parent)
+	}
+	return errs.err()
+}
+
+// validateProductCategories checks the slugs a product is assigned to, a slug can only be listed once
+func validateProductCategories(assignment entity.ProductCategories) error {
+	errs := fieldErrors{}
+	if assignment.Slugs == nil {
+		errs.add("categories", "is required")
+	}
+	if len(assignment.Slugs) > maxProductCategories {
+		errs.add("categories", fmt.Sprintf("must have at most %d categories", maxProductCategories))
+	}
+	checkSlugs(&errs, "categories", assignment.Slugs)
+	return errs.err()
+}
+
+// normalizeProductFilter normalizes the category slugs of a list or export filter and checks them
+func normalizeProductFilter(filter *entity.ProductFilter) error {
+	categories := make([]string, len(filter.Categories))
+	for i, slug := range filter.Categories {
+		categories[i] = normalizeSlug(slug)
+	}
+	filter.Categories = categories
+	return validateProductFilter(*filter)
+}
+
+// validateProductFilter checks the categories the product list is filtered by
+func validateProductFilter(filter entity.ProductFilter) error {
+	errs := fieldErrors{}
+	if len(filter.Categories) > maxProductCategories {
+		errs.add("category", fmt.Sprintf("must have at most %d categories", maxProductCategories))
+	}
+	checkSlugs(&errs, "category", filter.Categories)
+	return errs.err()
+}
+
+func checkSlugs(errs *fieldErrors, field string, slugs []string) {
+	seen := make(map[string]bool, len(slugs))
+	for i, slug := range slugs {
+		itemField := fmt.Sprintf("%s[%d]", field, i)
+		if seen[slug] {
+			errs.add(itemField, "is repeated")
+			continue
+		}
+		seen[slug] = true
+		checkSlug(errs, itemField, slug)
+	}
+}
+
+func checkSlug(errs *fieldErrors, field string, value string) {
+	if len(value) > maxSlugLength || !slugPattern.MatchString(value) {
+		errs.add(field, fmt.Sprintf("must have at most %d lowercase letters, digits or single hyphens between them", maxSlugLength))
+	}
+}
+
+func checkSKU(errs *fieldErrors, value string) {
+	if !skuPattern.MatchString(value) {
+		errs.add("sku", "must have between 2 and 32 letters, digits or hyphens, starting with a letter or digit")
+	}
+}
+
+func checkName(errs *fieldErrors, value string) {
+	// the length counts the blanks, they're stored unless the caller trimmed them
+	if strings.TrimSpace(value) == "" || utf8.RuneCountInString(value) > maxNameLength {
+		errs.add("name", fmt.Sprintf("must have between 1 and %d characters", maxNameLength))
+	}
+}
+
+func checkPrice(errs *fieldErrors, value float64) {
+	cents := value * 100
+	if value < 0 || value > maxPrice || math.Abs(cents-math.Round(cents)) > 1e-6 {
+		errs.add("price", fmt.Sprintf("must be between 0 and %.2f with at most 2 decimals", maxPrice))
+	}
+}
+
+func checkQuantity(errs *fieldErrors, value int64) {
+	if value < 0 || value > maxQuantity {
+		errs.add("quantity", fmt.Sprintf("must be between 0 and %d", maxQuantity))
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func invalidFields(t *testing.T, err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *entity.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	fields := make([]string, 0)
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	return fields
+}
+
+func Test_ValidateProduct(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		product       entity.Product
+		invalidFields []string
+	}{
+		{"valid product", entity.Product{SKU: "SK515276", Name: "Adapter", Price: 9.9, Quantity: 3}, nil},
+		{"blank name", entity.Product{SKU: "SK515276", Name: "  ", Price: 9.9}, []string{"name"}},
+		{"padded name too long", entity.Product{SKU: "SK515276", Name: " " + strings.Repeat("a", maxNameLength) + " ", Price: 9.9}, []string{"name"}},
+		{"missing SKU and negative price", entity.Product{Name: "Adapter", Price: -1}, []string{"sku", "price"}},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateProduct(tt.product, true)
+			assert.Equal(t, tt.invalidFields, invalidFields(t, err))
+		})
+	}
+}
+
+func Test_ValidateReservation(t *testing.T) {
+	tooMany := make([]entity.ReservationItem, maxReservationItems+1)
+	for i := range tooMany {
+		tooMany[i] = entity.ReservationItem{ProductID: strings.Repeat("p", i+1), Quantity: 1}
+	}
+
+	scenarios := []struct {
+		name          string
+		items         []entity.ReservationItem
+		invalidFields []string
+	}{
+		{"valid reservation", []entity.ReservationItem{{ProductID: "p1", Quantity: 2}, {ProductID: "p2", Quantity: 1}}, nil},
+		{"no products", nil, []string{"products"}},
+		{"too many products", tooMany, []string{"products"}},
+		{"repeated product", []entity.ReservationItem{{ProductID: "p1", Quantity: 1}, {ProductID: "p1", Quantity: 1}}, []string{"products[1].product_id"}},
+		{"blank product and zero quantity", []entity.ReservationItem{{ProductID: " ", Quantity: 0}}, []string{"products[0].product_id", "products[0].quantity"}},
+		{"quantity out of range", []entity.ReservationItem{{ProductID: "p1", Quantity: maxQuantity + 1}}, []string{"products[0].quantity"}},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateReservation(entity.Reservation{Items: tt.items})
+			assert.Equal(t, tt.invalidFields, invalidFields(t, err))
+		})
+	}
+}
+
+func Test_ValidateStockAdjustment(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		adjustment    entity.StockAdjustment
+		invalidFields []string
+	}{
+		{"restock", entity.StockAdjustment{Delta: 10, Reason: entity.MovementRestock}, nil},
+		{"sale", entity.StockAdjustment{Delta: -3, Reason: entity.MovementSale}, nil},
+		{"no change", entity.StockAdjustment{Delta: 0, Reason: entity.MovementAdjustment}, []string{"delta"}},
+		{"delta out of range", entity.StockAdjustment{Delta: -maxQuantity - 1, Reason: entity.MovementAdjustment}, []string{"delta"}},
+		{"reservation reason", entity.StockAdjustment{Delta: 1, Reason: entity.MovementReservation}, []string{"reason"}},
+		{"unknown reason", entity.StockAdjustment{Delta: 1, Reason: "gift"}, []string{"reason"}},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStockAdjustment(tt.adjustment)
+			assert.Equal(t, tt.invalidFields, invalidFields(t, err))
+		})
+	}
+}
@@ -13,6 +13,9 @@ type ProductMetrics struct {
 	ReqByStatusCode  *prometheus.CounterVec
 	Duration         *prometheus.HistogramVec
 	ExternalDuration *prometheus.HistogramVec
+	// Reservations counts the reservations that reached each status, ActiveReservations is refreshed by the reaper
+	Reservations       *prometheus.CounterVec
+	ActiveReservations *prometheus.GaugeVec
 }
 
 var bucket = []float64{0.0, 0.001, 0.002, 0.003, 0.005, 0.007, 0.009, 0.01, 0.015, 0.02, 0.023, 0.025, 0.027, 0.029, 0.03, 0.031, 0.033, 0.035, 0.04, 0.05, 0.1, 0.15, 0.2, 0.25, 0.3}
@@ -36,9 +39,17 @@ func NewProductMetrics(l slog.Logger, reg prometheus.Registerer) *ProductMetrics
 			Help:    "Duration of external request",
 			Buckets: bucket},
 			[]string{"service", "resource", "status", "method", "uri"}),
+		Reservations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "product_reservations_total",
+			Help: "Reservations by the status they reached"},
+			[]string{"service", "status"}),
+		ActiveReservations: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "product_reservations_active",
+			Help: "Reservations currently holding stock"},
+			[]string{"service"}),
 	}
 
-	reg.MustRegister(m.ReqByStatusCode, m.Duration, m.ExternalDuration)
+	reg.MustRegister(m.ReqByStatusCode, m.Duration, m.ExternalDuration, m.Reservations, m.ActiveReservations)
 	return m
 }
 
@@ -64,3 +75,11 @@ func (m *ProductMetrics) MeasureExternalDuration(start time.Time, resource strin
 		"status":   statusCode,
 	}).Observe(float64(time.Since(start).Seconds()))
 }
+
+func (m *ProductMetrics) AddReservations(status string, count int) {
+	m.Reservations.With(prometheus.Labels{"service": m.service, "status": status}).Add(float64(count))
+}
+
+func (m *ProductMetrics) SetActiveReservations(count int) {
+	m.ActiveReservations.With(prometheus.Labels{"service": m.service}).Set(float64(count))
+}
@@ -0,0 +1,346 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// categoryTree has the categories of $1 and all of their descendants. UNION leaves out the categories
+// already found, so the recursion ends even if the tree was broken into a cycle.
+const categoryTree = `WITH RECURSIVE tree AS (
+		SELECT category_id FROM categories WHERE category_id = ANY($1)
+		UNION
+		SELECT c.category_id FROM categories c JOIN tree t ON c.parent_id = t.category_id
+	)`
+
+const selectCategories = `SELECT c.category_id, c.slug, c.name, parent.slug, c.created_at, c.updated_at
+	FROM categories c LEFT JOIN categories parent ON parent.category_id = c.parent_id`
+
+type productCursor struct {
+	ID string `json:"id"`
+}
+
+func (g *productGateway) GetCategories(ctx context.Context) ([]*entity.Category, error) {
+	g.logger.Debug("Getting all categories from db", "traceID", ctx.Value("traceID"))
+	start := time.Now()
+
+	rows, err := g.db.QueryContext(ctx, selectCategories+" ORDER BY c.name, c.slug;")
+	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetCategories", "")
+	if err != nil {
+		g.logger.Error("Failed to get categories from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return g.scanCategories(ctx, rows)
+}
+
+func (g *productGateway) CreateCategory(ctx context.Context, category entity.Category) (*entity.Category, error) {
+	g.logger.Debug("Inserting category into db", "slug", category.Slug, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "CreateCategory", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin category transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	var parentID *string
+	if category.Parent != nil {
+		found, err := g.lockCategoryIDs(ctx, tx, []string{*category.Parent})
+		if err != nil {
+			return nil, err
+		}
+		id, ok := found[*category.Parent]
+		if !ok {
+			return nil, entity.InvalidField("parent", "doesn't belong to any category")
+		}
+		parentID = &id
+	}
+
+	id := ulid.Make().String()
+	category.ID = &id
+	err = tx.QueryRowContext(ctx, `INSERT INTO categories (category_id, parent_id, slug, name, created_at) VALUES ($1, $2, $3, $4, 'NOW()')
+		RETURNING created_at;`, id, parentID, category.Slug, category.Name).Scan(&category.CreatedAt)
+	if err != nil {
+		g.logger.Error("Failed to insert category into db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit category transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return &category, nil
+}
+
+// DeleteCategory removes a category and its product assignments, the products stay. A category with
+// subcategories is kept, they would be left without a parent.
+func (g *productGateway) DeleteCategory(ctx context.Context, slug string) error {
+	g.logger.Debug("Deleting category from db", "slug", slug, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+
+	res, err := g.db.ExecContext(ctx, "DELETE FROM categories WHERE slug = $1;", slug)
+	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "DeleteCategory", "")
+	if err != nil {
+		g.logger.Error("Failed to delete category from db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return translateError(err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w with slug=%s", entity.ErrCategoryNotFound, slug)
+	}
+
+	return nil
+}
+
+func (g *productGateway) GetProductCategories(ctx context.Context, productID string) ([]*entity.Category, error) {
+	g.logger.Debug("Getting product categories from db", "ID", productID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetProductCategories", "")
+
+	categories, err := g.getProductCategories(ctx, g.db, productID)
+	if err != nil || len(categories) > 0 {
+		return categories, err
+	}
+
+	// a product without categories and a missing product look the same to the join
+	var exists bool
+	err = g.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE product_id = $1);", productID).Scan(&exists)
+	if err != nil {
+		g.logger.Error("Failed to check product on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w with ID=%s", entity.ErrProductNotFound, productID)
+	}
+
+	return categories, nil
+}
+
+// SetProductCategories replaces the categories of a product with the ones of the slugs
+func (g *productGateway) SetProductCategories(ctx context.Context, assignment entity.ProductCategories) ([]*entity.Category, error) {
+	g.logger.Debug("Setting product categories on db", "ID", assignment.ProductID, "slugs", assignment.Slugs, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "SetProductCategories", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin product categories transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	// concurrent assignments of the same product are serialized
+	err = tx.QueryRowContext(ctx, "SELECT product_id FROM products WHERE product_id = $1 FOR UPDATE;", assignment.ProductID).Scan(&assignment.ProductID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with ID=%s", entity.ErrProductNotFound, assignment.ProductID)
+	}
+	if err != nil {
+		g.logger.Error("Failed to lock product on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	found, err := g.lockCategoryIDs(ctx, tx, assignment.Slugs)
+	if err != nil {
+		return nil, err
+	}
+	categoryIDs, err := categoryIDsOf(found, assignment.Slugs, "categories")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM product_categories WHERE product_id = $1;", assignment.ProductID)
+	if err != nil {
+		g.logger.Error("Failed to delete product categories from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO product_categories (product_id, category_id) SELECT $1, unnest($2::char(26)[]);",
+		assignment.ProductID, categoryIDs)
+	if err != nil {
+		g.logger.Error("Failed to insert product categories into db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	categories, err := g.getProductCategories(ctx, tx, assignment.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit product categories transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return categories, nil
+}
+
+// GetCategoryProducts pages the products of a category and of its subcategories in the order of their IDs,
+// a product in more than one of them is listed once
+func (g *productGateway) GetCategoryProducts(ctx context.Context, filter entity.CategoryProductFilter) (*entity.ProductPage, error) {
+	g.logger.Debug("Getting category products from db", "filter", filter, "traceID", ctx.Value("traceID"))
+	var after *string
+	if filter.Cursor != "" {
+		var cursor productCursor
+		if err := decodeCursor(filter.Cursor, &cursor); err != nil || cursor.ID == "" {
+			return nil, entity.InvalidField("cursor", "is malformed")
+		}
+		after = &cursor.ID
+	}
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetCategoryProducts", "")
+
+	found, err := g.getCategoryIDs(ctx, g.db, []string{filter.Slug})
+	if err != nil {
+		return nil, err
+	}
+	categoryID, ok := found[filter.Slug]
+	if !ok {
+		return nil, fmt.Errorf("%w with slug=%s", entity.ErrCategoryNotFound, filter.Slug)
+	}
+
+	// one more row than the limit tells whether there is a next page
+	rows, err := g.db.QueryContext(ctx, categoryTree+` SELECT p.product_id, p.sku, p.name, p.description, p.price, p.quantity, p.created_at, p.updated_at, p.version
+		FROM products p
+		WHERE EXISTS (SELECT 1 FROM product_categories pc JOIN tree t ON t.category_id = pc.category_id WHERE pc.product_id = p.product_id)
+		AND ($2::text IS NULL OR p.product_id > $2)
+		ORDER BY p.product_id LIMIT $3;`, []string{categoryID}, after, filter.Limit+1)
+	if err != nil {
+		g.logger.Error("Failed to get category products from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	products, err := g.scanProducts(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.ProductPage{Products: products}
+	if len(products) > filter.Limit {
+		page.Products = products[:filter.Limit]
+		nextCursor, err := encodeCursor(productCursor{ID: *page.Products[filter.Limit-1].ID})
+		if err != nil {
+			g.logger.Error("Failed to encode next cursor", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		page.NextCursor = &nextCursor
+	}
+
+	return page, nil
+}
+
+// getCategoryIDs maps the slugs to the IDs of their categories, the slugs of no category are left out
+func (g *productGateway) getCategoryIDs(ctx context.Context, q queryer, slugs []string) (map[string]string, error) {
+	return g.queryCategoryIDs(ctx, q, "SELECT category_id, slug FROM categories WHERE slug = ANY($1);", slugs)
+}
+
+// lockCategoryIDs is getCategoryIDs for write transactions. The categories are share locked, so they
+// can't be deleted before the end of the caller's transaction.
+func (g *productGateway) lockCategoryIDs(ctx context.Context, tx *sql.Tx, slugs []string) (map[string]string, error) {
+	return g.queryCategoryIDs(ctx, tx, "SELECT category_id, slug FROM categories WHERE slug = ANY($1) FOR KEY SHARE;", slugs)
+}
+
+func (g *productGateway) queryCategoryIDs(ctx context.Context, q queryer, query string, slugs []string) (map[string]string, error) {
+	rows, err := q.QueryContext(ctx, query, slugs)
+	if err != nil {
+		g.logger.Error("Failed to get category IDs from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	found := make(map[string]string, len(slugs))
+	for rows.Next() {
+		var id, slug string
+		if err = rows.Scan(&id, &slug); err != nil {
+			g.logger.Error("Error scaning category ID row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		found[slug] = id
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating category ID rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return found, nil
+}
+
+// categoryIDsOf lists the IDs of the slugs of field, every slug that wasn't found is reported
+func categoryIDsOf(found map[string]string, slugs []string, field string) ([]string, error) {
+	ids := make([]string, 0, len(slugs))
+	fields := make([]entity.FieldError, 0)
+	for i, slug := range slugs {
+		id, ok := found[slug]
+		if !ok {
+			fields = append(fields, entity.FieldError{Field: fmt.Sprintf("%s[%d]", field, i), Message: "doesn't belong to any category"})
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(fields) > 0 {
+		return nil, &entity.ValidationError{Fields: fields}
+	}
+
+	return ids, nil
+}
+
+func (g *productGateway) getProductCategories(ctx context.Context, q queryer, productID string) ([]*entity.Category, error) {
+	rows, err := q.QueryContext(ctx, selectCategories+` JOIN product_categories pc ON pc.category_id = c.category_id
+		WHERE pc.product_id = $1 ORDER BY c.name, c.slug;`, productID)
+	if err != nil {
+		g.logger.Error("Failed to get product categories from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return g.scanCategories(ctx, rows)
+}
+
+func (g *productGateway) scanCategories(ctx context.Context, rows *sql.Rows) ([]*entity.Category, error) {
+	defer rows.Close()
+	categories := make([]*entity.Category, 0)
+	for rows.Next() {
+		category := &entity.Category{}
+		err := rows.Scan(&category.ID, &category.Slug, &category.Name, &category.Parent, &category.CreatedAt, &category.UpdatedAt)
+		if err != nil {
+			g.logger.Error("Error scaning category row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		g.logger.Error("Error iterating category rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return categories, nil
+}
+
+func (g *productGateway) scanProducts(ctx context.Context, rows *sql.Rows) ([]*entity.Product, error) {
+	defer rows.Close()
+	products := make([]*entity.Product, 0)
+	for rows.Next() {
+		product := &entity.Product{}
+		err := rows.Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt, &product.Version)
+		if err != nil {
+			g.logger.Error("Error scaning product row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		g.logger.Error("Error iterating product rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return products, nil
+}
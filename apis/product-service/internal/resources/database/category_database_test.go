@@ -0,0 +1,85 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const selectCategoryIDsQuery = "SELECT category_id, slug FROM categories WHERE slug = ANY($1);"
+
+func categoryProductRows(ids ...string) *sqlmock.Rows {
+	rows := sqlmock.NewRows(productColumns)
+	for _, id := range ids {
+		rows.AddRow(id, "SK-"+id, "Product "+id, "", 9.9, 1, time.Now(), nil, 1)
+	}
+	return rows
+}
+
+func Test_ProductGtw_GetCategoryProducts(t *testing.T) {
+	after, err := encodeCursor(productCursor{ID: "p2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		cursor       string
+		after        interface{}
+		rows         []string
+		expectedIDs  []string
+		expectedNext *string
+	}{
+		{"first page with more products", "", nil, []string{"p1", "p2", "p3"}, []string{"p1", "p2"}, &after},
+		{"last page", after, "p2", []string{"p3"}, []string{"p3"}, nil},
+		{"page exactly full", "", nil, []string{"p1", "p2"}, []string{"p1", "p2"}, nil},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			gtw, mock := newMockGateway(t)
+			mock.ExpectQuery(regexp.QuoteMeta(selectCategoryIDsQuery)).
+				WithArgs([]string{"phones"}).
+				WillReturnRows(sqlmock.NewRows([]string{"category_id", "slug"}).AddRow("c1", "phones"))
+			// one more row than the limit is asked for, after the product of the cursor
+			mock.ExpectQuery(regexp.QuoteMeta("AND ($2::text IS NULL OR p.product_id > $2)")).
+				WithArgs([]string{"c1"}, tt.after, 3).
+				WillReturnRows(categoryProductRows(tt.rows...))
+
+			page, err := gtw.GetCategoryProducts(context.Background(), entity.CategoryProductFilter{Slug: "phones", Limit: 2, Cursor: tt.cursor})
+
+			assert.NoError(t, err)
+			ids := make([]string, 0)
+			for _, product := range page.Products {
+				ids = append(ids, *product.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedNext, page.NextCursor)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_ProductGtw_GetCategoryProducts_Errors(t *testing.T) {
+	gtw, mock := newMockGateway(t)
+
+	_, err := gtw.GetCategoryProducts(context.Background(), entity.CategoryProductFilter{Slug: "phones", Limit: 2, Cursor: "not a cursor"})
+	var validationErr *entity.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+
+	mock.ExpectQuery(regexp.QuoteMeta(selectCategoryIDsQuery)).
+		WithArgs([]string{"phones"}).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id", "slug"}))
+
+	_, err = gtw.GetCategoryProducts(context.Background(), entity.CategoryProductFilter{Slug: "phones", Limit: 2})
+	assert.ErrorIs(t, err, entity.ErrCategoryNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// encodeCursor makes the position of the last row of a page opaque to clients
+func encodeCursor(c interface{}) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor reads a cursor of encodeCursor into c, callers check the position it has
+func decodeCursor(cursor string, c interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, c)
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgconn"
+)
+
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+// productConstraintConflicts tells clients which rule a write broke, without the driver message
+var productConstraintConflicts = map[string]string{
+	"products_name_key": "name is already in use",
+	"products_sku_key":  "SKU is already in use",
+	"products_pkey":     "product ID is already in use",
+}
+
+// categoryConstraintConflicts are the rules of the category tree
+var categoryConstraintConflicts = map[string]string{
+	"categories_slug_key":       "slug is already in use",
+	"categories_parent_id_fkey": "category has subcategories",
+}
+
+// translateError turns driver errors into domain errors so their text never reaches clients.
+// Callers log the raw error before translating it.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == uniqueViolation || pgErr.Code == foreignKeyViolation:
+			if detail, ok := categoryConstraintConflicts[pgErr.ConstraintName]; ok {
+				return fmt.Errorf("%w, %s", entity.ErrCategoryConflict, detail)
+			}
+			if pgErr.Code == foreignKeyViolation {
+				return err
+			}
+			detail, ok := productConstraintConflicts[pgErr.ConstraintName]
+			if !ok {
+				return entity.ErrProductConflict
+			}
+			return fmt.Errorf("%w, %s", entity.ErrProductConflict, detail)
+		// the updates check the stock first, this only guards against a path that doesn't
+		case pgErr.Code == checkViolation && pgErr.ConstraintName == "products_quantity_check":
+			return fmt.Errorf("%w, quantity can't be negative", entity.ErrInsufficientStock)
+		// connection exceptions, insufficient resources and server shutdowns
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "53"), strings.HasPrefix(pgErr.Code, "57P"):
+			return fmt.Errorf("%w: %w", entity.ErrUnavailable, err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || pgconn.Timeout(err) || errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", entity.ErrUnavailable, err)
+	}
+
+	return err
+}
@@ -0,0 +1,203 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// unknownActor is recorded on the movements of requests without X-Actor
+const unknownActor = "unknown"
+
+type movementCursor struct {
+	ID int64 `json:"id"`
+}
+
+// movementSource is who changed the stock and on which request, as the handler or the reaper put them in ctx
+func movementSource(ctx context.Context) (string, *string) {
+	actor, _ := ctx.Value("actor").(string)
+	if actor == "" {
+		actor = unknownActor
+	}
+	var traceID *string
+	if value, ok := ctx.Value("traceID").(string); ok && value != "" {
+		traceID = &value
+	}
+
+	return actor, traceID
+}
+
+// recordMovement appends a movement in the transaction that changed the stock, zero quantities aren't recorded
+func (g *productGateway) recordMovement(ctx context.Context, tx *sql.Tx, productID string, reason string, quantity int64, reservationID *string) error {
+	if quantity == 0 {
+		return nil
+	}
+
+	actor, traceID := movementSource(ctx)
+	_, err := tx.ExecContext(ctx, `INSERT INTO inventory_movements (product_id, reason, quantity, reservation_id, actor, trace_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'NOW()');`, productID, reason, quantity, reservationID, actor, traceID)
+	if err != nil {
+		g.logger.Error("Failed to insert inventory movement into db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// recordReservationMovements appends a movement per product of each reservation, of its quantity times sign.
+// Deleted products are left out, like the stock updates leave them out.
+func (g *productGateway) recordReservationMovements(ctx context.Context, tx *sql.Tx, reservationIDs []string, reason string, sign int64) error {
+	actor, traceID := movementSource(ctx)
+	_, err := tx.ExecContext(ctx, `INSERT INTO inventory_movements (product_id, reason, quantity, reservation_id, actor, trace_id, created_at)
+		SELECT i.product_id, $2, $3 * i.quantity, i.reservation_id, $4, $5, 'NOW()'
+		FROM product_reservation_items i JOIN products p ON p.product_id = i.product_id
+		WHERE i.reservation_id = ANY($1)
+		ORDER BY i.reservation_id, i.product_id;`, reservationIDs, reason, sign, actor, traceID)
+	if err != nil {
+		g.logger.Error("Failed to insert reservation movements into db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (g *productGateway) GetProductMovements(ctx context.Context, filter entity.MovementFilter) (*entity.MovementPage, error) {
+	g.logger.Debug("Getting product movements from db", "filter", filter, "traceID", ctx.Value("traceID"))
+	var before *int64
+	if filter.Cursor != "" {
+		cursor, err := decodeMovementCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		before = &cursor.ID
+	}
+	start := time.Now()
+
+	// one more row than the limit tells whether there is a next page
+	rows, err := g.db.QueryContext(ctx, `SELECT movement_id, product_id, reason, quantity, reservation_id, actor, trace_id, created_at
+		FROM inventory_movements WHERE product_id = $1 AND ($2::bigint IS NULL OR movement_id < $2)
+		ORDER BY movement_id DESC LIMIT $3;`, filter.ProductID, before, filter.Limit+1)
+	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetProductMovements", "")
+	if err != nil {
+		g.logger.Error("Failed to get product movements from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	movements := make([]*entity.InventoryMovement, 0, filter.Limit+1)
+	for rows.Next() {
+		movement := &entity.InventoryMovement{}
+		err = rows.Scan(&movement.ID, &movement.ProductID, &movement.Reason, &movement.Quantity, &movement.ReservationID, &movement.Actor, &movement.TraceID, &movement.CreatedAt)
+		if err != nil {
+			g.logger.Error("Error scaning movement row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating movement rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	page := &entity.MovementPage{Movements: movements}
+	if len(movements) > filter.Limit {
+		page.Movements = movements[:filter.Limit]
+		nextCursor, err := encodeCursor(movementCursor{ID: page.Movements[filter.Limit-1].ID})
+		if err != nil {
+			g.logger.Error("Failed to encode next cursor", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		page.NextCursor = &nextCursor
+	}
+
+	return page, nil
+}
+
+// GetInventoryDrift lists the products whose quantity isn't the sum of their movements
+func (g *productGateway) GetInventoryDrift(ctx context.Context) ([]entity.InventoryDrift, error) {
+	g.logger.Debug("Getting inventory drift from db", "traceID", ctx.Value("traceID"))
+	start := time.Now()
+
+	rows, err := g.db.QueryContext(ctx, `SELECT p.product_id, p.name, p.quantity, COALESCE(SUM(m.quantity), 0)
+		FROM products p LEFT JOIN inventory_movements m ON m.product_id = p.product_id
+		GROUP BY p.product_id HAVING p.quantity <> COALESCE(SUM(m.quantity), 0)
+		ORDER BY p.product_id;`)
+	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetInventoryDrift", "")
+	if err != nil {
+		g.logger.Error("Failed to get inventory drift from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	drifts := make([]entity.InventoryDrift, 0)
+	for rows.Next() {
+		drift := entity.InventoryDrift{}
+		if err = rows.Scan(&drift.ProductID, &drift.Name, &drift.Quantity, &drift.LedgerQuantity); err != nil {
+			g.logger.Error("Error scaning drift row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		drifts = append(drifts, drift)
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating drift rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return drifts, nil
+}
+
+// FixInventoryDrift appends the adjustment that makes the ledger of the product add up to its quantity.
+// The drift is computed again with the product locked, it returns nil when there is none anymore.
+func (g *productGateway) FixInventoryDrift(ctx context.Context, productID string) (*entity.InventoryDrift, error) {
+	g.logger.Debug("Fixing inventory drift on db", "ID", productID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "FixInventoryDrift", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin drift transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	drift := &entity.InventoryDrift{ProductID: productID}
+	err = tx.QueryRowContext(ctx, "SELECT name, quantity FROM products WHERE product_id = $1 FOR UPDATE;", productID).Scan(&drift.Name, &drift.Quantity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with ID=%s", entity.ErrProductNotFound, productID)
+	}
+	if err != nil {
+		g.logger.Error("Failed to lock product on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(SUM(quantity), 0) FROM inventory_movements WHERE product_id = $1;", productID).Scan(&drift.LedgerQuantity)
+	if err != nil {
+		g.logger.Error("Failed to sum product movements on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	if drift.Quantity == drift.LedgerQuantity {
+		return nil, nil
+	}
+
+	if err = g.recordMovement(ctx, tx, productID, entity.MovementAdjustment, drift.Quantity-drift.LedgerQuantity, nil); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit drift transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return drift, nil
+}
+
+func decodeMovementCursor(cursor string) (*movementCursor, error) {
+	var c movementCursor
+	if err := decodeCursor(cursor, &c); err != nil || c.ID <= 0 {
+		return nil, entity.InvalidField("cursor", "is malformed")
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"cmd/product-service/internal/metrics"
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// anyValueConverter lets the slices given to ANY($n) through, like the pgx driver does
+type anyValueConverter struct{}
+
+func (anyValueConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	if value, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+		return value, nil
+	}
+	return v, nil
+}
+
+func newMockGateway(t *testing.T) (*productGateway, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(anyValueConverter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+	gtw := NewProductGateway(logger, metrics.NewProductMetrics(logger, prometheus.NewRegistry()), db).(*productGateway)
+	return gtw, mock
+}
+
+const insertMovementQuery = "INSERT INTO inventory_movements (product_id, reason, quantity, reservation_id, actor, trace_id, created_at)"
+
+func Test_ProductGtw_UpdateProduct_RecordsQuantityChange(t *testing.T) {
+	ctx := context.WithValue(context.WithValue(context.Background(), "actor", "ops@mock.com"), "traceID", "trace-1")
+	productID := "01HZ7E8GR7SBPV9F96XRR5HCW2"
+	product := entity.Product{ID: &productID, Name: "Adapter", Price: 9.9, Quantity: 8}
+
+	scenarios := []struct {
+		name     string
+		before   int64
+		movement int64
+	}{
+		{"restocked", 5, 3},
+		{"sold out", 10, -2},
+		{"same quantity", 8, 0},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			gtw, mock := newMockGateway(t)
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("UPDATE products p SET name = $1")).
+				WithArgs(product.Name, product.Description, product.Price, product.Quantity, product.ID, nil).
+				WillReturnRows(sqlmock.NewRows([]string{"before", "after"}).AddRow(tt.before, product.Quantity))
+			// the ledger only gets a row when the quantity changed, in the same transaction
+			if tt.movement != 0 {
+				mock.ExpectExec(regexp.QuoteMeta(insertMovementQuery)).
+					WithArgs(productID, entity.MovementAdjustment, tt.movement, nil, "ops@mock.com", "trace-1").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+			mock.ExpectCommit()
+
+			assert.NoError(t, gtw.UpdateProduct(ctx, product, nil))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_ProductGtw_DeleteProductByID_RecordsStockLeaving(t *testing.T) {
+	ctx := context.Background()
+	gtw, mock := newMockGateway(t)
+	productID := "01HZ7E8GR7SBPV9F96XRR5HCW2"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM products WHERE product_id = $1")).
+		WithArgs(productID, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"before", "after"}).AddRow(4, 0))
+	mock.ExpectExec(regexp.QuoteMeta(insertMovementQuery)).
+		WithArgs(productID, entity.MovementAdjustment, int64(-4), nil, unknownActor, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, gtw.DeleteProductByID(ctx, productID, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_ProductGtw_UpdateProduct_LedgerFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	gtw, mock := newMockGateway(t)
+	productID := "01HZ7E8GR7SBPV9F96XRR5HCW2"
+	product := entity.Product{ID: &productID, Name: "Adapter", Quantity: 8}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE products p SET name = $1")).
+		WillReturnRows(sqlmock.NewRows([]string{"before", "after"}).AddRow(5, 8))
+	mock.ExpectExec(regexp.QuoteMeta(insertMovementQuery)).WillReturnError(driver.ErrBadConn)
+	// the quantity can't change without its movement
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, gtw.UpdateProduct(ctx, product, nil), entity.ErrUnavailable)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_ProductGtw_GetInventoryDrift(t *testing.T) {
+	ctx := context.Background()
+	gtw, mock := newMockGateway(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("HAVING p.quantity <> COALESCE(SUM(m.quantity), 0)")).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "name", "quantity", "ledger"}).
+			AddRow("p1", "Adapter", 10, 7).
+			AddRow("p2", "Cable", 0, 3))
+
+	drifts, err := gtw.GetInventoryDrift(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.InventoryDrift{
+		{ProductID: "p1", Name: "Adapter", Quantity: 10, LedgerQuantity: 7},
+		{ProductID: "p2", Name: "Cable", Quantity: 0, LedgerQuantity: 3},
+	}, drifts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_ProductGtw_GetInventoryDrift_NoDrift(t *testing.T) {
+	ctx := context.Background()
+	gtw, mock := newMockGateway(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM products p LEFT JOIN inventory_movements m")).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "name", "quantity", "ledger"}))
+
+	drifts, err := gtw.GetInventoryDrift(ctx)
+
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+	assert.NotNil(t, drifts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	dbschema "cmd/product-service/db-schema"
+	"cmd/product-service/internal/metrics"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// migrationsUpTo keeps the embedded migrations up to version, to start from an older schema
+func migrationsUpTo(t *testing.T, version int) fstest.MapFS {
+	migrations, err := loadMigrations(dbschema.Migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{}
+	for _, migration := range migrations {
+		if migration.Version > version {
+			continue
+		}
+		fsys[fmt.Sprintf("%d_%s.up.sql", migration.Version, migration.Name)] = &fstest.MapFile{Data: []byte(migration.Up)}
+		fsys[fmt.Sprintf("%d_%s.down.sql", migration.Version, migration.Name)] = &fstest.MapFile{Data: []byte(migration.Down)}
+	}
+	return fsys
+}
+
+// newTestDB opens PRODUCT_TEST_DATABASE_URL on a schema of its own, dropped at the end of the test
+func newTestDB(t *testing.T) *sql.DB {
+	dsn := os.Getenv("PRODUCT_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("PRODUCT_TEST_DATABASE_URL isn't set")
+	}
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	if _, err = admin.Exec("CREATE SCHEMA " + schema + ";"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE;") })
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	db, err := sql.Open("pgx", u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func migrate(t *testing.T, db *sql.DB, fsys fs.FS) {
+	migrator, err := NewMigrator(*slog.New(slog.NewTextHandler(io.Discard, nil)), db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Migration_ClampsNegativeQuantity(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	// before the check the stock could go negative, and the ledger opened with that quantity
+	migrate(t, db, migrationsUpTo(t, 3))
+	_, err := db.Exec(`INSERT INTO products (product_id, name, price, quantity, created_at) VALUES
+		('01HZ7E8GR7SBPV9F96XRR5HCW1', 'Oversold', 1, -3, NOW()),
+		('01HZ7E8GR7SBPV9F96XRR5HCW2', 'In stock', 1, 5, NOW());`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrate(t, db, dbschema.Migrations)
+
+	var quantity, version int64
+	err = db.QueryRow("SELECT quantity, version FROM products WHERE product_id = '01HZ7E8GR7SBPV9F96XRR5HCW1';").Scan(&quantity, &version)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), quantity)
+	assert.Equal(t, int64(2), version)
+
+	var reason string
+	err = db.QueryRow("SELECT reason, quantity FROM inventory_movements WHERE product_id = '01HZ7E8GR7SBPV9F96XRR5HCW1' AND actor = 'migration' AND quantity > 0;").Scan(&reason, &quantity)
+	assert.NoError(t, err)
+	assert.Equal(t, "adjustment", reason)
+	assert.Equal(t, int64(3), quantity)
+
+	// every ledger still adds up to the quantity of its product
+	logger := *slog.New(slog.NewTextHandler(io.Discard, nil))
+	gtw := NewProductGateway(logger, metrics.NewProductMetrics(logger, prometheus.NewRegistry()), db)
+	drifts, err := gtw.GetInventoryDrift(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+
+	_, err = db.Exec("UPDATE products SET quantity = -1 WHERE product_id = '01HZ7E8GR7SBPV9F96XRR5HCW2';")
+	assert.Error(t, err)
+}
@@ -6,8 +6,10 @@ import (
 	"cmd/product-service/internal/metrics"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/oklog/ulid/v2"
@@ -27,50 +29,72 @@ func NewProductGateway(l slog.Logger, m *metrics.ProductMetrics, db *sql.DB) gat
 	}
 }
 
-func (g *productGateway) GetProductList(ctx context.Context) ([]*entity.Product, error) {
-	g.logger.Debug("Getting all products from DB", "traceID", ctx.Value("traceID"))
-	query := "SELECT product_id, name, description, price, quantity, created_at, updated_at FROM products;"
+// GetProductList lists every product, or with the categories of the filter only the products of them
+// and of their subcategories
+func (g *productGateway) GetProductList(ctx context.Context, filter entity.ProductFilter) ([]*entity.Product, error) {
+	g.logger.Debug("Getting all products from DB", "filter", filter, "traceID", ctx.Value("traceID"))
 	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetProductList", "")
 
-	rows, err := g.db.Query(query)
-	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetProductList", "")
+	query, args, err := g.selectProducts(ctx, g.db, filter)
 	if err != nil {
-		g.logger.Error("Failed to get products from db", "error", err, "traceID", ctx.Value("traceID"))
 		return nil, err
 	}
 
-	defer rows.Close()
-	products := make([]*entity.Product, 0)
-	for rows.Next() {
-		product := &entity.Product{}
-		err = rows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt)
-		if err != nil {
-			g.logger.Error("Error scaning row", "error", err, "traceID", ctx.Value("traceID"))
-			return nil, err
-		}
-		products = append(products, product)
+	rows, err := g.db.QueryContext(ctx, query+";", args...)
+	if err != nil {
+		g.logger.Error("Failed to get products from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	products, err := g.scanProducts(ctx, rows)
+	if err != nil {
+		return nil, err
 	}
 
 	g.logger.Info("Found product list on DB", "size", len(products))
 	return products, nil
 }
 
+// selectProducts builds the query of the products in any of the filter categories or in their
+// subcategories, or of every product when the filter has no categories
+func (g *productGateway) selectProducts(ctx context.Context, q queryer, filter entity.ProductFilter) (string, []interface{}, error) {
+	if len(filter.Categories) == 0 {
+		return "SELECT product_id, sku, name, description, price, quantity, created_at, updated_at, version FROM products ORDER BY product_id", nil, nil
+	}
+
+	found, err := g.getCategoryIDs(ctx, q, filter.Categories)
+	if err != nil {
+		return "", nil, err
+	}
+	categoryIDs, err := categoryIDsOf(found, filter.Categories, "category")
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := categoryTree + ` SELECT p.product_id, p.sku, p.name, p.description, p.price, p.quantity, p.created_at, p.updated_at, p.version
+		FROM products p
+		WHERE EXISTS (SELECT 1 FROM product_categories pc JOIN tree t ON t.category_id = pc.category_id WHERE pc.product_id = p.product_id)
+		ORDER BY p.product_id`
+	return query, []interface{}{categoryIDs}, nil
+}
+
 func (g *productGateway) GetProductByID(ctx context.Context, productID string) (*entity.Product, error) {
 	g.logger.Debug("Getting product by ID from db", "ID", productID, "traceID", ctx.Value("traceID"))
-	query := "SELECT product_id, name, description, price, quantity, created_at, updated_at FROM products WHERE product_id = $1;"
+	query := "SELECT product_id, sku, name, description, price, quantity, created_at, updated_at, version FROM products WHERE product_id = $1;"
 	start := time.Now()
 
 	rows, err := g.db.Query(query, productID)
 	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetProductByID", "")
 	if err != nil {
 		g.logger.Error("Failed to get product by ID from db", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	defer rows.Close()
 	for rows.Next() {
 		product := entity.Product{}
-		err = rows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt)
+		err = rows.Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt, &product.Version)
 		if err != nil {
 			g.logger.Error("Error scaning product row", "error", err)
 			return nil, err
@@ -78,25 +102,51 @@ func (g *productGateway) GetProductByID(ctx context.Context, productID string) (
 		return &product, nil
 	}
 
-	return nil, fmt.Errorf("No product found with ID=%s", productID)
+	return nil, fmt.Errorf("%w with ID=%s", entity.ErrProductNotFound, productID)
 }
 
 func (g *productGateway) GetProductByName(ctx context.Context, productName string) (*entity.Product, error) {
 	g.logger.Debug("Getting product by name from db", "productName", productName, "traceID", ctx.Value("traceID"))
-	query := "SELECT product_id, name, description, price, quantity, created_at, updated_at FROM products WHERE name = $1;"
+	query := "SELECT product_id, sku, name, description, price, quantity, created_at, updated_at, version FROM products WHERE name = $1;"
 	start := time.Now()
 
 	rows, err := g.db.Query(query, productName)
 	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetProductByName", "")
 	if err != nil {
 		g.logger.Error("Failed to get product by name from db", "error", err, "traceID", ctx.Value("traceID"))
-		return nil, err
+		return nil, translateError(err)
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		product := entity.Product{}
+		err = rows.Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt, &product.Version)
+		if err != nil {
+			g.logger.Error("Error scaning product row", "error", err)
+			return nil, err
+		}
+		return &product, nil
+	}
+
+	return nil, fmt.Errorf("%w with name=%s", entity.ErrProductNotFound, productName)
+}
+
+func (g *productGateway) GetProductBySKU(ctx context.Context, sku string) (*entity.Product, error) {
+	g.logger.Debug("Getting product by SKU from db", "sku", sku, "traceID", ctx.Value("traceID"))
+	query := "SELECT product_id, sku, name, description, price, quantity, created_at, updated_at, version FROM products WHERE sku = $1;"
+	start := time.Now()
+
+	rows, err := g.db.Query(query, sku)
+	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetProductBySKU", "")
+	if err != nil {
+		g.logger.Error("Failed to get product by SKU from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
 	}
 
 	defer rows.Close()
 	for rows.Next() {
 		product := entity.Product{}
-		err = rows.Scan(&product.ID, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt)
+		err = rows.Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt, &product.Version)
 		if err != nil {
 			g.logger.Error("Error scaning product row", "error", err)
 			return nil, err
@@ -104,67 +154,159 @@ func (g *productGateway) GetProductByName(ctx context.Context, productName strin
 		return &product, nil
 	}
 
-	return nil, fmt.Errorf("No product found with name=%s", productName)
+	return nil, fmt.Errorf("%w with SKU=%s", entity.ErrProductNotFound, sku)
 }
 
 func (g *productGateway) CreateProduct(ctx context.Context, product entity.Product) (*string, error) {
 	g.logger.Debug("Inserting product into DB", "name", product.Name, "traceID", ctx.Value("traceID"))
 	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "CreateProduct", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin product transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
 
 	id := ulid.Make().String()
-	_, err := g.db.Exec(`INSERT INTO products (product_id, name, description, price, quantity, created_at) VALUES ($1, $2, $3, $4, $5, 'NOW()');`,
+	_, err = tx.ExecContext(ctx, `INSERT INTO products (product_id, sku, name, description, price, quantity, created_at) VALUES ($1, $2, $3, $4, $5, $6, 'NOW()');`,
 		id,
+		product.SKU,
 		product.Name,
 		product.Description,
 		product.Price,
 		product.Quantity)
-	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "CreateProduct", "")
 	if err != nil {
 		g.logger.Error("Failed to insert product into db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	// the initial stock of a product is its first restock
+	if err = g.recordMovement(ctx, tx, id, entity.MovementRestock, product.Quantity, nil); err != nil {
 		return nil, err
 	}
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit product transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
 
 	return &id, nil
 }
 
-func (g *productGateway) UpdateProduct(ctx context.Context, product entity.Product) error {
+func (g *productGateway) UpdateProduct(ctx context.Context, product entity.Product, ifMatch *int64) error {
 	g.logger.Debug("Updating product on db", "ID", product.ID, "traceID", ctx.Value("traceID"))
 	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "UpdateProduct", "")
 
-	result, err := g.db.Exec(`UPDATE products SET name = $1, description = $2, price = $3, quantity = $4, updated_at = 'NOW()' WHERE product_id = $5;`,
-		product.Name,
-		product.Description,
-		product.Price,
-		product.Quantity,
-		product.ID)
-	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "UpdateProduct", "")
+	return g.writeProduct(ctx, `UPDATE products p SET name = $1, description = $2, price = $3, quantity = $4, updated_at = 'NOW()', version = p.version + 1
+		FROM (SELECT product_id, quantity FROM products WHERE product_id = $5 FOR UPDATE) old
+		WHERE p.product_id = old.product_id AND ($6::bigint IS NULL OR p.version = $6)
+		RETURNING old.quantity, p.quantity;`,
+		[]interface{}{product.Name, product.Description, product.Price, product.Quantity, product.ID, ifMatch},
+		*product.ID, ifMatch)
+}
+
+func (g *productGateway) PatchProduct(ctx context.Context, patch entity.ProductPatch, ifMatch *int64) error {
+	g.logger.Debug("Patching product on db", "ID", patch.ID, "traceID", ctx.Value("traceID"))
+	query, args := buildProductPatchQuery(patch, ifMatch)
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "PatchProduct", "")
+
+	return g.writeProduct(ctx, query, args, patch.ID, ifMatch)
+}
+
+// writeProduct runs an update that returns the quantity before and after it, a change of quantity
+// is recorded as an adjustment in the same transaction
+func (g *productGateway) writeProduct(ctx context.Context, query string, args []interface{}, productID string, ifMatch *int64) error {
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin product transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	var before, after int64
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&before, &after)
+	if errors.Is(err, sql.ErrNoRows) {
+		return g.productNotAffectedError(ctx, productID, ifMatch)
+	}
 	if err != nil {
 		g.logger.Error("Failed to update product on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	if err = g.recordMovement(ctx, tx, productID, entity.MovementAdjustment, after-before, nil); err != nil {
 		return err
 	}
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit product transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
 
-	return validateIfRowWasAffected(result, *product.ID)
+	return nil
 }
 
-func (g *productGateway) DeleteProductByID(ctx context.Context, productID string) error {
+func (g *productGateway) DeleteProductByID(ctx context.Context, productID string, ifMatch *int64) error {
 	g.logger.Debug("Deleting product on db", "ID", productID, "traceID", ctx.Value("traceID"))
 	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "DeleteProductByID", "")
+
+	// the stock leaves with the product, so the ledger of a deleted product adds up to zero
+	return g.writeProduct(ctx, `DELETE FROM products WHERE product_id = $1 AND ($2::bigint IS NULL OR version = $2) RETURNING quantity, 0;`,
+		[]interface{}{productID, ifMatch}, productID, ifMatch)
+}
+
+// productNotAffectedError tells a missing product apart from one whose version didn't match ifMatch
+func (g *productGateway) productNotAffectedError(ctx context.Context, productID string, ifMatch *int64) error {
+	if ifMatch == nil {
+		return fmt.Errorf("%w with ID=%s", entity.ErrProductNotFound, productID)
+	}
 
-	result, err := g.db.Exec(`DELETE FROM products WHERE product_id = $1;`, productID)
-	g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "DeleteProductByID", "")
+	var exists bool
+	err := g.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM products WHERE product_id = $1);", productID).Scan(&exists)
 	if err != nil {
-		g.logger.Error("Failed to update product on db", "error", err, "traceID", ctx.Value("traceID"))
-		return err
+		g.logger.Error("Failed to check product version on db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	if exists {
+		return fmt.Errorf("%w with ID=%s, expected version %d", entity.ErrProductVersionMismatch, productID, *ifMatch)
 	}
 
-	return validateIfRowWasAffected(result, productID)
+	return fmt.Errorf("%w with ID=%s", entity.ErrProductNotFound, productID)
 }
 
-func validateIfRowWasAffected(result sql.Result, productID string) error {
-	rows, err := result.RowsAffected()
-	if rows == 0 || err != nil {
-		return fmt.Errorf("Product not found with ID=%s", productID)
+// buildProductPatchQuery only sets the columns present in the patch, the query returns the quantity before and after it
+func buildProductPatchQuery(patch entity.ProductPatch, ifMatch *int64) (string, []interface{}) {
+	assignments := make([]string, 0)
+	args := make([]interface{}, 0)
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
 	}
 
-	return nil
+	if patch.Name != nil {
+		set("name", *patch.Name)
+	}
+	if patch.Description != nil {
+		set("description", *patch.Description)
+	}
+	if patch.Price != nil {
+		set("price", *patch.Price)
+	}
+	if patch.Quantity != nil {
+		set("quantity", *patch.Quantity)
+	}
+	assignments = append(assignments, "updated_at = 'NOW()'", "version = p.version + 1")
+
+	args = append(args, patch.ID)
+	query := fmt.Sprintf("UPDATE products p SET %s FROM (SELECT product_id, quantity FROM products WHERE product_id = $%d FOR UPDATE) old WHERE p.product_id = old.product_id",
+		strings.Join(assignments, ", "), len(args))
+	if ifMatch != nil {
+		args = append(args, *ifMatch)
+		query += fmt.Sprintf(" AND p.version = $%d", len(args))
+	}
+	query += " RETURNING old.quantity, p.quantity;"
+
+	return query, args
 }
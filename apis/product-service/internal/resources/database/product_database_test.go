@@ -0,0 +1,54 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BuildProductPatchQuery(t *testing.T) {
+	name := "Adapter"
+	price := 9.9
+	version := int64(3)
+
+	scenarios := []struct {
+		name    string
+		patch   entity.ProductPatch
+		ifMatch *int64
+		want    string
+		args    []interface{}
+	}{
+		{
+			"name and price",
+			entity.ProductPatch{ID: "p1", Name: &name, Price: &price},
+			nil,
+			"UPDATE products p SET name = $1, price = $2, updated_at = 'NOW()', version = p.version + 1 FROM (SELECT product_id, quantity FROM products WHERE product_id = $3 FOR UPDATE) old WHERE p.product_id = old.product_id RETURNING old.quantity, p.quantity;",
+			[]interface{}{name, price, "p1"},
+		},
+		{
+			"name and price with If-Match",
+			entity.ProductPatch{ID: "p1", Name: &name, Price: &price},
+			&version,
+			"UPDATE products p SET name = $1, price = $2, updated_at = 'NOW()', version = p.version + 1 FROM (SELECT product_id, quantity FROM products WHERE product_id = $3 FOR UPDATE) old WHERE p.product_id = old.product_id AND p.version = $4 RETURNING old.quantity, p.quantity;",
+			[]interface{}{name, price, "p1", version},
+		},
+		{
+			"no fields with If-Match",
+			entity.ProductPatch{ID: "p1"},
+			&version,
+			"UPDATE products p SET updated_at = 'NOW()', version = p.version + 1 FROM (SELECT product_id, quantity FROM products WHERE product_id = $1 FOR UPDATE) old WHERE p.product_id = old.product_id AND p.version = $2 RETURNING old.quantity, p.quantity;",
+			[]interface{}{"p1", version},
+		},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := buildProductPatchQuery(tt.patch, tt.ifMatch)
+			assert.Equal(t, tt.want, query)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
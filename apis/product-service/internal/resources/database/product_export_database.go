@@ -0,0 +1,78 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// exportFetchSize rows are fetched from the export cursor at a time, it bounds the memory an export holds
+const exportFetchSize = 1000
+
+// ExportProducts walks the filtered products through a server side cursor and hands them to write
+// one at a time
+func (g *productGateway) ExportProducts(ctx context.Context, filter entity.ProductFilter, write func(product *entity.Product) error) error {
+	g.logger.Debug("Exporting products from DB", "filter", filter, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "ExportProducts", "")
+
+	// a cursor only lives inside a transaction, which also gives the export a single snapshot
+	tx, err := g.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		g.logger.Error("Failed to begin export transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+	defer tx.Rollback()
+
+	query, args, err := g.selectProducts(ctx, tx, filter)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DECLARE product_export NO SCROLL CURSOR FOR "+query+";", args...)
+	if err != nil {
+		g.logger.Error("Failed to declare product export cursor", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	for {
+		fetched, err := g.fetchProductExport(ctx, tx, write)
+		if err != nil {
+			return err
+		}
+		if fetched < exportFetchSize {
+			return nil
+		}
+	}
+}
+
+func (g *productGateway) fetchProductExport(ctx context.Context, tx *sql.Tx, write func(product *entity.Product) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("FETCH %d FROM product_export;", exportFetchSize))
+	if err != nil {
+		g.logger.Error("Failed to fetch product export rows", "error", err, "traceID", ctx.Value("traceID"))
+		return 0, translateError(err)
+	}
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		product := &entity.Product{}
+		err = rows.Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt, &product.Version)
+		if err != nil {
+			g.logger.Error("Error scaning row", "error", err, "traceID", ctx.Value("traceID"))
+			return 0, err
+		}
+		if err = write(product); err != nil {
+			return 0, err
+		}
+		fetched++
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating product export rows", "error", err, "traceID", ctx.Value("traceID"))
+		return 0, translateError(err)
+	}
+
+	return fetched, nil
+}
@@ -0,0 +1,412 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// CreateReservation takes the stock of every item in a single transaction, either all of them are held or none.
+// The products are locked in the order of their IDs, so concurrent reservations can't deadlock each other.
+func (g *productGateway) CreateReservation(ctx context.Context, items []entity.ReservationItem, ttl time.Duration) (*entity.Reservation, error) {
+	g.logger.Debug("Creating reservation on db", "size", len(items), "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "CreateReservation", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin reservation transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	productIDs := make([]string, len(items))
+	quantities := make([]int64, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+		quantities[i] = item.Quantity
+	}
+
+	stock, err := g.lockProductStock(ctx, tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		available, ok := stock[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w with ID=%s", entity.ErrProductNotFound, item.ProductID)
+		}
+		if available < item.Quantity {
+			return nil, fmt.Errorf("%w for product with ID=%s, %d available", entity.ErrInsufficientStock, item.ProductID, available)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE products p SET quantity = p.quantity - r.quantity, updated_at = 'NOW()', version = p.version + 1
+		FROM unnest($1::text[], $2::bigint[]) AS r (product_id, quantity) WHERE p.product_id = r.product_id;`, productIDs, quantities)
+	if err != nil {
+		g.logger.Error("Failed to take reserved stock on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	id := ulid.Make().String()
+	reservation := &entity.Reservation{ID: &id, Status: entity.ReservationHeld, Items: items}
+	// the expiry is set by the database clock, the same one the reaper compares it to
+	err = tx.QueryRowContext(ctx, `INSERT INTO product_reservations (reservation_id, status, expires_at, created_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3), 'NOW()') RETURNING expires_at, created_at;`,
+		id, entity.ReservationHeld, ttl.Seconds()).Scan(&reservation.ExpiresAt, &reservation.CreatedAt)
+	if err != nil {
+		g.logger.Error("Failed to insert reservation into db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO product_reservation_items (reservation_id, product_id, quantity)
+		SELECT $1, product_id, quantity FROM unnest($2::text[], $3::bigint[]) AS r (product_id, quantity);`, id, productIDs, quantities)
+	if err != nil {
+		g.logger.Error("Failed to insert reservation items into db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	if err = g.recordReservationMovements(ctx, tx, []string{id}, entity.MovementReservation, -1); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit reservation transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	g.metrics.AddReservations(entity.ReservationHeld, 1)
+	return reservation, nil
+}
+
+func (g *productGateway) GetReservationByID(ctx context.Context, reservationID string) (*entity.Reservation, error) {
+	g.logger.Debug("Getting reservation by ID from db", "ID", reservationID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "GetReservationByID", "")
+
+	return g.getReservation(ctx, g.db, reservationID)
+}
+
+// CommitReservation keeps the stock of a held reservation for good, on the ledger the hold is given back
+// and taken again as a sale. Committing it again is a no-op, so a retried commit succeeds, but one that
+// expired in the meantime can't be committed anymore.
+func (g *productGateway) CommitReservation(ctx context.Context, reservationID string) (*entity.Reservation, error) {
+	g.logger.Debug("Committing reservation on db", "ID", reservationID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "CommitReservation", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin commit transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE product_reservations SET status = $1, updated_at = 'NOW()'
+		WHERE reservation_id = $2 AND status = $3 AND expires_at > NOW();`, entity.ReservationCommitted, reservationID, entity.ReservationHeld)
+	if err != nil {
+		g.logger.Error("Failed to commit reservation on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		g.logger.Error("Failed to commit reservation on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	committed := rows > 0
+	if committed {
+		ids := []string{reservationID}
+		if err = g.recordReservationMovements(ctx, tx, ids, entity.MovementReservation, 1); err != nil {
+			return nil, err
+		}
+		if err = g.recordReservationMovements(ctx, tx, ids, entity.MovementSale, -1); err != nil {
+			return nil, err
+		}
+	}
+
+	reservation, err := g.getReservation(ctx, tx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if !committed {
+		if err = checkCommitted(reservationID, reservation); err != nil {
+			return nil, err
+		}
+		return reservation, nil
+	}
+
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit the reservation commit transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	g.metrics.AddReservations(entity.ReservationCommitted, 1)
+	return reservation, nil
+}
+
+// ReleaseReservation gives the stock of a held reservation back, even after its expiry. Releasing a
+// reservation that was already released or expired is a no-op, a committed one can't be released.
+func (g *productGateway) ReleaseReservation(ctx context.Context, reservationID string) (*entity.Reservation, error) {
+	g.logger.Debug("Releasing reservation on db", "ID", reservationID, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "ReleaseReservation", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin release transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	released, err := g.closeReservations(ctx, tx, entity.ReservationReleased, `SELECT reservation_id FROM product_reservations
+		WHERE reservation_id = $2 AND status = $3 FOR UPDATE`, reservationID, entity.ReservationHeld)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err := g.getReservation(ctx, tx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkReleased(reservationID, reservation); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit release transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	g.metrics.AddReservations(entity.ReservationReleased, released)
+	return reservation, nil
+}
+
+// checkCommitted tells whether a commit that updated no reservation succeeds anyway, which it only
+// does when the reservation was committed already
+func checkCommitted(reservationID string, reservation *entity.Reservation) error {
+	switch reservation.Status {
+	case entity.ReservationCommitted:
+		return nil
+	case entity.ReservationHeld:
+		// past its expiry, the reaper just didn't get to it yet
+		return fmt.Errorf("%w with ID=%s, it expired at %s", entity.ErrReservationClosed, reservationID, reservation.ExpiresAt.Format(time.RFC3339))
+	}
+	return fmt.Errorf("%w with ID=%s, it is %s", entity.ErrReservationClosed, reservationID, reservation.Status)
+}
+
+// checkReleased tells whether a release succeeds given the reservation it left, every status but
+// committed means the stock is back
+func checkReleased(reservationID string, reservation *entity.Reservation) error {
+	if reservation.Status == entity.ReservationCommitted {
+		return fmt.Errorf("%w with ID=%s, it is %s", entity.ErrReservationClosed, reservationID, reservation.Status)
+	}
+	return nil
+}
+
+// ExpireReservations gives the stock of up to limit held reservations past their expiry back and returns
+// how many expired. Reservations locked by another replica's reaper, or by a release, are skipped.
+func (g *productGateway) ExpireReservations(ctx context.Context, limit int) (int, error) {
+	g.logger.Debug("Expiring reservations on db", "limit", limit, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "ExpireReservations", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin expiry transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return 0, translateError(err)
+	}
+	defer tx.Rollback()
+
+	expired, err := g.closeReservations(ctx, tx, entity.ReservationExpired, `SELECT reservation_id FROM product_reservations
+		WHERE status = $3 AND expires_at <= NOW() ORDER BY expires_at LIMIT $2 FOR UPDATE SKIP LOCKED`, limit, entity.ReservationHeld)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit expiry transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return 0, translateError(err)
+	}
+
+	g.metrics.AddReservations(entity.ReservationExpired, expired)
+	return expired, nil
+}
+
+func (g *productGateway) CountActiveReservations(ctx context.Context) (int, error) {
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "CountActiveReservations", "")
+
+	var count int
+	err := g.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM product_reservations WHERE status = $1;", entity.ReservationHeld).Scan(&count)
+	if err != nil {
+		g.logger.Error("Failed to count active reservations on db", "error", err, "traceID", ctx.Value("traceID"))
+		return 0, translateError(err)
+	}
+
+	return count, nil
+}
+
+// closeReservations sets status on the reservations picked by the selection, which gets status as $1 and
+// args from $2, and gives their stock back to the products. It returns how many reservations were closed.
+func (g *productGateway) closeReservations(ctx context.Context, tx *sql.Tx, status string, selection string, args ...interface{}) (int, error) {
+	ids, err := g.updateReservationStatus(ctx, tx, fmt.Sprintf(`UPDATE product_reservations SET status = $1, updated_at = 'NOW()'
+		WHERE reservation_id IN (%s) RETURNING reservation_id;`, selection), append([]interface{}{status}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	restock, err := g.reservedStock(ctx, tx, ids)
+	if err != nil {
+		return 0, err
+	}
+	productIDs := make([]string, 0, len(restock))
+	for productID := range restock {
+		productIDs = append(productIDs, productID)
+	}
+	// the locks are taken in the same order a reservation takes them
+	if _, err = g.lockProductStock(ctx, tx, productIDs); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE products p SET quantity = p.quantity + r.quantity, updated_at = 'NOW()', version = p.version + 1
+		FROM (SELECT product_id, SUM(quantity) AS quantity FROM product_reservation_items WHERE reservation_id = ANY($1) GROUP BY product_id) r
+		WHERE p.product_id = r.product_id;`, ids)
+	if err != nil {
+		g.logger.Error("Failed to give reserved stock back on db", "error", err, "traceID", ctx.Value("traceID"))
+		return 0, translateError(err)
+	}
+	if err = g.recordReservationMovements(ctx, tx, ids, entity.MovementReservation, 1); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+func (g *productGateway) updateReservationStatus(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		g.logger.Error("Failed to update reservation status on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			g.logger.Error("Error scaning reservation row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating reservation rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return ids, nil
+}
+
+// reservedStock sums the quantities the reservations hold by product
+func (g *productGateway) reservedStock(ctx context.Context, tx *sql.Tx, reservationIDs []string) (map[string]int64, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT product_id, SUM(quantity) FROM product_reservation_items WHERE reservation_id = ANY($1) GROUP BY product_id;", reservationIDs)
+	if err != nil {
+		g.logger.Error("Failed to get reserved stock from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	stock := map[string]int64{}
+	for rows.Next() {
+		var productID string
+		var quantity int64
+		if err = rows.Scan(&productID, &quantity); err != nil {
+			g.logger.Error("Error scaning reserved stock row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		stock[productID] = quantity
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating reserved stock rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return stock, nil
+}
+
+// lockProductStock locks the products in the order of their IDs until the transaction ends and returns their
+// quantity, deleted products are missing from the result
+func (g *productGateway) lockProductStock(ctx context.Context, tx *sql.Tx, productIDs []string) (map[string]int64, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT product_id, quantity FROM products WHERE product_id = ANY($1) ORDER BY product_id FOR UPDATE;", productIDs)
+	if err != nil {
+		g.logger.Error("Failed to lock products on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	stock := make(map[string]int64, len(productIDs))
+	for rows.Next() {
+		var productID string
+		var quantity int64
+		if err = rows.Scan(&productID, &quantity); err != nil {
+			g.logger.Error("Error scaning product stock row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		stock[productID] = quantity
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating product stock rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return stock, nil
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (g *productGateway) getReservation(ctx context.Context, q queryer, reservationID string) (*entity.Reservation, error) {
+	reservation := &entity.Reservation{}
+	err := q.QueryRowContext(ctx, "SELECT reservation_id, status, expires_at, created_at, updated_at FROM product_reservations WHERE reservation_id = $1;",
+		reservationID).Scan(&reservation.ID, &reservation.Status, &reservation.ExpiresAt, &reservation.CreatedAt, &reservation.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w with ID=%s", entity.ErrReservationNotFound, reservationID)
+	}
+	if err != nil {
+		g.logger.Error("Failed to get reservation by ID from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	rows, err := q.QueryContext(ctx, "SELECT product_id, quantity FROM product_reservation_items WHERE reservation_id = $1 ORDER BY product_id;", reservationID)
+	if err != nil {
+		g.logger.Error("Failed to get reservation items from db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	reservation.Items = make([]entity.ReservationItem, 0)
+	for rows.Next() {
+		item := entity.ReservationItem{}
+		if err = rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			g.logger.Error("Error scaning reservation item row", "error", err, "traceID", ctx.Value("traceID"))
+			return nil, err
+		}
+		reservation.Items = append(reservation.Items, item)
+	}
+	if err = rows.Err(); err != nil {
+		g.logger.Error("Error iterating reservation item rows", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return reservation, nil
+}
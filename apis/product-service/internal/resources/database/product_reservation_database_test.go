@@ -0,0 +1,56 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CheckCommitted(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		status      string
+		expectedErr error
+	}{
+		// committing twice answers like the first commit
+		{"committed", entity.ReservationCommitted, nil},
+		// still held but past its expiry, the update skipped it
+		{"expired but held", entity.ReservationHeld, entity.ErrReservationClosed},
+		{"released", entity.ReservationReleased, entity.ErrReservationClosed},
+		{"expired", entity.ReservationExpired, entity.ErrReservationClosed},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			reservation := &entity.Reservation{Status: tt.status, ExpiresAt: time.Now().Add(-time.Minute)}
+			err := checkCommitted("r1", reservation)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func Test_CheckReleased(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		status      string
+		expectedErr error
+	}{
+		// a held reservation is released even past its expiry
+		{"released", entity.ReservationReleased, nil},
+		{"expired", entity.ReservationExpired, nil},
+		{"committed then released", entity.ReservationCommitted, entity.ErrReservationClosed},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReleased("r1", &entity.Reservation{Status: tt.status})
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
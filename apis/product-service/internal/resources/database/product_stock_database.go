@@ -0,0 +1,69 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// AdjustStock adds the signed delta to the quantity in a single update, so concurrent adjustments never
+// overwrite each other. The update only matches when the result fits the quantity column, a negative
+// result is insufficient stock.
+func (g *productGateway) AdjustStock(ctx context.Context, adjustment entity.StockAdjustment) (*entity.Product, error) {
+	g.logger.Debug("Adjusting product stock on db", "ID", adjustment.ProductID, "delta", adjustment.Delta, "traceID", ctx.Value("traceID"))
+	start := time.Now()
+	defer g.metrics.MeasureExternalDuration(start, "database", "ProductDB", "AdjustStock", "")
+
+	tx, err := g.db.BeginTx(ctx, nil)
+	if err != nil {
+		g.logger.Error("Failed to begin stock transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+	defer tx.Rollback()
+
+	product := &entity.Product{}
+	err = tx.QueryRowContext(ctx, `UPDATE products SET quantity = quantity + $1, updated_at = 'NOW()', version = version + 1
+		WHERE product_id = $2 AND quantity + $1::bigint BETWEEN 0 AND $3
+		RETURNING product_id, sku, name, description, price, quantity, created_at, updated_at, version;`,
+		adjustment.Delta, adjustment.ProductID, math.MaxInt32).
+		Scan(&product.ID, &product.SKU, &product.Name, &product.Description, &product.Price, &product.Quantity, &product.CreatedAt, &product.UpdatedAt, &product.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, g.stockNotAdjustedError(ctx, adjustment)
+	}
+	if err != nil {
+		g.logger.Error("Failed to adjust product stock on db", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	if err = g.recordMovement(ctx, tx, adjustment.ProductID, adjustment.Reason, adjustment.Delta, nil); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		g.logger.Error("Failed to commit stock transaction", "error", err, "traceID", ctx.Value("traceID"))
+		return nil, translateError(err)
+	}
+
+	return product, nil
+}
+
+// stockNotAdjustedError tells why an adjustment matched no product, the quantity read may already be stale
+func (g *productGateway) stockNotAdjustedError(ctx context.Context, adjustment entity.StockAdjustment) error {
+	var quantity int64
+	err := g.db.QueryRowContext(ctx, "SELECT quantity FROM products WHERE product_id = $1;", adjustment.ProductID).Scan(&quantity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w with ID=%s", entity.ErrProductNotFound, adjustment.ProductID)
+	}
+	if err != nil {
+		g.logger.Error("Failed to get product stock from db", "error", err, "traceID", ctx.Value("traceID"))
+		return translateError(err)
+	}
+
+	if quantity+adjustment.Delta < 0 {
+		return fmt.Errorf("%w for product with ID=%s, %d available", entity.ErrInsufficientStock, adjustment.ProductID, quantity)
+	}
+	return entity.InvalidField("delta", fmt.Sprintf("would take the quantity above %d", math.MaxInt32))
+}
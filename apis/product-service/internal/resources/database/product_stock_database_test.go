@@ -0,0 +1,82 @@
+package database
+
+import (
+	"cmd/product-service/internal/domain/entity"
+	"context"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var productColumns = []string{"product_id", "sku", "name", "description", "price", "quantity", "created_at", "updated_at", "version"}
+
+func Test_ProductGtw_AdjustStock(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "actor", "ops@mock.com")
+	gtw, mock := newMockGateway(t)
+	adjustment := entity.StockAdjustment{ProductID: "p1", Delta: -3, Reason: entity.MovementSale}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE products SET quantity = quantity + $1")).
+		WithArgs(adjustment.Delta, adjustment.ProductID, math.MaxInt32).
+		WillReturnRows(sqlmock.NewRows(productColumns).AddRow("p1", "SK1", "Adapter", "", 9.9, 7, time.Now(), nil, 4))
+	// the movement carries the reason and the signed delta of the adjustment
+	mock.ExpectExec(regexp.QuoteMeta(insertMovementQuery)).
+		WithArgs("p1", entity.MovementSale, int64(-3), nil, "ops@mock.com", nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	product, err := gtw.AdjustStock(ctx, adjustment)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), product.Quantity)
+	assert.Equal(t, int64(4), product.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_ProductGtw_AdjustStock_NotAdjusted(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		delta       int64
+		quantity    *int64
+		expectedErr error
+	}{
+		{"insufficient stock", -5, func() *int64 { q := int64(2); return &q }(), entity.ErrInsufficientStock},
+		{"above the column", 10, func() *int64 { q := int64(math.MaxInt32 - 1); return &q }(), &entity.ValidationError{}},
+		{"unknown product", 1, nil, entity.ErrProductNotFound},
+	}
+
+	for _, tt := range scenarios {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			gtw, mock := newMockGateway(t)
+			adjustment := entity.StockAdjustment{ProductID: "p1", Delta: tt.delta, Reason: entity.MovementAdjustment}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("UPDATE products SET quantity = quantity + $1")).
+				WillReturnRows(sqlmock.NewRows(productColumns))
+			rows := sqlmock.NewRows([]string{"quantity"})
+			if tt.quantity != nil {
+				rows.AddRow(*tt.quantity)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT quantity FROM products WHERE product_id = $1;")).
+				WithArgs("p1").
+				WillReturnRows(rows)
+			// nothing is written to the ledger
+			mock.ExpectRollback()
+
+			_, err := gtw.AdjustStock(context.Background(), adjustment)
+
+			if validationErr, ok := tt.expectedErr.(*entity.ValidationError); ok {
+				assert.ErrorAs(t, err, &validationErr)
+			} else {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -9,5 +9,5 @@ export const options = {
 
 export default function() {
 	http.get(`${util.customerBaseUrl}/v1/customers/email/${util.randomItemFromArray(fixture.customerEmails)}`)
-	http.get(`${util.customerBaseUrl}/v2/customers/email/${util.randomItemFromArray(fixture.customerEmails)}`)
+	http.get(`${util.customerBaseUrl}/v1/customers/email/${util.randomItemFromArray(fixture.customerEmails)}`)
 }
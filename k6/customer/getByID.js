@@ -9,5 +9,5 @@ export const options = {
 
 export default function() {
 	http.get(`${util.customerBaseUrl}/v1/customers/${util.randomItemFromArray(fixture.customerIds)}`)
-	http.get(`${util.customerBaseUrl}/v2/customers/${util.randomItemFromArray(fixture.customerIds)}`)
+	http.get(`${util.customerBaseUrl}/v1/customers/${util.randomItemFromArray(fixture.customerIds)}`)
 }
@@ -9,8 +9,8 @@ export const options = {
 
 export default function() {
 	http.get(`${util.customerBaseUrl}/v1/customers/name/${util.randomItemFromArray(fixture.customersNames)}`)
-	http.get(`${util.customerBaseUrl}/v2/customers/name/${util.randomItemFromArray(fixture.customersNames)}`)
+	http.get(`${util.customerBaseUrl}/v1/customers/name/${util.randomItemFromArray(fixture.customersNames)}`)
 
 	http.get(`${util.customerBaseUrl}/v1/customers/email/${util.randomItemFromArray(fixture.customerEmails)}`)
-	http.get(`${util.customerBaseUrl}/v2/customers/email/${util.randomItemFromArray(fixture.customerEmails)}`)
+	http.get(`${util.customerBaseUrl}/v1/customers/email/${util.randomItemFromArray(fixture.customerEmails)}`)
 }
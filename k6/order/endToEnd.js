@@ -11,7 +11,7 @@ export default function() {
 	let url = `${util.productBaseUrl}/v1/products`
 
 	// POST
-	const createResponse = http.post(url, generateJson())
+	const createResponse = http.post(url, JSON.stringify({ sku: util.randomSKU(), ...generateFields() }))
 	check(createResponse, {
 		'create status 201': (r) => r.status === 201
 	})
@@ -26,7 +26,7 @@ export default function() {
 	})
 
 	// PUT
-	const updateResponse = http.put(url, generateJson())
+	const updateResponse = http.put(url, JSON.stringify(generateFields()))
 	check(updateResponse, {
 		'update status 200': (r) => r.status === 200
 	})
@@ -38,11 +38,12 @@ export default function() {
 	})
 }
 
-function generateJson() {
-	return JSON.stringify({
+// the SKU is only sent on create, an update can't change it
+function generateFields() {
+	return {
 		name: util.randomString(6),
 		description: util.randomString(30),
 		price: util.randomPrice(),
 		quantity: util.randomInteger(1, 100)
-	})
+	}
 }
@@ -53,6 +53,7 @@ export default function() {
 
 function generateJson() {
 	return JSON.stringify({
+		sku: "ERRORSKU",
 		name: "errorName",
 		description: util.randomString(30),
 		price: util.randomPrice(),
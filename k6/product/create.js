@@ -13,6 +13,7 @@ export default function() {
 	const url = `${util.productBaseUrl}/v1/products`
 
 	const payload = JSON.stringify({
+		sku: util.randomSKU(),
 		name: util.randomString(10),
 		description: util.randomString(30),
 		price: util.randomPrice(),
@@ -9,6 +9,10 @@ export function randomString(length, charset = '') {
     return res;
 }
 
+export function randomSKU() {
+    return `SK${randomString(10, '0123456789')}`;
+}
+
 export function randomItemFromArray(array) {
     return array[Math.floor(Math.random() * array.length)];
 }